@@ -0,0 +1,160 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/go-secure-stdlib/strutil"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google/downscope"
+)
+
+const (
+	// maxAccessBoundaryRules is the maximum number of rules allowed by GCP in a Credential Access Boundary.
+	maxAccessBoundaryRules = 10
+
+	accessBoundaryPermissionPrefix = "inRole:"
+)
+
+// AccessBoundary is a Credential Access Boundary used to downscope access tokens.
+// See https://cloud.google.com/iam/docs/downscoping-short-lived-credentials
+type AccessBoundary struct {
+	Rules []downscope.AccessBoundaryRule `json:"accessBoundaryRules"`
+}
+
+// parseAccessBoundary parses a Credential Access Boundary given as a raw or base64-encoded JSON
+// string in the format GCP documents, i.e. {"accessBoundary": {"accessBoundaryRules": [...]}}.
+// An empty string returns a nil boundary.
+func parseAccessBoundary(raw string) (*AccessBoundary, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+
+	// Try to base64 decode
+	jsonBytes, err := base64.StdEncoding.DecodeString(raw)
+	if err != nil {
+		jsonBytes = []byte(raw)
+	}
+
+	var parsed struct {
+		AccessBoundary *AccessBoundary `json:"accessBoundary"`
+	}
+	dec := json.NewDecoder(bytes.NewReader(jsonBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&parsed); err != nil {
+		return nil, errwrap.Wrapf("unable to parse access boundary JSON: {{err}}", err)
+	}
+	if parsed.AccessBoundary == nil {
+		return nil, fmt.Errorf(`access boundary JSON does not contain an "accessBoundary" object`)
+	}
+
+	if err := parsed.AccessBoundary.validate(); err != nil {
+		return nil, err
+	}
+	return parsed.AccessBoundary, nil
+}
+
+func (ab *AccessBoundary) validate() error {
+	err := &multierror.Error{}
+	if len(ab.Rules) == 0 {
+		err = multierror.Append(err, fmt.Errorf("access boundary must contain at least one rule"))
+	}
+	if len(ab.Rules) > maxAccessBoundaryRules {
+		err = multierror.Append(err, fmt.Errorf("access boundary can contain at most %d rules, got %d", maxAccessBoundaryRules, len(ab.Rules)))
+	}
+	for i, rule := range ab.Rules {
+		if rule.AvailableResource == "" {
+			err = multierror.Append(err, fmt.Errorf("access boundary rule %d has empty availableResource", i))
+		}
+		if len(rule.AvailablePermissions) == 0 {
+			err = multierror.Append(err, fmt.Errorf("access boundary rule %d must have at least one availablePermissions entry", i))
+		}
+		for _, perm := range rule.AvailablePermissions {
+			if !strings.HasPrefix(perm, accessBoundaryPermissionPrefix) {
+				err = multierror.Append(err, fmt.Errorf("access boundary rule %d permission %q must be of the form %q", i, perm, accessBoundaryPermissionPrefix+"roles/ROLE"))
+			}
+		}
+		if rule.Condition != nil && rule.Condition.Expression == "" {
+			err = multierror.Append(err, fmt.Errorf("access boundary rule %d has availabilityCondition with empty expression", i))
+		}
+	}
+	return err.ErrorOrNil()
+}
+
+// checkWithin returns an error if the requested boundary grants access that this boundary does not.
+// Each requested rule must be for a resource this boundary has a rule for, with a subset of its
+// permissions, and keep its availability condition if it has one. A nil boundary allows any
+// requested boundary, since the token would otherwise not be downscoped at all.
+func (ab *AccessBoundary) checkWithin(requested *AccessBoundary) error {
+	if ab == nil || requested == nil {
+		return nil
+	}
+
+	for _, r := range requested.Rules {
+		if !ab.hasRuleCovering(r) {
+			return fmt.Errorf("access boundary rule for %q is not within the configured access boundary", r.AvailableResource)
+		}
+	}
+	return nil
+}
+
+func (ab *AccessBoundary) hasRuleCovering(r downscope.AccessBoundaryRule) bool {
+	for _, rule := range ab.Rules {
+		if rule.AvailableResource != r.AvailableResource {
+			continue
+		}
+		if rule.Condition != nil && (r.Condition == nil || r.Condition.Expression != rule.Condition.Expression) {
+			continue
+		}
+
+		covered := true
+		for _, perm := range r.AvailablePermissions {
+			if !strutil.StrListContains(rule.AvailablePermissions, perm) {
+				covered = false
+				break
+			}
+		}
+		if covered {
+			return true
+		}
+	}
+	return false
+}
+
+// asOutput returns the boundary in the same format it is given as input.
+func (ab *AccessBoundary) asOutput() map[string]interface{} {
+	return map[string]interface{}{
+		"accessBoundary": ab,
+	}
+}
+
+// downscopeToken exchanges the given token for a token limited by the access boundary.
+// If the boundary is nil, the token is returned as is.
+func downscopeToken(ctx context.Context, token *oauth2.Token, ab *AccessBoundary) (*oauth2.Token, error) {
+	if ab == nil {
+		return token, nil
+	}
+
+	ts, err := downscope.NewTokenSource(ctx, downscope.DownscopingConfig{
+		RootSource: oauth2.StaticTokenSource(token),
+		Rules:      ab.Rules,
+	})
+	if err != nil {
+		return nil, errwrap.Wrapf("could not create downscoped token source: {{err}}", err)
+	}
+
+	tkn, err := ts.Token()
+	if err != nil {
+		return nil, errwrap.Wrapf("got error while downscoping token: {{err}}", err)
+	}
+	return tkn, nil
+}
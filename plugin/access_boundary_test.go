@@ -0,0 +1,187 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"golang.org/x/oauth2/google/downscope"
+)
+
+func Test_ParseAccessBoundary(t *testing.T) {
+	validJSON := `{
+  "accessBoundary": {
+    "accessBoundaryRules": [
+      {
+        "availableResource": "//storage.googleapis.com/projects/_/buckets/bucket-a",
+        "availablePermissions": ["inRole:roles/storage.objectViewer"],
+        "availabilityCondition": {
+          "expression": "resource.name.startsWith('projects/_/buckets/bucket-a/objects/prefix/')"
+        }
+      }
+    ]
+  }
+}`
+
+	tests := []struct {
+		name      string
+		raw       string
+		wantNil   bool
+		wantRules int
+		wantErr   bool
+	}{
+		{
+			name:    "empty",
+			raw:     "",
+			wantNil: true,
+		},
+		{
+			name:      "raw JSON",
+			raw:       validJSON,
+			wantRules: 1,
+		},
+		{
+			name:      "base64-encoded JSON",
+			raw:       base64.StdEncoding.EncodeToString([]byte(validJSON)),
+			wantRules: 1,
+		},
+		{
+			name:    "invalid JSON",
+			raw:     `{"accessBoundary": `,
+			wantErr: true,
+		},
+		{
+			name:    "missing accessBoundary",
+			raw:     `{"accessBoundaryRules": []}`,
+			wantErr: true,
+		},
+		{
+			name:    "no rules",
+			raw:     `{"accessBoundary": {"accessBoundaryRules": []}}`,
+			wantErr: true,
+		},
+		{
+			name:    "permission without inRole prefix",
+			raw:     `{"accessBoundary": {"accessBoundaryRules": [{"availableResource": "//storage.googleapis.com/projects/_/buckets/bucket-a", "availablePermissions": ["roles/storage.objectViewer"]}]}}`,
+			wantErr: true,
+		},
+		{
+			name:    "missing resource",
+			raw:     `{"accessBoundary": {"accessBoundaryRules": [{"availablePermissions": ["inRole:roles/storage.objectViewer"]}]}}`,
+			wantErr: true,
+		},
+		{
+			name:    "empty condition expression",
+			raw:     `{"accessBoundary": {"accessBoundaryRules": [{"availableResource": "//storage.googleapis.com/projects/_/buckets/bucket-a", "availablePermissions": ["inRole:roles/storage.objectViewer"], "availabilityCondition": {"title": "no expression"}}]}}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseAccessBoundary(tt.raw)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("parseAccessBoundary() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if tt.wantNil {
+				if got != nil {
+					t.Fatalf("expected nil access boundary, got %+v", got)
+				}
+				return
+			}
+			if len(got.Rules) != tt.wantRules {
+				t.Fatalf("expected %d rules, got %d", tt.wantRules, len(got.Rules))
+			}
+		})
+	}
+}
+
+func Test_AccessBoundaryCheckWithin(t *testing.T) {
+	condition := &downscope.AvailabilityCondition{
+		Expression: "resource.name.startsWith('projects/_/buckets/bucket-a/objects/prefix/')",
+	}
+	role := &AccessBoundary{
+		Rules: []downscope.AccessBoundaryRule{
+			{
+				AvailableResource:    "//storage.googleapis.com/projects/_/buckets/bucket-a",
+				AvailablePermissions: []string{"inRole:roles/storage.objectViewer", "inRole:roles/storage.objectCreator"},
+			},
+			{
+				AvailableResource:    "//storage.googleapis.com/projects/_/buckets/bucket-b",
+				AvailablePermissions: []string{"inRole:roles/storage.objectViewer"},
+				Condition:            condition,
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		role      *AccessBoundary
+		requested downscope.AccessBoundaryRule
+		wantErr   bool
+	}{
+		{
+			name: "no role boundary allows anything",
+			requested: downscope.AccessBoundaryRule{
+				AvailableResource:    "//storage.googleapis.com/projects/_/buckets/bucket-c",
+				AvailablePermissions: []string{"inRole:roles/storage.admin"},
+			},
+		},
+		{
+			name: "subset of permissions",
+			role: role,
+			requested: downscope.AccessBoundaryRule{
+				AvailableResource:    "//storage.googleapis.com/projects/_/buckets/bucket-a",
+				AvailablePermissions: []string{"inRole:roles/storage.objectViewer"},
+			},
+		},
+		{
+			name: "added condition",
+			role: role,
+			requested: downscope.AccessBoundaryRule{
+				AvailableResource:    "//storage.googleapis.com/projects/_/buckets/bucket-a",
+				AvailablePermissions: []string{"inRole:roles/storage.objectViewer"},
+				Condition:            condition,
+			},
+		},
+		{
+			name: "extra permission",
+			role: role,
+			requested: downscope.AccessBoundaryRule{
+				AvailableResource:    "//storage.googleapis.com/projects/_/buckets/bucket-a",
+				AvailablePermissions: []string{"inRole:roles/storage.admin"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "other resource",
+			role: role,
+			requested: downscope.AccessBoundaryRule{
+				AvailableResource:    "//storage.googleapis.com/projects/_/buckets/bucket-c",
+				AvailablePermissions: []string{"inRole:roles/storage.objectViewer"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "dropped condition",
+			role: role,
+			requested: downscope.AccessBoundaryRule{
+				AvailableResource:    "//storage.googleapis.com/projects/_/buckets/bucket-b",
+				AvailablePermissions: []string{"inRole:roles/storage.objectViewer"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.role.checkWithin(&AccessBoundary{Rules: []downscope.AccessBoundaryRule{tt.requested}})
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("checkWithin() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/vault/sdk/logical"
+	"google.golang.org/api/apikeys/v2"
+)
+
+const (
+	defaultApiKeyDisplayNamePrefix = "vault"
+	apiKeyDisplayNameMaxLen        = 63
+)
+
+// ApiKeyRole configures the API keys created for leases under this role.
+type ApiKeyRole struct {
+	Name              string
+	Project           string
+	DisplayNamePrefix string
+
+	// ApiTargets are the services, optionally followed by ":<method>", the key may call.
+	ApiTargets []string
+
+	// Application restrictions. At most one kind can be set.
+	AllowedReferrers   []string
+	AllowedIps         []string
+	AllowedAndroidApps []string
+	AllowedBundleIds   []string
+}
+
+func (b *backend) getApiKeyRole(name string, ctx context.Context, s logical.Storage) (*ApiKeyRole, error) {
+	b.Logger().Debug("getting API key role from storage", "api_key_role_name", name)
+	entry, err := s.Get(ctx, fmt.Sprintf("%s/%s", apiKeyStoragePrefix, name))
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	r := &ApiKeyRole{}
+	if err := entry.DecodeJSON(r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *ApiKeyRole) validate() error {
+	err := &multierror.Error{}
+	if r.Name == "" {
+		err = multierror.Append(err, errors.New("API key role name is empty"))
+	}
+
+	if r.Project == "" {
+		err = multierror.Append(err, errors.New("API key role project is empty"))
+	}
+
+	restrictionKinds := 0
+	for _, l := range [][]string{r.AllowedReferrers, r.AllowedIps, r.AllowedAndroidApps, r.AllowedBundleIds} {
+		if len(l) > 0 {
+			restrictionKinds++
+		}
+	}
+	if restrictionKinds > 1 {
+		err = multierror.Append(err, errors.New("only one of allowed_referrers, allowed_ips, allowed_android_apps or allowed_bundle_ids can be set"))
+	}
+
+	if _, rErr := r.restrictions(); rErr != nil {
+		err = multierror.Append(err, rErr)
+	}
+	return err.ErrorOrNil()
+}
+
+func (r *ApiKeyRole) save(ctx context.Context, s logical.Storage) error {
+	if err := r.validate(); err != nil {
+		return err
+	}
+
+	entry, err := logical.StorageEntryJSON(fmt.Sprintf("%s/%s", apiKeyStoragePrefix, r.Name), r)
+	if err != nil {
+		return err
+	}
+
+	return s.Put(ctx, entry)
+}
+
+// unrestrictedWarning returns a warning if the role has neither API targets nor application
+// restrictions, so its keys can call any API enabled in the project from anywhere.
+func (r *ApiKeyRole) unrestrictedWarning() string {
+	for _, l := range [][]string{r.ApiTargets, r.AllowedReferrers, r.AllowedIps, r.AllowedAndroidApps, r.AllowedBundleIds} {
+		if len(l) > 0 {
+			return ""
+		}
+	}
+	return fmt.Sprintf("API key role %q has no restrictions, so its keys can call any API enabled in project %q from anywhere; set api_targets or an application restriction", r.Name, r.Project)
+}
+
+// restrictions converts the role's restrictions to the API Keys representation.
+func (r *ApiKeyRole) restrictions() (*apikeys.V2Restrictions, error) {
+	restrictions := &apikeys.V2Restrictions{}
+
+	targets := map[string]*apikeys.V2ApiTarget{}
+	for _, t := range r.ApiTargets {
+		service, method, _ := strings.Cut(t, ":")
+		if service == "" {
+			return nil, fmt.Errorf("invalid API target %q, expected <service> or <service>:<method>", t)
+		}
+
+		target, ok := targets[service]
+		if !ok {
+			target = &apikeys.V2ApiTarget{Service: service}
+			targets[service] = target
+			restrictions.ApiTargets = append(restrictions.ApiTargets, target)
+		}
+		if method != "" {
+			target.Methods = append(target.Methods, method)
+		}
+	}
+
+	switch {
+	case len(r.AllowedReferrers) > 0:
+		restrictions.BrowserKeyRestrictions = &apikeys.V2BrowserKeyRestrictions{AllowedReferrers: r.AllowedReferrers}
+	case len(r.AllowedIps) > 0:
+		restrictions.ServerKeyRestrictions = &apikeys.V2ServerKeyRestrictions{AllowedIps: r.AllowedIps}
+	case len(r.AllowedAndroidApps) > 0:
+		apps := make([]*apikeys.V2AndroidApplication, 0, len(r.AllowedAndroidApps))
+		for _, a := range r.AllowedAndroidApps {
+			pkg, fingerprint, ok := strings.Cut(a, ":")
+			if !ok || pkg == "" || fingerprint == "" {
+				return nil, fmt.Errorf("invalid Android app %q, expected <package_name>:<sha1_fingerprint>", a)
+			}
+			apps = append(apps, &apikeys.V2AndroidApplication{PackageName: pkg, Sha1Fingerprint: fingerprint})
+		}
+		restrictions.AndroidKeyRestrictions = &apikeys.V2AndroidKeyRestrictions{AllowedApplications: apps}
+	case len(r.AllowedBundleIds) > 0:
+		restrictions.IosKeyRestrictions = &apikeys.V2IosKeyRestrictions{AllowedBundleIds: r.AllowedBundleIds}
+	}
+
+	return restrictions, nil
+}
+
+// newKey returns the API key to create for a new lease, along with its key ID.
+func (r *ApiKeyRole) newKey() (keyId string, key *apikeys.V2Key, err error) {
+	restrictions, err := r.restrictions()
+	if err != nil {
+		return "", nil, err
+	}
+
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return "", nil, err
+	}
+
+	prefix := r.DisplayNamePrefix
+	if prefix == "" {
+		prefix = defaultApiKeyDisplayNamePrefix
+	}
+	displayName := fmt.Sprintf("%s-%s", prefix, r.Name)
+	if len(displayName) > apiKeyDisplayNameMaxLen {
+		displayName = displayName[:apiKeyDisplayNameMaxLen]
+	}
+
+	return "vault-" + id, &apikeys.V2Key{
+		DisplayName:  displayName,
+		Restrictions: restrictions,
+	}, nil
+}
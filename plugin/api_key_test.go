@@ -0,0 +1,152 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/apikeys/v2"
+)
+
+func Test_ApiKeyRoleValidate(t *testing.T) {
+	base := func() *ApiKeyRole {
+		return &ApiKeyRole{
+			Name:       "maps",
+			Project:    "project",
+			ApiTargets: []string{"places.googleapis.com"},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(r *ApiKeyRole)
+		wantErr bool
+	}{
+		{
+			name:   "no restrictions",
+			modify: func(r *ApiKeyRole) {},
+		},
+		{
+			name: "server restrictions",
+			modify: func(r *ApiKeyRole) {
+				r.AllowedIps = []string{"10.0.0.0/8"}
+			},
+		},
+		{
+			name: "missing project",
+			modify: func(r *ApiKeyRole) {
+				r.Project = ""
+			},
+			wantErr: true,
+		},
+		{
+			name: "multiple application restrictions",
+			modify: func(r *ApiKeyRole) {
+				r.AllowedReferrers = []string{"https://example.com/*"}
+				r.AllowedIps = []string{"10.0.0.0/8"}
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid API target",
+			modify: func(r *ApiKeyRole) {
+				r.ApiTargets = []string{":method"}
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid Android app",
+			modify: func(r *ApiKeyRole) {
+				r.AllowedAndroidApps = []string{"com.example.app"}
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := base()
+			tt.modify(r)
+			err := r.validate()
+			if tt.wantErr && err == nil {
+				t.Fatal("expected error")
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func Test_ApiKeyRoleRestrictions(t *testing.T) {
+	r := &ApiKeyRole{
+		Name:               "maps",
+		Project:            "project",
+		ApiTargets:         []string{"places.googleapis.com", "translate.googleapis.com:TranslateText", "translate.googleapis.com:DetectLanguage"},
+		AllowedAndroidApps: []string{"com.example.app:DA:39:A3:EE"},
+	}
+
+	restrictions, err := r.restrictions()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &apikeys.V2Restrictions{
+		ApiTargets: []*apikeys.V2ApiTarget{
+			{Service: "places.googleapis.com"},
+			{Service: "translate.googleapis.com", Methods: []string{"TranslateText", "DetectLanguage"}},
+		},
+		AndroidKeyRestrictions: &apikeys.V2AndroidKeyRestrictions{
+			AllowedApplications: []*apikeys.V2AndroidApplication{
+				{PackageName: "com.example.app", Sha1Fingerprint: "DA:39:A3:EE"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(restrictions, expected) {
+		t.Fatalf("expected restrictions %+v, got %+v", expected, restrictions)
+	}
+}
+
+func Test_ApiKeyRoleNewKey(t *testing.T) {
+	r := &ApiKeyRole{
+		Name:    strings.Repeat("a", 80),
+		Project: "project",
+	}
+
+	keyId, key, err := r.newKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Key IDs must match the format required by the API Keys service.
+	if !regexp.MustCompile(`^[a-z]([a-z0-9-]{0,61}[a-z0-9])?$`).MatchString(keyId) {
+		t.Fatalf("invalid key ID %q", keyId)
+	}
+	if len(key.DisplayName) != apiKeyDisplayNameMaxLen {
+		t.Fatalf("expected display name to be truncated to %d characters, got %q", apiKeyDisplayNameMaxLen, key.DisplayName)
+	}
+	if !strings.HasPrefix(key.DisplayName, defaultApiKeyDisplayNamePrefix+"-") {
+		t.Fatalf("expected display name to start with default prefix, got %q", key.DisplayName)
+	}
+}
+
+func Test_ApiKeyRoleUnrestrictedWarning(t *testing.T) {
+	role := &ApiKeyRole{Name: "maps", Project: "project"}
+	if w := role.unrestrictedWarning(); w == "" {
+		t.Fatal("expected warning for role without restrictions")
+	}
+
+	role.AllowedIps = []string{"10.0.0.0/8"}
+	if w := role.unrestrictedWarning(); w != "" {
+		t.Fatalf("expected no warning with an application restriction, got %q", w)
+	}
+
+	role = &ApiKeyRole{Name: "maps", Project: "project", ApiTargets: []string{"places.googleapis.com"}}
+	if w := role.unrestrictedWarning(); w != "" {
+		t.Fatalf("expected no warning with API targets, got %q", w)
+	}
+}
@@ -5,28 +5,36 @@ package gcpsecrets
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"cloud.google.com/go/compute/metadata"
 	"github.com/hashicorp/errwrap"
 	"github.com/hashicorp/go-cleanhttp"
 	"github.com/hashicorp/go-gcp-common/gcputil"
 	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/locksutil"
 	"github.com/hashicorp/vault/sdk/helper/pluginutil"
 	"github.com/hashicorp/vault/sdk/helper/useragent"
 	"github.com/hashicorp/vault/sdk/logical"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 	"golang.org/x/oauth2/google/externalaccount"
+	"google.golang.org/api/apikeys/v2"
 	"google.golang.org/api/iam/v1"
 	"google.golang.org/api/option"
+	"google.golang.org/api/storage/v1"
 
 	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/cache"
 	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/iamutil"
+	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/tokencache"
 )
 
 const userAgentPluginName = "secrets-gcp"
@@ -36,6 +44,10 @@ const (
 	// must be less than 60 minutes.
 	cacheTime = 30 * time.Minute
 
+	// defaultTokenCacheMinTTL is the default minimum remaining lifetime of a
+	// cached token for it to be returned.
+	defaultTokenCacheMinTTL = 5 * time.Minute
+
 	// operationPrefixGoogleCloud is used as a prefix for OpenAPI operation id's.
 	operationPrefixGoogleCloud = "google-cloud"
 )
@@ -47,14 +59,38 @@ type backend struct {
 	// cache directly.
 	cache *cache.Cache
 
+	// tokenCache caches generated OAuth2 access tokens.
+	tokenCache *tokencache.Cache
+
 	// pluginEnv contains Vault version information. It is used in user-agent headers.
 	pluginEnv *logical.PluginEnvironment
 
 	resources iamutil.ResourceParser
 
+	// mountIdLock protects mountId, the cached ID of this mount.
+	mountIdLock sync.Mutex
+	mountId     string
+
 	rolesetLock             sync.Mutex
 	staticAccountLock       sync.Mutex
 	impersonatedAccountLock sync.Mutex
+	apiKeyLock              sync.Mutex
+	jitGrantLock            sync.Mutex
+
+	// keyPoolLocks serialize key issuance from the key pool of each role set, so the GCP calls
+	// made while issuing a pooled key do not block other role sets.
+	keyPoolLocks []*locksutil.LockEntry
+
+	// driftCheckLock protects lastDriftCheck, the time the periodic drift check last ran.
+	driftCheckLock sync.Mutex
+	lastDriftCheck time.Time
+
+	// tidyRunning is set while a tidy operation runs. tidyStatusLock protects the status of
+	// the last tidy operation and lastTidy, the time it started.
+	tidyRunning    atomic.Bool
+	tidyStatusLock sync.RWMutex
+	tidyStatus     *tidyStatus
+	lastTidy       time.Time
 }
 
 // Factory returns a new backend as logical.Backend.
@@ -68,8 +104,10 @@ func Factory(ctx context.Context, conf *logical.BackendConfig) (logical.Backend,
 
 func Backend() *backend {
 	b := &backend{
-		cache:     cache.New(),
-		resources: iamutil.GetEnabledResources(),
+		cache:        cache.New(),
+		tokenCache:   tokencache.New(),
+		resources:    iamutil.GetEnabledResources(),
+		keyPoolLocks: locksutil.CreateLocks(),
 	}
 
 	b.Backend = &framework.Backend{
@@ -78,6 +116,7 @@ func Backend() *backend {
 		PathsSpecial: &logical.Paths{
 			LocalStorage: []string{
 				framework.WALPrefix,
+				leasedKeyIndexPrefix + "/",
 			},
 			SealWrapStorage: []string{
 				"config",
@@ -94,29 +133,61 @@ func Backend() *backend {
 				pathRoleSetRotateAccount(b),
 				pathRoleSetRotateKey(b),
 				pathRoleSetSecretAccessToken(b),
+				pathRoleSetSecretIdToken(b),
 				pathRoleSetSecretServiceAccountKey(b),
+				pathRoleSetSecretHmacKey(b),
+				pathRoleSetVerify(b),
+				pathRoleSetKeys(b),
+				pathRoleSetKeysPrune(b),
 				deprecatedPathRoleSetSecretAccessToken(b),
 				deprecatedPathRoleSetSecretServiceAccountKey(b),
 				// Static Account
 				pathStaticAccount(b),
 				pathStaticAccountList(b),
 				pathStaticAccountRotateKey(b),
+				pathStaticAccountCreds(b),
 				pathStaticAccountSecretAccessToken(b),
+				pathStaticAccountSecretIdToken(b),
 				pathStaticAccountSecretServiceAccountKey(b),
+				pathStaticAccountSecretHmacKey(b),
+				pathStaticAccountVerify(b),
+				pathStaticAccountKeys(b),
+				pathStaticAccountKeysPrune(b),
 				// Impersonate
 				pathImpersonatedAccount(b),
 				pathImpersonatedAccountList(b),
 				pathImpersonatedAccountSecretAccessToken(b),
+				pathImpersonatedAccountSecretIdToken(b),
+				// API Key
+				pathApiKey(b),
+				pathApiKeyList(b),
+				pathApiKeySecret(b),
+				// JIT Grant
+				pathJitGrant(b),
+				pathJitGrantList(b),
+				pathJitGrantSecret(b),
+				// Resources
+				pathResourcesList(b),
+				pathResourcesParse(b),
+				// Drift
+				pathVerify(b),
+				// Tidy
+				pathTidy(b),
+				pathTidyStatus(b),
 			},
 		),
 		Secrets: []*framework.Secret{
 			secretAccessToken(b),
 			secretServiceAccountKey(b),
+			secretHmacKey(b),
+			secretApiKey(b),
+			secretJitGrant(b),
 		},
 
 		InitializeFunc:   b.initialize,
+		PeriodicFunc:     b.periodicFunc,
 		Invalidate:       b.invalidate,
-		RotateCredential: b.rotateRootCredential,
+		RotateCredential: b.rotateCredential,
 
 		WALRollback:       b.walRollback,
 		WALRollbackMinAge: 5 * time.Minute,
@@ -125,6 +196,22 @@ func Backend() *backend {
 	return b
 }
 
+// periodicFunc runs the periodic drift check and tidy operation, if they are enabled and due, and
+// rotates the keys of static accounts in rotating credential mode.
+func (b *backend) periodicFunc(ctx context.Context, req *logical.Request) error {
+	var merr *multierror.Error
+	if err := b.periodicDriftCheck(ctx, req); err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	if err := b.periodicTidy(ctx, req); err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	if err := b.periodicStaticKeyRotation(ctx, req); err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	return merr.ErrorOrNil()
+}
+
 func (b *backend) initialize(ctx context.Context, _ *logical.InitializationRequest) error {
 	pluginEnv, err := b.System().PluginEnv(ctx)
 	if err != nil {
@@ -158,6 +245,52 @@ func (b *backend) IAMAdminClient(s logical.Storage) (*iam.Service, error) {
 	return client.(*iam.Service), nil
 }
 
+// StorageClient returns a new Cloud Storage client. The client is cached.
+func (b *backend) StorageClient(s logical.Storage) (*storage.Service, error) {
+	httpClient, err := b.HTTPClient(s)
+	if err != nil {
+		return nil, errwrap.Wrapf("failed to create Cloud Storage HTTP client: {{err}}", err)
+	}
+
+	client, err := b.cache.Fetch("storage", cacheTime, func() (interface{}, error) {
+		client, err := storage.NewService(context.Background(), option.WithHTTPClient(httpClient))
+		if err != nil {
+			return nil, errwrap.Wrapf("failed to create Cloud Storage client: {{err}}", err)
+		}
+		client.UserAgent = useragent.PluginString(b.pluginEnv, userAgentPluginName)
+
+		return client, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return client.(*storage.Service), nil
+}
+
+// ApiKeysClient returns a new API Keys client. The client is cached.
+func (b *backend) ApiKeysClient(s logical.Storage) (*apikeys.Service, error) {
+	httpClient, err := b.HTTPClient(s)
+	if err != nil {
+		return nil, errwrap.Wrapf("failed to create API Keys HTTP client: {{err}}", err)
+	}
+
+	client, err := b.cache.Fetch("apikeys", cacheTime, func() (interface{}, error) {
+		client, err := apikeys.NewService(context.Background(), option.WithHTTPClient(httpClient))
+		if err != nil {
+			return nil, errwrap.Wrapf("failed to create API Keys client: {{err}}", err)
+		}
+		client.UserAgent = useragent.PluginString(b.pluginEnv, userAgentPluginName)
+
+		return client, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return client.(*apikeys.Service), nil
+}
+
 // HTTPClient returns a new http.Client that is authenticated using the provided
 // credentials. The underlying httpClient is cached among all clients.
 func (b *backend) HTTPClient(s logical.Storage) (*http.Client, error) {
@@ -234,6 +367,41 @@ func (b *backend) credentials(s logical.Storage) (*google.Credentials, error) {
 	return creds.(*google.Credentials), nil
 }
 
+// rootServiceAccountEmail returns the email of the service account Vault
+// authenticates as, i.e. the principal that impersonates managed accounts.
+func (b *backend) rootServiceAccountEmail(ctx context.Context, s logical.Storage) (string, error) {
+	cfg, err := getConfig(ctx, s)
+	if err != nil {
+		return "", err
+	}
+	if cfg != nil && cfg.IdentityTokenAudience != "" {
+		return cfg.ServiceAccountEmail, nil
+	}
+
+	creds, err := b.credentials(s)
+	if err != nil {
+		return "", err
+	}
+	if len(creds.JSON) > 0 {
+		gcpCreds, err := gcputil.Credentials(string(creds.JSON))
+		if err == nil && gcpCreds.ClientEmail != "" {
+			return gcpCreds.ClientEmail, nil
+		}
+	}
+
+	// Default credentials on GCE, GKE, Cloud Run, etc. don't have a JSON
+	// representation, so ask the metadata server instead.
+	if metadata.OnGCEWithContext(ctx) {
+		email, err := metadata.EmailWithContext(ctx, "default")
+		if err != nil {
+			return "", fmt.Errorf("failed to get service account email from metadata server: %w", err)
+		}
+		return email, nil
+	}
+
+	return "", errors.New("unable to determine the service account email of the configured credentials")
+}
+
 func (b *backend) GetExternalAccountConfig(c *config, ts *PluginIdentityTokenSupplier) *gcputil.ExternalAccountConfig {
 	b.Logger().Debug("adding web identity token fetcher")
 	cfg := &gcputil.ExternalAccountConfig{
@@ -273,17 +441,48 @@ func (p *PluginIdentityTokenSupplier) SubjectToken(ctx context.Context, opts ext
 	return resp.Token.Token(), nil
 }
 
-// ClearCaches deletes all cached clients and credentials.
+// ClearCaches deletes all cached clients, credentials and tokens.
 func (b *backend) ClearCaches() {
 	b.cache.Clear()
+	b.tokenCache.Clear()
+}
+
+// cachedToken returns a token for the given key from the token cache, calling f to
+// generate it on a miss. Unless the token cache is enabled, f is always called.
+//
+// A cached token is shared by concurrent requests, so f is called with a context that
+// is not canceled with the request and is bounded by retryTimeout instead.
+func (b *backend) cachedToken(ctx context.Context, s logical.Storage, key tokencache.Key, f tokencache.Func) (*oauth2.Token, error) {
+	cfg, err := getConfig(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil || !cfg.TokenCacheEnabled {
+		return f(ctx)
+	}
+
+	minTTL := defaultTokenCacheMinTTL
+	if cfg.TokenCacheMinTTL > 0 {
+		minTTL = cfg.TokenCacheMinTTL
+	}
+	return b.tokenCache.Fetch(ctx, key, minTTL, func(ctx context.Context) (*oauth2.Token, error) {
+		ctx, cancel := context.WithTimeout(ctx, retryTimeout)
+		defer cancel()
+		return f(ctx)
+	})
 }
 
 // invalidate resets the plugin. This is called when a key is updated via
 // replication.
 func (b *backend) invalidate(ctx context.Context, key string) {
-	switch key {
-	case "config":
+	switch {
+	case key == "config":
 		b.ClearCaches()
+	case strings.HasPrefix(key, rolesetStoragePrefix+"/"),
+		strings.HasPrefix(key, staticAccountStoragePrefix+"/"),
+		strings.HasPrefix(key, impersonatedAccountStoragePrefix+"/"):
+		// We don't know which account the changed role used, so drop all cached tokens.
+		b.tokenCache.Clear()
 	}
 }
 
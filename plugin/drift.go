@@ -0,0 +1,445 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/iamutil"
+	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/util"
+	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/helper/useragent"
+	"github.com/hashicorp/vault/sdk/logical"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	driftParentRoleSet       = "roleset"
+	driftParentStaticAccount = "static_account"
+
+	// driftEventType is the type of the events sent when the periodic check finds drift.
+	driftEventType = "gcp/bindings-drift"
+)
+
+// boundBinding is a single role bound to a member on a resource, under an optional condition.
+type boundBinding struct {
+	Resource  string
+	Role      string
+	Condition *iamutil.Condition
+}
+
+func (bb *boundBinding) asOutput() map[string]interface{} {
+	out := map[string]interface{}{
+		"resource": bb.Resource,
+		"role":     bb.Role,
+	}
+	if bb.Condition != nil {
+		out["condition"] = map[string]interface{}{
+			"title":       bb.Condition.Title,
+			"description": bb.Condition.Description,
+			"expression":  bb.Condition.Expression,
+		}
+	}
+	return out
+}
+
+// driftReport is the result of comparing a role set or static account against GCP.
+type driftReport struct {
+	Parent         string
+	Name           string
+	ServiceAccount string
+
+	ServiceAccountMissing bool
+	KeyMissing            bool
+
+	// MissingBindings are managed bindings that are not set on the resource. UnexpectedBindings are
+	// roles the service account has on a bound resource that are not managed by Vault.
+	MissingBindings    []*boundBinding
+	UnexpectedBindings []*boundBinding
+
+	// RepairedBindings are the missing bindings that were re-applied.
+	RepairedBindings []*boundBinding
+
+	Errors []string
+
+	// PoolAccounts are the reports of a role set's pool accounts.
+	PoolAccounts []*driftReport
+}
+
+// hasDrift returns whether the service account or any pool account drifted.
+func (r *driftReport) hasDrift() bool {
+	for _, pr := range r.PoolAccounts {
+		if pr.hasDrift() {
+			return true
+		}
+	}
+	return r.hasAccountDrift()
+}
+
+// hasAccountDrift returns whether the report's own service account drifted.
+func (r *driftReport) hasAccountDrift() bool {
+	return r.ServiceAccountMissing || r.KeyMissing || len(r.MissingBindings)+len(r.UnexpectedBindings) > 0
+}
+
+// accountReports returns the report followed by the reports of its pool accounts.
+func (r *driftReport) accountReports() []*driftReport {
+	return append([]*driftReport{r}, r.PoolAccounts...)
+}
+
+func (r *driftReport) asOutput() map[string]interface{} {
+	bindingsOutput := func(bbs []*boundBinding) []map[string]interface{} {
+		out := make([]map[string]interface{}, 0, len(bbs))
+		for _, bb := range bbs {
+			out = append(out, bb.asOutput())
+		}
+		return out
+	}
+	poolOutput := make([]map[string]interface{}, 0, len(r.PoolAccounts))
+	for _, pr := range r.PoolAccounts {
+		poolOutput = append(poolOutput, pr.asOutput())
+	}
+	return map[string]interface{}{
+		"type":                    r.Parent,
+		"name":                    r.Name,
+		"service_account_email":   r.ServiceAccount,
+		"drift":                   r.hasDrift(),
+		"service_account_missing": r.ServiceAccountMissing,
+		"key_missing":             r.KeyMissing,
+		"missing_bindings":        bindingsOutput(r.MissingBindings),
+		"unexpected_bindings":     bindingsOutput(r.UnexpectedBindings),
+		"repaired_bindings":       bindingsOutput(r.RepairedBindings),
+		"errors":                  r.Errors,
+		"pool_accounts":           poolOutput,
+	}
+}
+
+// verifyRoleSet checks the role set's service account, key and bindings still exist, and that the
+// accounts in its pool still exist with the role set's bindings.
+func (b *backend) verifyRoleSet(ctx context.Context, req *logical.Request, rs *RoleSet, repair bool) *driftReport {
+	report := &driftReport{
+		Parent: driftParentRoleSet,
+		Name:   rs.Name,
+	}
+	resources := rs.boundResources()
+	if resources == nil {
+		report.Errors = append(report.Errors, "role set has no service account")
+		return report
+	}
+	b.verifyAccountResources(ctx, req, resources, report, repair)
+
+	for _, poolResources := range rs.poolResources() {
+		poolReport := &driftReport{
+			Parent: driftParentRoleSet,
+			Name:   rs.Name,
+		}
+		b.verifyAccountResources(ctx, req, poolResources, poolReport, repair)
+		report.PoolAccounts = append(report.PoolAccounts, poolReport)
+	}
+	return report
+}
+
+// verifyStaticAccount checks the static account's service account, key and bindings still exist.
+func (b *backend) verifyStaticAccount(ctx context.Context, req *logical.Request, a *StaticAccount, repair bool) *driftReport {
+	report := &driftReport{
+		Parent: driftParentStaticAccount,
+		Name:   a.Name,
+	}
+	b.verifyAccountResources(ctx, req, a.boundResources(), report, repair)
+	return report
+}
+
+func (b *backend) verifyAccountResources(ctx context.Context, req *logical.Request, resources *gcpAccountResources, report *driftReport, repair bool) {
+	report.ServiceAccount = resources.accountId.EmailOrId
+
+	iamAdmin, err := b.IAMAdminClient(req.Storage)
+	if err != nil {
+		report.Errors = append(report.Errors, err.Error())
+		return
+	}
+
+	if _, err := b.getServiceAccount(iamAdmin, &resources.accountId); err != nil {
+		if !isGoogleAccountNotFoundErr(err) {
+			report.Errors = append(report.Errors, err.Error())
+			return
+		}
+		// Bindings can't be repaired without the service account.
+		report.ServiceAccountMissing = true
+		repair = false
+	}
+
+	if resources.tokenGen != nil && resources.tokenGen.KeyName != "" && !report.ServiceAccountMissing {
+		_, err := iamAdmin.Projects.ServiceAccounts.Keys.Get(resources.tokenGen.KeyName).Context(ctx).Do()
+		switch {
+		case isGoogleAccountKeyNotFoundErr(err):
+			report.KeyMissing = true
+		case err != nil:
+			report.Errors = append(report.Errors, errwrap.Wrapf("unable to get service account key: {{err}}", err).Error())
+		}
+	}
+
+	expected := accountBoundBindings(resources.bindings, resources.conditionalBindings)
+	if err := b.diffAccountBindings(ctx, req, resources.accountId.EmailOrId, expected, report); err != nil {
+		report.Errors = append(report.Errors, err.Error())
+		return
+	}
+
+	if repair && len(report.MissingBindings) > 0 {
+		b.repairBindings(ctx, req, resources.accountId.EmailOrId, report)
+	}
+}
+
+// accountBoundBindings flattens the unconditional and conditional bindings, grouped by resource.
+func accountBoundBindings(bindings ResourceBindings, conditional ConditionalBindings) map[string][]*boundBinding {
+	byResource := make(map[string][]*boundBinding)
+	add := func(condition *iamutil.Condition, binds ResourceBindings) {
+		for resource, roles := range binds {
+			for role := range roles {
+				byResource[resource] = append(byResource[resource], &boundBinding{
+					Resource:  resource,
+					Role:      role,
+					Condition: condition,
+				})
+			}
+		}
+	}
+	add(nil, bindings)
+	for _, cb := range conditional {
+		add(&cb.Condition, cb.Bindings)
+	}
+	return byResource
+}
+
+// diffAccountBindings compares the expected bindings of a service account with the IAM policy of
+// each bound resource.
+func (b *backend) diffAccountBindings(ctx context.Context, req *logical.Request, email string, expected map[string][]*boundBinding, report *driftReport) error {
+	httpC, err := b.HTTPClient(req.Storage)
+	if err != nil {
+		return err
+	}
+	apiHandle := iamutil.GetApiHandle(httpC, useragent.PluginString(b.pluginEnv,
+		userAgentPluginName))
+
+	resourceNames := make([]string, 0, len(expected))
+	for resName := range expected {
+		resourceNames = append(resourceNames, resName)
+	}
+	sort.Strings(resourceNames)
+
+	member := fmt.Sprintf(iamutil.ServiceAccountMemberTmpl, email)
+	for _, resName := range resourceNames {
+		resource, err := b.resources.Parse(resName)
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("unable to parse resource %q: %v", resName, err))
+			continue
+		}
+		p, err := resource.GetIamPolicy(ctx, apiHandle)
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("unable to get IAM policy for resource %q: %v", resName, err))
+			continue
+		}
+
+		var actual []*boundBinding
+		for _, bind := range p.Bindings {
+			for _, m := range bind.Members {
+				if m == member {
+					actual = append(actual, &boundBinding{Resource: resName, Role: bind.Role, Condition: bind.Condition})
+					break
+				}
+			}
+		}
+
+		report.MissingBindings = append(report.MissingBindings, subBoundBindings(expected[resName], actual)...)
+		report.UnexpectedBindings = append(report.UnexpectedBindings, subBoundBindings(actual, expected[resName])...)
+	}
+	sortBoundBindings(report.MissingBindings)
+	sortBoundBindings(report.UnexpectedBindings)
+	return nil
+}
+
+// repairBindings re-applies the report's missing bindings.
+func (b *backend) repairBindings(ctx context.Context, req *logical.Request, email string, report *driftReport) {
+	var unconditional ResourceBindings
+	var conditional ConditionalBindings
+	for _, bb := range report.MissingBindings {
+		var binds ResourceBindings
+		if bb.Condition == nil {
+			if unconditional == nil {
+				unconditional = make(ResourceBindings)
+			}
+			binds = unconditional
+		} else if binds = conditional.find(bb.Condition); binds == nil {
+			binds = make(ResourceBindings)
+			conditional = append(conditional, &ConditionalBinding{Condition: *bb.Condition, Bindings: binds})
+		}
+		if _, ok := binds[bb.Resource]; !ok {
+			binds[bb.Resource] = make(util.StringSet)
+		}
+		binds[bb.Resource].Add(bb.Role)
+	}
+
+	b.Logger().Info("repairing missing IAM bindings", "service_account", email, "bindings", len(report.MissingBindings))
+	if err := b.createAccountIamBindings(ctx, req, email, unconditional, conditional); err != nil {
+		report.Errors = append(report.Errors, errwrap.Wrapf("unable to repair missing bindings: {{err}}", err).Error())
+		return
+	}
+	report.RepairedBindings = report.MissingBindings
+	b.tokenCache.ExpireAccount(email)
+}
+
+// subBoundBindings returns the bindings in a that are not in b.
+func subBoundBindings(a, b []*boundBinding) []*boundBinding {
+	var out []*boundBinding
+	for _, x := range a {
+		found := false
+		for _, y := range b {
+			if x.Resource == y.Resource && x.Role == y.Role && x.Condition.Equals(y.Condition) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			out = append(out, x)
+		}
+	}
+	return out
+}
+
+func sortBoundBindings(bbs []*boundBinding) {
+	sort.SliceStable(bbs, func(i, j int) bool {
+		if bbs[i].Resource != bbs[j].Resource {
+			return bbs[i].Resource < bbs[j].Resource
+		}
+		return bbs[i].Role < bbs[j].Role
+	})
+}
+
+// verifyAll checks every role set and static account, holding the respective lock while each is checked.
+func (b *backend) verifyAll(ctx context.Context, req *logical.Request, repair bool) ([]*driftReport, error) {
+	var reports []*driftReport
+
+	rsNames, err := req.Storage.List(ctx, fmt.Sprintf("%s/", rolesetStoragePrefix))
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range rsNames {
+		report, err := func() (*driftReport, error) {
+			b.rolesetLock.Lock()
+			defer b.rolesetLock.Unlock()
+
+			rs, err := getRoleSet(name, ctx, req.Storage)
+			if err != nil || rs == nil {
+				return nil, err
+			}
+			return b.verifyRoleSet(ctx, req, rs, repair), nil
+		}()
+		if err != nil {
+			return nil, err
+		}
+		if report != nil {
+			reports = append(reports, report)
+		}
+	}
+
+	saNames, err := req.Storage.List(ctx, fmt.Sprintf("%s/", staticAccountStoragePrefix))
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range saNames {
+		report, err := func() (*driftReport, error) {
+			b.staticAccountLock.Lock()
+			defer b.staticAccountLock.Unlock()
+
+			a, err := b.getStaticAccount(name, ctx, req.Storage)
+			if err != nil || a == nil {
+				return nil, err
+			}
+			return b.verifyStaticAccount(ctx, req, a, repair), nil
+		}()
+		if err != nil {
+			return nil, err
+		}
+		if report != nil {
+			reports = append(reports, report)
+		}
+	}
+	return reports, nil
+}
+
+// periodicDriftCheck runs the drift check if it is enabled and due.
+func (b *backend) periodicDriftCheck(ctx context.Context, req *logical.Request) error {
+	// Only the primary's active node checks for drift, so it is reported once.
+	if b.System().ReplicationState().HasState(consts.ReplicationPerformanceSecondary | consts.ReplicationPerformanceStandby) {
+		return nil
+	}
+
+	cfg, err := getConfig(ctx, req.Storage)
+	if err != nil {
+		return err
+	}
+	if cfg == nil || cfg.DriftCheckInterval <= 0 {
+		return nil
+	}
+
+	b.driftCheckLock.Lock()
+	defer b.driftCheckLock.Unlock()
+	if time.Since(b.lastDriftCheck) < cfg.DriftCheckInterval {
+		return nil
+	}
+	b.lastDriftCheck = time.Now()
+
+	b.Logger().Debug("checking role sets and static accounts for IAM drift")
+	reports, err := b.verifyAll(ctx, req, false)
+	if err != nil {
+		return errwrap.Wrapf("unable to check for IAM drift: {{err}}", err)
+	}
+	for _, report := range reports {
+		for _, r := range report.accountReports() {
+			if len(r.Errors) > 0 {
+				b.Logger().Warn("unable to fully check for IAM drift", "type", r.Parent, "name", r.Name, "service_account", r.ServiceAccount, "errors", r.Errors)
+			}
+			if !r.hasAccountDrift() {
+				continue
+			}
+			b.Logger().Warn("detected IAM drift",
+				"type", r.Parent,
+				"name", r.Name,
+				"service_account", r.ServiceAccount,
+				"service_account_missing", r.ServiceAccountMissing,
+				"key_missing", r.KeyMissing,
+				"missing_bindings", len(r.MissingBindings),
+				"unexpected_bindings", len(r.UnexpectedBindings))
+			b.sendDriftEvent(ctx, r)
+		}
+	}
+	return nil
+}
+
+// sendDriftEvent emits an event for the report. Failures are only logged, as events may not be enabled.
+func (b *backend) sendDriftEvent(ctx context.Context, report *driftReport) {
+	ev, err := logical.NewEvent()
+	if err != nil {
+		b.Logger().Debug("unable to create drift event", "error", err)
+		return
+	}
+	ev.Metadata, err = structpb.NewStruct(map[string]interface{}{
+		"type":                    report.Parent,
+		"name":                    report.Name,
+		"service_account_email":   report.ServiceAccount,
+		"service_account_missing": report.ServiceAccountMissing,
+		"key_missing":             report.KeyMissing,
+		"missing_bindings":        float64(len(report.MissingBindings)),
+		"unexpected_bindings":     float64(len(report.UnexpectedBindings)),
+	})
+	if err != nil {
+		b.Logger().Debug("unable to create drift event metadata", "error", err)
+		return
+	}
+	if err := b.SendEvent(ctx, driftEventType, ev); err != nil {
+		b.Logger().Debug("unable to send drift event", "error", err)
+	}
+}
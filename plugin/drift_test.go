@@ -0,0 +1,59 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"testing"
+
+	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/iamutil"
+	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/util"
+)
+
+func Test_DriftBoundBindings(t *testing.T) {
+	project := "//cloudresourcemanager.googleapis.com/projects/project"
+	bucket := "//storage.googleapis.com/buckets/bucket"
+	cond := iamutil.Condition{Title: "expiry", Expression: `request.time < timestamp("2030-01-01T00:00:00Z")`}
+
+	expected := accountBoundBindings(
+		ResourceBindings{
+			project: util.ToSet([]string{"roles/viewer"}),
+			bucket:  util.ToSet([]string{"roles/storage.objectViewer"}),
+		},
+		ConditionalBindings{{
+			Condition: cond,
+			Bindings:  ResourceBindings{project: util.ToSet([]string{"roles/editor"})},
+		}},
+	)
+	if len(expected[project]) != 2 || len(expected[bucket]) != 1 {
+		t.Fatalf("unexpected grouping of bound bindings: %v", expected)
+	}
+
+	otherCond := cond
+	otherCond.Expression = `request.time < timestamp("2031-01-01T00:00:00Z")`
+	actual := []*boundBinding{
+		{Resource: project, Role: "roles/viewer"},
+		{Resource: project, Role: "roles/editor", Condition: &otherCond},
+		{Resource: project, Role: "roles/owner"},
+	}
+
+	missing := subBoundBindings(expected[project], actual)
+	if len(missing) != 1 || missing[0].Role != "roles/editor" || !missing[0].Condition.Equals(&cond) {
+		t.Fatalf("expected conditional roles/editor to be missing, got %v", missing)
+	}
+
+	unexpected := subBoundBindings(actual, expected[project])
+	sortBoundBindings(unexpected)
+	if len(unexpected) != 2 || unexpected[0].Role != "roles/editor" || unexpected[1].Role != "roles/owner" {
+		t.Fatalf("expected roles/editor with other condition and roles/owner to be unexpected, got %v", unexpected)
+	}
+
+	report := &driftReport{Parent: driftParentRoleSet, Name: "rs"}
+	if report.hasDrift() {
+		t.Fatal("expected empty report to have no drift")
+	}
+	report.UnexpectedBindings = unexpected
+	if !report.hasDrift() {
+		t.Fatal("expected report with unexpected bindings to have drift")
+	}
+}
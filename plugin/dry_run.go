@@ -0,0 +1,279 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/go-gcp-common/gcputil"
+	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/iamutil"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/useragent"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const dryRunFieldDescription = "If true, validate the request and return the IAM policy changes and GCP resources it would create, without making any changes."
+
+func responseFieldsDryRun() map[string]*framework.FieldSchema {
+	return map[string]*framework.FieldSchema{
+		"dry_run": {
+			Type:        framework.TypeBool,
+			Description: "Set if the request was a dry run and no changes were made.",
+		},
+		"new_service_account": {
+			Type:        framework.TypeString,
+			Description: "Email of the service account that would be created, if any. The final name includes the creation time.",
+		},
+		"deleted_service_accounts": {
+			Type:        framework.TypeStringSlice,
+			Description: "Emails of the service accounts that would be deleted, including pool accounts.",
+		},
+		"new_key": {
+			Type:        framework.TypeBool,
+			Description: "Whether a new service account key would be created.",
+		},
+		"iam_changes": {
+			Type:        framework.TypeSlice,
+			Description: "Per-resource list of roles and the members that would be added to or removed from them.",
+		},
+	}
+}
+
+// plannedBindings are the roles a member would be bound to and unbound from under a condition.
+type plannedBindings struct {
+	member    string
+	condition *iamutil.Condition
+	add       ResourceBindings
+	remove    ResourceBindings
+}
+
+// accountBindingsPlan returns the planned bindings to bind (or unbind, if remove is true) a service
+// account to the given unconditional and conditional bindings.
+func accountBindingsPlan(email string, bindings ResourceBindings, conditional ConditionalBindings, remove bool) []*plannedBindings {
+	member := fmt.Sprintf(iamutil.ServiceAccountMemberTmpl, email)
+	groups := []*plannedBindings{{member: member, add: bindings}}
+	for _, cb := range conditional {
+		groups = append(groups, &plannedBindings{member: member, condition: &cb.Condition, add: cb.Bindings})
+	}
+	if remove {
+		for _, g := range groups {
+			g.add, g.remove = nil, g.add
+		}
+	}
+	return groups
+}
+
+// bindingGroupChangesPlan returns the planned bindings to change a service account's bindings in place.
+func bindingGroupChangesPlan(email string, oldBinds ResourceBindings, oldConditional ConditionalBindings, newBinds ResourceBindings, newConditional ConditionalBindings) []*plannedBindings {
+	member := fmt.Sprintf(iamutil.ServiceAccountMemberTmpl, email)
+	var planned []*plannedBindings
+	for _, change := range bindingGroupChanges(oldBinds, oldConditional, newBinds, newConditional) {
+		planned = append(planned, &plannedBindings{
+			member:    member,
+			condition: change.condition,
+			add:       change.newBindings.sub(change.oldBindings),
+			remove:    change.oldBindings.sub(change.newBindings),
+		})
+	}
+	return planned
+}
+
+// planIamChanges fetches the current IAM policy of every resource in the planned bindings and
+// returns the members that would be added to and removed from each role, per resource.
+// Resources whose policies would not change are omitted.
+func (b *backend) planIamChanges(ctx context.Context, req *logical.Request, planned []*plannedBindings) ([]map[string]interface{}, error) {
+	resourceSet := make(map[string]struct{})
+	for _, pb := range planned {
+		for resName, roles := range pb.add {
+			if len(roles) > 0 {
+				resourceSet[resName] = struct{}{}
+			}
+		}
+		for resName, roles := range pb.remove {
+			if len(roles) > 0 {
+				resourceSet[resName] = struct{}{}
+			}
+		}
+	}
+	resourceNames := make([]string, 0, len(resourceSet))
+	for resName := range resourceSet {
+		resourceNames = append(resourceNames, resName)
+	}
+	sort.Strings(resourceNames)
+
+	httpC, err := b.HTTPClient(req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	apiHandle := iamutil.GetApiHandle(httpC, useragent.PluginString(b.pluginEnv,
+		userAgentPluginName))
+
+	changes := make([]map[string]interface{}, 0, len(resourceNames))
+	for _, resName := range resourceNames {
+		resource, err := b.resources.Parse(resName)
+		if err != nil {
+			return nil, errwrap.Wrapf(fmt.Sprintf("unable to parse resource %q: {{err}}", resName), err)
+		}
+
+		before, err := resource.GetIamPolicy(ctx, apiHandle)
+		if err != nil {
+			return nil, errwrap.Wrapf(fmt.Sprintf("unable to get IAM policy for resource %q: {{err}}", resName), err)
+		}
+
+		after := before
+		for _, pb := range planned {
+			var toAdd, toRemove *iamutil.PolicyDelta
+			if roles := pb.add[resName]; len(roles) > 0 {
+				if pb.condition != nil && !iamutil.SupportsConditions(resource) {
+					return nil, fmt.Errorf("unable to set conditional IAM binding for resource %q: %w", resName, iamutil.ErrConditionsNotSupported)
+				}
+				toAdd = &iamutil.PolicyDelta{Roles: roles, Member: pb.member, Condition: pb.condition}
+			}
+			if roles := pb.remove[resName]; len(roles) > 0 {
+				toRemove = &iamutil.PolicyDelta{Roles: roles, Member: pb.member, Condition: pb.condition}
+			}
+			if toAdd == nil && toRemove == nil {
+				continue
+			}
+			_, after = after.ChangeBindings(toAdd, toRemove)
+		}
+
+		diffs := iamutil.DiffPolicies(before, after)
+		if len(diffs) == 0 {
+			continue
+		}
+		bindingChanges := make([]map[string]interface{}, 0, len(diffs))
+		for _, diff := range diffs {
+			c := map[string]interface{}{
+				"role":            diff.Role,
+				"members_added":   diff.MembersAdded,
+				"members_removed": diff.MembersRemoved,
+			}
+			if diff.Condition != nil {
+				c["condition"] = map[string]interface{}{
+					"title":       diff.Condition.Title,
+					"description": diff.Condition.Description,
+					"expression":  diff.Condition.Expression,
+				}
+			}
+			bindingChanges = append(bindingChanges, c)
+		}
+		changes = append(changes, map[string]interface{}{
+			"resource": resName,
+			"bindings": bindingChanges,
+		})
+	}
+	return changes, nil
+}
+
+// roleSetRotationPlan returns the planned bindings of moving the role set to the new account, and
+// the emails of the accounts that would be deleted. The pool accounts are deleted along with the
+// old account.
+func roleSetRotationPlan(rs *RoleSet, newEmail string, newBinds ResourceBindings, newConditionalBinds ConditionalBindings) ([]*plannedBindings, []string) {
+	planned := accountBindingsPlan(newEmail, newBinds, newConditionalBinds, false)
+	deletedAccounts := []string{}
+	for _, id := range rs.accounts() {
+		deletedAccounts = append(deletedAccounts, id.EmailOrId)
+		planned = append(planned, accountBindingsPlan(id.EmailOrId, rs.Bindings, rs.ConditionalBindings, true)...)
+	}
+	return planned, deletedAccounts
+}
+
+// roleSetInPlacePlan returns the planned bindings of changing the bindings of the role set's
+// service account and pool accounts in place.
+func roleSetInPlacePlan(rs *RoleSet, newBinds ResourceBindings, newConditionalBinds ConditionalBindings) []*plannedBindings {
+	var planned []*plannedBindings
+	for _, id := range rs.accounts() {
+		planned = append(planned, bindingGroupChangesPlan(id.EmailOrId, rs.Bindings, rs.ConditionalBindings, newBinds, newConditionalBinds)...)
+	}
+	return planned
+}
+
+// planRoleSetUpdate returns the changes saving a role set with the given bindings would make. Unless
+// the role set is updated in place, new bindings move it to a new service account.
+func (b *backend) planRoleSetUpdate(ctx context.Context, req *logical.Request, rs *RoleSet, project string, newBinds ResourceBindings, newConditionalBinds ConditionalBindings) (*logical.Response, error) {
+	if rs.updatesInPlace() {
+		return b.planRoleSetUpdateInPlace(ctx, req, rs, newBinds, newConditionalBinds)
+	}
+
+	newAccount := gcputil.ServiceAccountId{
+		Project:   project,
+		EmailOrId: emailForServiceAccountName(project, generateAccountNameForRoleSet(rs.Name)),
+	}
+
+	planned, deletedAccounts := roleSetRotationPlan(rs, newAccount.EmailOrId, newBinds, newConditionalBinds)
+	changes, err := b.planIamChanges(ctx, req, planned)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"dry_run":                  true,
+			"new_service_account":      newAccount.EmailOrId,
+			"deleted_service_accounts": deletedAccounts,
+			"new_key":                  rs.SecretType == SecretTypeAccessToken,
+			"iam_changes":              changes,
+		},
+	}, nil
+}
+
+// planRoleSetUpdateInPlace returns the bindings changes on the role set's existing service account
+// and pool accounts.
+func (b *backend) planRoleSetUpdateInPlace(ctx context.Context, req *logical.Request, rs *RoleSet, newBinds ResourceBindings, newConditionalBinds ConditionalBindings) (*logical.Response, error) {
+	changes, err := b.planIamChanges(ctx, req, roleSetInPlacePlan(rs, newBinds, newConditionalBinds))
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"dry_run":                  true,
+			"new_service_account":      "",
+			"deleted_service_accounts": []string{},
+			"new_key":                  false,
+			"iam_changes":              changes,
+		},
+	}, nil
+}
+
+// planStaticAccountUpdate returns the changes creating (if a is nil) or updating a static account
+// with the given input would make. Static accounts never create or delete service accounts.
+func (b *backend) planStaticAccountUpdate(ctx context.Context, req *logical.Request, a *StaticAccount, input *inputParams) (*logical.Response, error) {
+	var planned []*plannedBindings
+	newKey := false
+	if a == nil {
+		iamAdmin, err := b.IAMAdminClient(req.Storage)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := b.getServiceAccount(iamAdmin, &gcputil.ServiceAccountId{
+			Project:   gcpServiceAccountInferredProject,
+			EmailOrId: input.serviceAccountEmail,
+		}); err != nil {
+			return logical.ErrorResponse("unable to confirm service account %q exists: %v", input.serviceAccountEmail, err), nil
+		}
+
+		planned = accountBindingsPlan(input.serviceAccountEmail, input.bindings, input.conditionalBindings, false)
+		newKey = input.secretType == SecretTypeAccessToken
+	} else if input.hasBindings {
+		planned = bindingGroupChangesPlan(a.EmailOrId, a.Bindings, a.ConditionalBindings, input.bindings, input.conditionalBindings)
+	}
+
+	changes, err := b.planIamChanges(ctx, req, planned)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"dry_run":     true,
+			"new_key":     newKey,
+			"iam_changes": changes,
+		},
+	}, nil
+}
@@ -5,24 +5,38 @@ package gcpsecrets
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/hashicorp/errwrap"
 	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/util"
 	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/automatedrotationutil"
 )
 
 type inputParams struct {
 	name       string
 	secretType string
 
-	hasBindings bool
-	rawBindings string
-	bindings    ResourceBindings
+	hasBindings         bool
+	rawBindings         string
+	bindings            ResourceBindings
+	conditionalBindings ConditionalBindings
 
 	project             string
 	serviceAccountEmail string
 
 	scopes []string
+
+	allowedAudiences []string
+
+	accessBoundary *AccessBoundary
+
+	rotation     automatedrotationutil.AutomatedRotationParams
+	rotationInfo automatedrotationutil.RotationInfoResponseParams
+
+	credentialMode    string
+	keyRotationPeriod time.Duration
+	keyOverlapPeriod  time.Duration
 }
 
 func (input *inputParams) parseOkInputSecretType(d *framework.FieldData) (warnings []string, err error) {
@@ -35,7 +49,7 @@ func (input *inputParams) parseOkInputSecretType(d *framework.FieldData) (warnin
 	}
 
 	switch secretType {
-	case SecretTypeKey, SecretTypeAccessToken:
+	case SecretTypeKey, SecretTypeAccessToken, SecretTypeImpersonatedAccessToken, SecretTypeHmacKey:
 		input.secretType = secretType
 		return nil, nil
 	default:
@@ -77,16 +91,78 @@ func (input *inputParams) parseOkInputTokenScopes(d *framework.FieldData) (warni
 		input.scopes = scopes
 	}
 
-	if input.secretType == SecretTypeAccessToken && len(input.scopes) == 0 {
-		return nil, fmt.Errorf("non-empty token_scopes must be provided for generating access_token secrets")
+	if isAccessTokenSecretType(input.secretType) && len(input.scopes) == 0 {
+		return nil, fmt.Errorf("non-empty token_scopes must be provided for generating %s secrets", input.secretType)
 	}
 
-	if input.secretType != SecretTypeAccessToken && ok && len(input.scopes) > 0 {
-		warnings = append(warnings, "ignoring non-empty token_scopes, secret type not access_token")
+	if !isAccessTokenSecretType(input.secretType) && ok && len(input.scopes) > 0 {
+		warnings = append(warnings, "ignoring non-empty token_scopes, secret type not access_token or impersonated_access_token")
 	}
 	return
 }
 
+// parseOkInputCredentialMode parses the credential mode of a service_account_key static account
+// and, in rotating mode, the rotation and overlap periods of its key. The mode cannot be updated.
+func (input *inputParams) parseOkInputCredentialMode(d *framework.FieldData) (warnings []string, err error) {
+	if v, ok := d.GetOk("credential_mode"); ok {
+		mode := v.(string)
+		switch mode {
+		case credentialModeLeased, credentialModeRotating:
+		default:
+			return nil, fmt.Errorf("invalid credential_mode %q, must be one of %q or %q", mode, credentialModeLeased, credentialModeRotating)
+		}
+		if input.credentialMode != "" && input.credentialMode != mode {
+			return nil, fmt.Errorf("cannot update credential_mode")
+		}
+		input.credentialMode = mode
+	}
+	if input.credentialMode == "" {
+		input.credentialMode = credentialModeLeased
+	}
+
+	_, overlapOk := d.GetOk("overlap_period")
+	if input.credentialMode != credentialModeRotating {
+		if overlapOk {
+			return nil, fmt.Errorf("overlap_period only applies to %q credential mode", credentialModeRotating)
+		}
+		return nil, nil
+	}
+
+	if input.secretType != SecretTypeKey {
+		return nil, fmt.Errorf("%q credential mode is only supported for %q secret type", credentialModeRotating, SecretTypeKey)
+	}
+	if v, ok := d.GetOk("rotation_period"); ok {
+		input.keyRotationPeriod = time.Duration(v.(int)) * time.Second
+	}
+	if overlapOk {
+		input.keyOverlapPeriod = time.Duration(d.Get("overlap_period").(int)) * time.Second
+	}
+	if input.keyRotationPeriod < minKeyRotationPeriod {
+		return nil, fmt.Errorf("rotation_period of at least %s is required for %q credential mode", minKeyRotationPeriod, credentialModeRotating)
+	}
+	if input.keyOverlapPeriod < 0 || input.keyOverlapPeriod >= input.keyRotationPeriod {
+		return nil, fmt.Errorf("overlap_period must be less than rotation_period")
+	}
+	return nil, nil
+}
+
+func (input *inputParams) parseOkInputAccessBoundary(d *framework.FieldData) (warnings []string, err error) {
+	v, ok := d.GetOk("access_boundary")
+	if !ok {
+		return nil, nil
+	}
+
+	boundary, err := parseAccessBoundary(v.(string))
+	if err != nil {
+		return nil, err
+	}
+	if boundary != nil && !isAccessTokenSecretType(input.secretType) {
+		warnings = append(warnings, "access_boundary only applies to access_token or impersonated_access_token secrets")
+	}
+	input.accessBoundary = boundary
+	return warnings, nil
+}
+
 func (input *inputParams) parseOkInputBindings(d *framework.FieldData) (warnings []string, err error) {
 	bRaw, ok := d.GetOk("bindings")
 	if !ok {
@@ -99,13 +175,18 @@ func (input *inputParams) parseOkInputBindings(d *framework.FieldData) (warnings
 		return nil, fmt.Errorf("bindings are not a string")
 	}
 
-	bindings, err := util.ParseBindings(bRaw.(string))
+	bindings, conditional, err := util.ParseConditionalBindings(bRaw.(string))
 	if err != nil {
 		return nil, errwrap.Wrapf("unable to parse bindings: {{err}}", err)
 	}
+	conditionalBindings := toConditionalBindings(conditional)
+	if err := checkDuplicateAliases(bindings, conditionalBindings); err != nil {
+		return nil, err
+	}
 
 	input.hasBindings = true
 	input.rawBindings = rawBindings
 	input.bindings = bindings
+	input.conditionalBindings = conditionalBindings
 	return nil, nil
 }
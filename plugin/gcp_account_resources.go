@@ -10,11 +10,14 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
+	"sort"
+	"sync/atomic"
 	"time"
 
 	"github.com/hashicorp/errwrap"
 	"github.com/hashicorp/go-gcp-common/gcputil"
 	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/go-uuid"
 	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/iamutil"
 	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/util"
 	"github.com/hashicorp/vault/sdk/helper/useragent"
@@ -29,6 +32,11 @@ const (
 
 	maxBackoff   = 32 * time.Second
 	retryTimeout = 80 * time.Second
+
+	serviceAccountResourceTmpl = "//iam.googleapis.com/projects/%s/serviceAccounts/%s"
+
+	// mountIdStorageKey is the storage key of the random ID of this mount.
+	mountIdStorageKey = "mount_id"
 )
 
 type (
@@ -36,19 +44,42 @@ type (
 	// This includes a Vault-managed GCP service account (required), IAM bindings, and/or key via TokenGenerator
 	// (for generating access tokens).
 	gcpAccountResources struct {
-		accountId gcputil.ServiceAccountId
-		bindings  ResourceBindings
-		tokenGen  *TokenGenerator
+		accountId           gcputil.ServiceAccountId
+		bindings            ResourceBindings
+		conditionalBindings ConditionalBindings
+		tokenGen            *TokenGenerator
+		tokenImpersonator   *TokenImpersonator
 	}
 
 	// ResourceBindings represent a map of GCP resource name to IAM roles to be bound on that resource.
 	ResourceBindings map[string]util.StringSet
 
+	// ConditionalBinding is a set of resource bindings that are only granted under an IAM Condition.
+	ConditionalBinding struct {
+		Condition iamutil.Condition
+		Bindings  ResourceBindings
+	}
+
+	// ConditionalBindings are the bindings of each IAM Condition set on a role set or static account.
+	ConditionalBindings []*ConditionalBinding
+
 	// TokenGenerator wraps the service account key and params required to create access tokens.
 	TokenGenerator struct {
 		KeyName    string
 		B64KeyJSON string
 		Scopes     []string
+
+		// CreateTime is when the key was created, which is unknown for keys created before it
+		// was recorded.
+		CreateTime time.Time `json:",omitempty"`
+	}
+
+	// TokenImpersonator holds the params required to create access tokens by impersonating
+	// the account with the root credentials, which are granted roles/iam.serviceAccountTokenCreator
+	// on the account instead of a key being created.
+	TokenImpersonator struct {
+		Principal string
+		Scopes    []string
 	}
 )
 
@@ -60,6 +91,64 @@ func (rb ResourceBindings) asOutput() map[string][]string {
 	return out
 }
 
+// resourceAliases returns the full resource name of each bound resource given as a short
+// resource URI, keyed by the alias.
+func resourceAliases(bindings ResourceBindings, conditional ConditionalBindings) map[string]string {
+	aliases := make(map[string]string)
+	add := func(rb ResourceBindings) {
+		for resourceName := range rb {
+			if name, isAlias, err := iamutil.ResolveResourceAlias(resourceName); err == nil && isAlias {
+				aliases[resourceName] = name
+			}
+		}
+	}
+	add(bindings)
+	for _, cb := range conditional {
+		add(cb.Bindings)
+	}
+	return aliases
+}
+
+// checkDuplicateAliases returns an error if the same resource is bound under more than one name,
+// e.g. as a short resource URI and as its full resource name, in the unconditional bindings or
+// in any group of conditional bindings. Its roles would otherwise be tracked twice.
+func checkDuplicateAliases(bindings ResourceBindings, conditional ConditionalBindings) error {
+	check := func(rb ResourceBindings) error {
+		names := make(map[string]string, len(rb))
+		for _, resourceName := range rb.resourceNames() {
+			name, _, err := iamutil.ResolveResourceAlias(resourceName)
+			if err != nil {
+				return err
+			}
+			if other, ok := names[name]; ok {
+				return fmt.Errorf("resources %q and %q are both %q, bind all of its roles under one name", other, resourceName, name)
+			}
+			names[name] = resourceName
+		}
+		return nil
+	}
+
+	if err := check(bindings); err != nil {
+		return err
+	}
+	for _, cb := range conditional {
+		if err := check(cb.Bindings); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resourceNames returns the bound resource names in sorted order.
+func (rb ResourceBindings) resourceNames() []string {
+	names := make([]string, 0, len(rb))
+	for name := range rb {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func (rb ResourceBindings) sub(toRemove ResourceBindings) ResourceBindings {
 	subbed := make(ResourceBindings)
 	for r, iamRoles := range rb {
@@ -72,6 +161,67 @@ func (rb ResourceBindings) sub(toRemove ResourceBindings) ResourceBindings {
 	return subbed
 }
 
+// toConditionalBindings converts parsed conditional bindings.
+func toConditionalBindings(parsed []*util.ConditionalBindings) ConditionalBindings {
+	if len(parsed) == 0 {
+		return nil
+	}
+	cbs := make(ConditionalBindings, 0, len(parsed))
+	for _, p := range parsed {
+		cbs = append(cbs, &ConditionalBinding{
+			Condition: iamutil.Condition(p.Condition),
+			Bindings:  p.Bindings,
+		})
+	}
+	return cbs
+}
+
+// find returns the bindings under the given condition, or nil if there are none.
+func (cbs ConditionalBindings) find(condition *iamutil.Condition) ResourceBindings {
+	for _, cb := range cbs {
+		if condition.Equals(&cb.Condition) {
+			return cb.Bindings
+		}
+	}
+	return nil
+}
+
+func (cbs ConditionalBindings) asOutput() []map[string]interface{} {
+	out := make([]map[string]interface{}, 0, len(cbs))
+	for _, cb := range cbs {
+		out = append(out, map[string]interface{}{
+			"condition": map[string]interface{}{
+				"title":       cb.Condition.Title,
+				"description": cb.Condition.Description,
+				"expression":  cb.Condition.Expression,
+			},
+			"bindings": cb.Bindings.asOutput(),
+		})
+	}
+	return out
+}
+
+// structuredBindings returns the bindings in the structured JSON format, sorted so that the same
+// bindings are always rendered the same way regardless of how they were written.
+func structuredBindings(bindings ResourceBindings, conditional ConditionalBindings) []*util.BindingsEntry {
+	entries := util.BindingsEntries(bindings, nil)
+	for _, cb := range conditional {
+		condition := util.Condition(cb.Condition)
+		entries = append(entries, util.BindingsEntries(cb.Bindings, &condition)...)
+	}
+	util.SortBindingsEntries(entries)
+	return entries
+}
+
+// boundRoles returns the roles bound on a resource under the given condition, where a nil
+// condition refers to the unconditional bindings.
+func boundRoles(bindings ResourceBindings, conditional ConditionalBindings, resource string, condition *iamutil.Condition) util.StringSet {
+	if condition == nil {
+		return bindings[resource]
+	}
+	return conditional.find(condition)[resource]
+}
+
 func getStringHash(bindingsRaw string) string {
 	ssum := sha256.Sum256([]byte(bindingsRaw))
 	return base64.StdEncoding.EncodeToString(ssum[:])
@@ -97,11 +247,32 @@ func (b *backend) createNewTokenGen(ctx context.Context, req *logical.Request, p
 		KeyName:    key.Name,
 		B64KeyJSON: key.PrivateKeyData,
 		Scopes:     scopes,
+		CreateTime: time.Now().UTC(),
 	}, nil
 }
 
+// createNewTokenImpersonator grants the root principal permission to create tokens for the given account.
+func (b *backend) createNewTokenImpersonator(ctx context.Context, req *logical.Request, accountId gcputil.ServiceAccountId, ti *TokenImpersonator) error {
+	b.Logger().Debug("creating new TokenImpersonator (token creator binding)", "account", accountId.ResourceName(), "principal", ti.Principal)
+	return b.createIamBindings(ctx, req, ti.Principal, tokenCreatorBindings(accountId))
+}
+
+// tokenCreatorBindings returns the bindings on the given service account that allow a principal to create
+// access tokens for it.
+func tokenCreatorBindings(accountId gcputil.ServiceAccountId) ResourceBindings {
+	return ResourceBindings{
+		fmt.Sprintf(serviceAccountResourceTmpl, accountId.Project, accountId.EmailOrId): util.ToSet([]string{tokenCreatorRole}),
+	}
+}
+
 func (b *backend) createIamBindings(ctx context.Context, req *logical.Request, saEmail string, binds ResourceBindings) error {
-	b.Logger().Debug("creating IAM bindings", "account_email", saEmail, "bindings", binds)
+	return b.createMemberIamBindings(ctx, req, fmt.Sprintf(iamutil.ServiceAccountMemberTmpl, saEmail), nil, binds)
+}
+
+// createMemberIamBindings binds the given member to the roles on each resource. If condition is
+// non-nil, the bindings are only granted under that IAM Condition.
+func (b *backend) createMemberIamBindings(ctx context.Context, req *logical.Request, member string, condition *iamutil.Condition, binds ResourceBindings) error {
+	b.Logger().Debug("creating IAM bindings", "member", member, "bindings", binds)
 	httpC, err := b.HTTPClient(req.Storage)
 	if err != nil {
 		return err
@@ -109,42 +280,104 @@ func (b *backend) createIamBindings(ctx context.Context, req *logical.Request, s
 	apiHandle := iamutil.GetApiHandle(httpC, useragent.PluginString(b.pluginEnv,
 		userAgentPluginName))
 
-	for resourceName, roles := range binds {
-		b.Logger().Debug("setting IAM binding", "resource", resourceName, "roles", roles)
+	// Parse every resource first so an invalid one fails before any policy is changed.
+	resources := make(map[string]iamutil.Resource, len(binds))
+	for resourceName := range binds {
 		resource, err := b.resources.Parse(resourceName)
 		if err != nil {
 			return err
 		}
+		if condition != nil && !iamutil.SupportsConditions(resource) {
+			return fmt.Errorf("unable to set conditional IAM binding for resource %q: %w", resourceName, iamutil.ErrConditionsNotSupported)
+		}
+		resources[resourceName] = resource
+	}
 
-		b.Logger().Debug("getting IAM policy for resource name", "name", resourceName)
-		p, err := resource.GetIamPolicy(ctx, apiHandle)
+	concurrency, err := b.iamPolicyUpdateConcurrency(ctx, req.Storage)
+	if err != nil {
+		return err
+	}
+	errs := iamutil.ForEachConcurrently(ctx, concurrency, binds.resourceNames(), func(ctx context.Context, resourceName string) error {
+		b.Logger().Debug("setting IAM binding", "resource", resourceName, "roles", binds[resourceName])
+		_, err := iamutil.UpdatePolicy(ctx, apiHandle, resources[resourceName], func(p *iamutil.Policy) (bool, *iamutil.Policy) {
+			return p.AddBindings(&iamutil.PolicyDelta{
+				Roles:     binds[resourceName],
+				Member:    member,
+				Condition: condition,
+			})
+		}, b.iamPolicyUpdateOptions(ctx, resourceName))
 		if err != nil {
-			return err
+			return errwrap.Wrapf(fmt.Sprintf("unable to set IAM policy for resource %q: {{err}}", resourceName), err)
 		}
+		return nil
+	})
 
-		b.Logger().Debug("got IAM policy for resource name", "name", resourceName)
-		changed, newP := p.AddBindings(&iamutil.PolicyDelta{
-			Roles: roles,
-			Email: saEmail,
-		})
-		if !changed || newP == nil {
-			continue
+	var merr *multierror.Error
+	for _, resourceName := range binds.resourceNames() {
+		if err, ok := errs[resourceName]; ok {
+			merr = multierror.Append(merr, err)
 		}
+	}
+	return merr.ErrorOrNil()
+}
 
-		b.Logger().Debug("setting IAM policy for resource name", "name", resourceName)
-		if _, err := resource.SetIamPolicy(ctx, apiHandle, newP); err != nil {
-			return errwrap.Wrapf(fmt.Sprintf("unable to set IAM policy for resource %q: {{err}}", resourceName), err)
+// createAccountIamBindings binds the service account to the unconditional and conditional bindings.
+func (b *backend) createAccountIamBindings(ctx context.Context, req *logical.Request, saEmail string, binds ResourceBindings, conditional ConditionalBindings) error {
+	if err := b.createIamBindings(ctx, req, saEmail, binds); err != nil {
+		return err
+	}
+	member := fmt.Sprintf(iamutil.ServiceAccountMemberTmpl, saEmail)
+	for _, cb := range conditional {
+		if err := b.createMemberIamBindings(ctx, req, member, &cb.Condition, cb.Bindings); err != nil {
+			return err
 		}
 	}
-
 	return nil
 }
 
+// getMountId returns the random ID of this mount, which is recorded on the service accounts it
+// creates. If create is false and the mount has no ID yet, an empty ID is returned.
+func (b *backend) getMountId(ctx context.Context, s logical.Storage, create bool) (string, error) {
+	b.mountIdLock.Lock()
+	defer b.mountIdLock.Unlock()
+	if b.mountId != "" {
+		return b.mountId, nil
+	}
+
+	entry, err := s.Get(ctx, mountIdStorageKey)
+	if err != nil {
+		return "", err
+	}
+	if entry != nil {
+		b.mountId = string(entry.Value)
+		return b.mountId, nil
+	}
+	if !create {
+		return "", nil
+	}
+
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return "", err
+	}
+	if err := s.Put(ctx, &logical.StorageEntry{Key: mountIdStorageKey, Value: []byte(id)}); err != nil {
+		return "", err
+	}
+	b.mountId = id
+	return id, nil
+}
+
 func (b *backend) createServiceAccount(ctx context.Context, req *logical.Request, project, saName, descriptor string) (*iam.ServiceAccount, error) {
+	mountId, err := b.getMountId(ctx, req.Storage, true)
+	if err != nil {
+		return nil, errwrap.Wrapf("unable to get mount ID: {{err}}", err)
+	}
+
 	createSaReq := &iam.CreateServiceAccountRequest{
 		AccountId: saName,
 		ServiceAccount: &iam.ServiceAccount{
 			DisplayName: roleSetServiceAccountDisplayName(descriptor),
+			Description: fmt.Sprintf(serviceAccountDescriptionTmpl, mountId),
 		},
 	}
 
@@ -170,6 +403,9 @@ func (b *backend) tryDeleteGcpAccountResources(ctx context.Context, req *logical
 
 	b.Logger().Debug("try to delete GCP account resources", "bound_resources", boundResources, "remove_service_account", removeServiceAccount)
 
+	// Don't hand out tokens that were generated with the resources being removed.
+	b.tokenCache.ExpireAccount(boundResources.accountId.EmailOrId)
+
 	iamAdmin, err := b.IAMAdminClient(req.Storage)
 	if err != nil {
 		return []string{err.Error()}
@@ -183,7 +419,15 @@ func (b *backend) tryDeleteGcpAccountResources(ctx context.Context, req *logical
 		}
 	}
 
-	if merr := b.removeBindings(ctx, req, boundResources.accountId.EmailOrId, boundResources.bindings); merr != nil {
+	// Bindings on the service account itself are deleted with it.
+	if boundResources.tokenImpersonator != nil && !removeServiceAccount {
+		if merr := b.removeBindings(ctx, req, boundResources.tokenImpersonator.Principal, tokenCreatorBindings(boundResources.accountId)); merr != nil {
+			w := fmt.Sprintf("unable to delete token creator binding for %q on service account %q (WAL entry to clean-up later has been added): %v", boundResources.tokenImpersonator.Principal, boundResources.accountId.ResourceName(), merr)
+			warnings = append(warnings, w)
+		}
+	}
+
+	if merr := b.removeAccountBindings(ctx, req, boundResources.accountId.EmailOrId, boundResources.bindings, boundResources.conditionalBindings); merr != nil {
 		for _, err := range merr.Errors {
 			w := fmt.Sprintf("unable to delete IAM policy bindings for service account %q (WAL entry to clean-up later has been added): %v", boundResources.accountId.EmailOrId, err)
 			warnings = append(warnings, w)
@@ -218,6 +462,24 @@ func (b *backend) deleteTokenGenKey(ctx context.Context, iamAdmin *iam.Service,
 }
 
 func (b *backend) removeBindings(ctx context.Context, req *logical.Request, email string, bindings ResourceBindings) (allErr *multierror.Error) {
+	return b.removeMemberBindings(ctx, req, fmt.Sprintf(iamutil.ServiceAccountMemberTmpl, email), nil, bindings)
+}
+
+// removeAccountBindings unbinds the service account from the unconditional and conditional bindings.
+func (b *backend) removeAccountBindings(ctx context.Context, req *logical.Request, email string, bindings ResourceBindings, conditional ConditionalBindings) *multierror.Error {
+	allErr := b.removeBindings(ctx, req, email, bindings)
+	member := fmt.Sprintf(iamutil.ServiceAccountMemberTmpl, email)
+	for _, cb := range conditional {
+		if merr := b.removeMemberBindings(ctx, req, member, &cb.Condition, cb.Bindings); merr != nil {
+			allErr = multierror.Append(allErr, merr.Errors...)
+		}
+	}
+	return allErr
+}
+
+// removeMemberBindings unbinds the given member from the roles on each resource, only
+// touching bindings with the given IAM Condition.
+func (b *backend) removeMemberBindings(ctx context.Context, req *logical.Request, member string, condition *iamutil.Condition, bindings ResourceBindings) (allErr *multierror.Error) {
 	httpC, err := b.HTTPClient(req.Storage)
 	if err != nil {
 		return &multierror.Error{Errors: []error{err}}
@@ -226,34 +488,87 @@ func (b *backend) removeBindings(ctx context.Context, req *logical.Request, emai
 	apiHandle := iamutil.GetApiHandle(httpC, useragent.PluginString(b.pluginEnv,
 		userAgentPluginName))
 
-	for resName, roles := range bindings {
+	concurrency, err := b.iamPolicyUpdateConcurrency(ctx, req.Storage)
+	if err != nil {
+		return &multierror.Error{Errors: []error{err}}
+	}
+	errs := iamutil.ForEachConcurrently(ctx, concurrency, bindings.resourceNames(), func(ctx context.Context, resName string) error {
 		resource, err := b.resources.Parse(resName)
 		if err != nil {
-			allErr = multierror.Append(allErr, errwrap.Wrapf(fmt.Sprintf("unable to delete role binding for resource '%s': {{err}}", resName), err))
-			continue
+			return errwrap.Wrapf(fmt.Sprintf("unable to delete role binding for resource '%s': {{err}}", resName), err)
 		}
 
-		p, err := resource.GetIamPolicy(ctx, apiHandle)
+		_, err = iamutil.UpdatePolicy(ctx, apiHandle, resource, func(p *iamutil.Policy) (bool, *iamutil.Policy) {
+			return p.RemoveBindings(&iamutil.PolicyDelta{
+				Roles:     bindings[resName],
+				Member:    member,
+				Condition: condition,
+			})
+		}, b.iamPolicyUpdateOptions(ctx, resName))
 		if err != nil {
-			allErr = multierror.Append(allErr, errwrap.Wrapf(fmt.Sprintf("unable to delete role binding for resource '%s': {{err}}", resName), err))
-			continue
+			return errwrap.Wrapf(fmt.Sprintf("unable to delete role binding for resource '%s': {{err}}", resName), err)
 		}
+		return nil
+	})
 
-		changed, newP := p.RemoveBindings(&iamutil.PolicyDelta{
-			Email: email,
-			Roles: roles,
-		})
-		if !changed {
-			continue
-		}
-		if _, err = resource.SetIamPolicy(ctx, apiHandle, newP); err != nil {
-			allErr = multierror.Append(allErr, errwrap.Wrapf(fmt.Sprintf("unable to delete role binding for resource '%s': {{err}}", resName), err))
-			continue
+	for _, resName := range bindings.resourceNames() {
+		if err, ok := errs[resName]; ok {
+			allErr = multierror.Append(allErr, err)
 		}
 	}
 	return
 }
 
+// iamPolicyUpdateConcurrency returns the number of resources whose IAM policies are updated at
+// the same time.
+func (b *backend) iamPolicyUpdateConcurrency(ctx context.Context, s logical.Storage) (int, error) {
+	cfg, err := getConfig(ctx, s)
+	if err != nil {
+		return 0, err
+	}
+	if cfg == nil || cfg.IamPolicyConcurrency <= 0 {
+		return iamutil.DefaultPolicyUpdateConcurrency, nil
+	}
+	return cfg.IamPolicyConcurrency, nil
+}
+
+// iamPolicyUpdateOptions returns the options for updating the IAM policy of a resource, which
+// log each etag conflict and count it in the policyConflicts of ctx, if any.
+func (b *backend) iamPolicyUpdateOptions(ctx context.Context, resourceName string) *iamutil.PolicyUpdateOptions {
+	conflicts, _ := ctx.Value(policyConflictsKey{}).(*policyConflicts)
+	return &iamutil.PolicyUpdateOptions{
+		OnConflict: func(attempt int, err error) {
+			b.Logger().Warn("IAM policy changed concurrently, retrying update", "resource", resourceName, "attempt", attempt, "error", err)
+			if conflicts != nil {
+				conflicts.count.Add(1)
+			}
+		},
+	}
+}
+
+type policyConflictsKey struct{}
+
+// policyConflicts counts the IAM policy etag conflicts retried while handling a request, so a
+// write can warn that the policies it changed are also being changed by someone else.
+type policyConflicts struct {
+	count atomic.Int64
+}
+
+// withPolicyConflicts returns a context whose IAM policy updates count their etag conflicts.
+func withPolicyConflicts(ctx context.Context) (context.Context, *policyConflicts) {
+	conflicts := &policyConflicts{}
+	return context.WithValue(ctx, policyConflictsKey{}, conflicts), conflicts
+}
+
+// warning returns a response warning if any etag conflicts were retried.
+func (c *policyConflicts) warning() string {
+	n := c.count.Load()
+	if n == 0 {
+		return ""
+	}
+	return fmt.Sprintf("IAM policies were changed concurrently %d time(s) during this update and the update was retried; check whether another tool manages the same bindings", n)
+}
+
 func (b *backend) deleteServiceAccount(ctx context.Context, iamAdmin *iam.Service, account gcputil.ServiceAccountId) error {
 	if account.EmailOrId == "" {
 		return nil
@@ -7,9 +7,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/iamutil"
+	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/util"
 )
 
 func Test_RoleSetServiceAccountDisplayName(t *testing.T) {
@@ -155,3 +159,185 @@ func assertDuration(t *testing.T, actual, expected, delta time.Duration) {
 		t.Fatalf("Actual duration %s does not equal expected %s with delta %s", actual, expected, delta)
 	}
 }
+
+func Test_BoundRoles(t *testing.T) {
+	resource := "//cloudresourcemanager.googleapis.com/projects/project"
+	bindings := ResourceBindings{
+		resource: util.ToSet([]string{"roles/viewer"}),
+	}
+	conditional := toConditionalBindings([]*util.ConditionalBindings{
+		{
+			Condition: util.Condition{Title: "expires", Expression: `request.time < timestamp("2030-01-01T00:00:00Z")`},
+			Bindings: map[string]util.StringSet{
+				resource: util.ToSet([]string{"roles/editor"}),
+			},
+		},
+	})
+
+	if roles := boundRoles(bindings, conditional, resource, nil); !roles.Equals(util.ToSet([]string{"roles/viewer"})) {
+		t.Fatalf("expected unconditional roles, got %v", roles.ToSlice())
+	}
+
+	condition := &iamutil.Condition{Title: "expires", Expression: `request.time < timestamp("2030-01-01T00:00:00Z")`}
+	if roles := boundRoles(bindings, conditional, resource, condition); !roles.Equals(util.ToSet([]string{"roles/editor"})) {
+		t.Fatalf("expected conditional roles, got %v", roles.ToSlice())
+	}
+
+	other := &iamutil.Condition{Title: "other", Expression: "true"}
+	if roles := boundRoles(bindings, conditional, resource, other); len(roles) != 0 {
+		t.Fatalf("expected no roles for unknown condition, got %v", roles.ToSlice())
+	}
+}
+
+func Test_BindingGroupChanges(t *testing.T) {
+	resource := "//cloudresourcemanager.googleapis.com/projects/project"
+	kept := iamutil.Condition{Title: "kept", Expression: "true"}
+	removed := iamutil.Condition{Title: "removed", Expression: "true"}
+	added := iamutil.Condition{Title: "added", Expression: "true"}
+
+	oldConditional := ConditionalBindings{
+		{Condition: kept, Bindings: ResourceBindings{resource: util.ToSet([]string{"roles/a"})}},
+		{Condition: removed, Bindings: ResourceBindings{resource: util.ToSet([]string{"roles/b"})}},
+	}
+	newConditional := ConditionalBindings{
+		{Condition: kept, Bindings: ResourceBindings{resource: util.ToSet([]string{"roles/a", "roles/c"})}},
+		{Condition: added, Bindings: ResourceBindings{resource: util.ToSet([]string{"roles/d"})}},
+	}
+
+	changes := bindingGroupChanges(
+		ResourceBindings{resource: util.ToSet([]string{"roles/viewer"})}, oldConditional,
+		ResourceBindings{resource: util.ToSet([]string{"roles/editor"})}, newConditional)
+	if len(changes) != 4 {
+		t.Fatalf("expected 4 binding group changes, got %d", len(changes))
+	}
+
+	tests := []struct {
+		condition *iamutil.Condition
+		toAdd     []string
+		toRemove  []string
+	}{
+		{condition: nil, toAdd: []string{"roles/editor"}, toRemove: []string{"roles/viewer"}},
+		{condition: &kept, toAdd: []string{"roles/c"}},
+		{condition: &added, toAdd: []string{"roles/d"}},
+		{condition: &removed, toRemove: []string{"roles/b"}},
+	}
+	for i, tt := range tests {
+		change := changes[i]
+		if !change.condition.Equals(tt.condition) {
+			t.Fatalf("change %d: expected condition %v, got %v", i, tt.condition, change.condition)
+		}
+		toAdd := change.newBindings.sub(change.oldBindings)[resource]
+		toRemove := change.oldBindings.sub(change.newBindings)[resource]
+		if len(toAdd) != len(tt.toAdd) || !toAdd.Equals(util.ToSet(tt.toAdd)) ||
+			len(toRemove) != len(tt.toRemove) || !toRemove.Equals(util.ToSet(tt.toRemove)) {
+			t.Fatalf("change %d: expected to add %v and remove %v, got %v and %v", i, tt.toAdd, tt.toRemove, toAdd.ToSlice(), toRemove.ToSlice())
+		}
+	}
+}
+
+func Test_ResourceAliases(t *testing.T) {
+	bindings := ResourceBindings{
+		"gs://my-bucket": util.ToSet([]string{"roles/storage.objectViewer"}),
+		"//cloudresourcemanager.googleapis.com/projects/project": util.ToSet([]string{"roles/viewer"}),
+	}
+	conditional := ConditionalBindings{
+		{
+			Condition: iamutil.Condition{Title: "expires", Expression: `request.time < timestamp("2030-01-01T00:00:00Z")`},
+			Bindings: ResourceBindings{
+				"secret://project/my-secret": util.ToSet([]string{"roles/secretmanager.secretAccessor"}),
+			},
+		},
+	}
+
+	expected := map[string]string{
+		"gs://my-bucket":             "//storage.googleapis.com/b/my-bucket",
+		"secret://project/my-secret": "//secretmanager.googleapis.com/projects/project/secrets/my-secret",
+	}
+	if aliases := resourceAliases(bindings, conditional); !reflect.DeepEqual(aliases, expected) {
+		t.Fatalf("expected resource aliases %v, got %v", expected, aliases)
+	}
+}
+
+func Test_CheckDuplicateAliases(t *testing.T) {
+	cond := iamutil.Condition{Title: "expires", Expression: `request.time < timestamp("2030-01-01T00:00:00Z")`}
+
+	tests := []struct {
+		name        string
+		bindings    ResourceBindings
+		conditional ConditionalBindings
+		wantErr     bool
+	}{
+		{
+			name: "distinct resources",
+			bindings: ResourceBindings{
+				"gs://bucket-a":                       util.ToSet([]string{"roles/storage.objectViewer"}),
+				"//storage.googleapis.com/b/bucket-b": util.ToSet([]string{"roles/storage.objectViewer"}),
+			},
+		},
+		{
+			name: "alias and full resource name",
+			bindings: ResourceBindings{
+				"gs://bucket":                       util.ToSet([]string{"roles/storage.objectViewer"}),
+				"//storage.googleapis.com/b/bucket": util.ToSet([]string{"roles/storage.admin"}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "alias and full resource name in conditional bindings",
+			conditional: ConditionalBindings{{
+				Condition: cond,
+				Bindings: ResourceBindings{
+					"secret://project/my-secret":                                        util.ToSet([]string{"roles/secretmanager.secretAccessor"}),
+					"//secretmanager.googleapis.com/projects/project/secrets/my-secret": util.ToSet([]string{"roles/secretmanager.viewer"}),
+				},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "same resource unconditionally and under a condition",
+			bindings: ResourceBindings{
+				"gs://bucket": util.ToSet([]string{"roles/storage.objectViewer"}),
+			},
+			conditional: ConditionalBindings{{
+				Condition: cond,
+				Bindings: ResourceBindings{
+					"//storage.googleapis.com/b/bucket": util.ToSet([]string{"roles/storage.admin"}),
+				},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkDuplicateAliases(tt.bindings, tt.conditional)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("checkDuplicateAliases() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_StructuredBindingsEmpty(t *testing.T) {
+	// Reads return bindings_structured even without bindings, as an empty list rather than null.
+	entries := structuredBindings(nil, nil)
+	if entries == nil || len(entries) != 0 {
+		t.Fatalf("expected empty non-nil entries, got %#v", entries)
+	}
+}
+
+func Test_PolicyConflicts(t *testing.T) {
+	b, _ := getTestBackend(t)
+
+	// Updates outside of a counting context only log conflicts.
+	b.iamPolicyUpdateOptions(context.Background(), "resource").OnConflict(1, errors.New("conflict"))
+
+	ctx, conflicts := withPolicyConflicts(context.Background())
+	if w := conflicts.warning(); w != "" {
+		t.Fatalf("expected no warning without conflicts, got %q", w)
+	}
+	opts := b.iamPolicyUpdateOptions(ctx, "resource")
+	opts.OnConflict(1, errors.New("conflict"))
+	opts.OnConflict(2, errors.New("conflict"))
+	if w := conflicts.warning(); !strings.Contains(w, "2 time(s)") {
+		t.Fatalf("expected warning for 2 conflicts, got %q", w)
+	}
+}
@@ -4,11 +4,14 @@
 package iamutil
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/hashicorp/errwrap"
@@ -45,6 +48,31 @@ func (h *ApiHandle) DoSetRequest(ctx context.Context, r Resource, data io.Reader
 	return h.doRequest(ctx, req, out)
 }
 
+// DoTestPermissionsRequest calls the testIamPermissions method of the resource. Most
+// resources take the permissions in the request body, but a few use a GET with query
+// parameters instead.
+func (h *ApiHandle) DoTestPermissionsRequest(ctx context.Context, r Resource, permissions []string, out interface{}) error {
+	config := r.GetConfig()
+	method := &config.TestPermissionsMethod
+
+	var data io.Reader
+	if method.HttpMethod != http.MethodGet {
+		body, err := json.Marshal(map[string][]string{"permissions": permissions})
+		if err != nil {
+			return err
+		}
+		data = bytes.NewReader(body)
+	}
+	req, err := constructRequest(r, method, data)
+	if err != nil {
+		return errwrap.Wrapf("Unable to construct TestPermissions request: {{err}}", err)
+	}
+	if method.HttpMethod == http.MethodGet {
+		req.URL.RawQuery = url.Values{"permissions": permissions}.Encode()
+	}
+	return h.doRequest(ctx, req, out)
+}
+
 func (h *ApiHandle) doRequest(ctx context.Context, req *http.Request, out interface{}) error {
 	if req.Header == nil {
 		req.Header = make(http.Header)
@@ -71,12 +99,15 @@ func (h *ApiHandle) doRequest(ctx context.Context, req *http.Request, out interf
 
 func constructRequest(r Resource, restMethod *RestMethod, data io.Reader) (*http.Request, error) {
 	config := r.GetConfig()
-	if data == nil && config != nil && config.Service == "cloudresourcemanager" {
-		// In order to support Resource Manager policies with conditional bindings,
-		// we need to request the policy version of 3. This request parameter is backwards compatible
-		// and will return version 1 policies if they are not yet updated to version 3.
-		requestPolicyVersion3 := `{"options": {"requestedPolicyVersion": 3}}`
-		data = strings.NewReader(requestPolicyVersion3)
+
+	// In order to support policies with conditional bindings, we need to request the policy
+	// version of 3. This request parameter is backwards compatible and will return version 1
+	// policies if they are not yet updated to version 3. GET methods take it as a query
+	// parameter instead of in the request body.
+	requestPolicyVersion3 := data == nil && config != nil && SupportsConditions(r)
+	if requestPolicyVersion3 && (config.Service == "cloudresourcemanager" || restMethod.HttpMethod != http.MethodGet) {
+		data = strings.NewReader(fmt.Sprintf(`{"options": {"requestedPolicyVersion": %d}}`, ConditionalPolicyVersion))
+		requestPolicyVersion3 = false
 	}
 	req, err := http.NewRequest(
 		restMethod.HttpMethod,
@@ -119,5 +150,28 @@ func constructRequest(r Resource, restMethod *RestMethod, data io.Reader) (*http
 	}
 
 	googleapi.Expand(req.URL, replacementMap)
+	if requestPolicyVersion3 {
+		q := req.URL.Query()
+		q.Set(policyVersionQueryParam(config.Service), strconv.Itoa(ConditionalPolicyVersion))
+		req.URL.RawQuery = q.Encode()
+	}
 	return req, nil
 }
+
+// policyVersionQueryParams are the query parameters requesting the policy version of services
+// whose GET getIamPolicy methods don't take options.requestedPolicyVersion. Unknown query
+// parameters are ignored, so these services would otherwise return version 1 policies.
+var policyVersionQueryParams = map[string]string{
+	"compute":           "optionsRequestedPolicyVersion",
+	"deploymentmanager": "optionsRequestedPolicyVersion",
+	"storage":           "optionsRequestedPolicyVersion",
+}
+
+// policyVersionQueryParam returns the query parameter requesting the policy version for GET
+// getIamPolicy methods of the given service.
+func policyVersionQueryParam(service string) string {
+	if param, ok := policyVersionQueryParams[service]; ok {
+		return param
+	}
+	return "options.requestedPolicyVersion"
+}
@@ -21,6 +21,52 @@ type AccessBinding struct {
 	Role         string `json:"role,omitempty"`
 	UserByEmail  string `json:"userByEmail,omitempty"`
 	GroupByEmail string `json:"groupByEmail,omitempty"`
+
+	// unmanaged is the entry as returned by BigQuery if it is anything other than a role
+	// granted to a user or group by email, e.g. a specialGroup, domain, iamMember, authorized
+	// view, routine or dataset entry. It is marshaled back unchanged.
+	unmanaged json.RawMessage
+}
+
+// accessBindingFields are the fields of an access entry that can be represented as an IAM
+// policy binding.
+var accessBindingFields = map[string]bool{
+	"role":         true,
+	"userByEmail":  true,
+	"groupByEmail": true,
+}
+
+func (b *AccessBinding) UnmarshalJSON(data []byte) error {
+	type accessBinding AccessBinding
+	var ab accessBinding
+	if err := json.Unmarshal(data, &ab); err != nil {
+		return err
+	}
+	*b = AccessBinding(ab)
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	managed := b.UserByEmail != "" || b.GroupByEmail != ""
+	for f := range fields {
+		if !accessBindingFields[f] {
+			managed = false
+			break
+		}
+	}
+	if !managed {
+		b.unmanaged = append(json.RawMessage(nil), data...)
+	}
+	return nil
+}
+
+func (b *AccessBinding) MarshalJSON() ([]byte, error) {
+	if b.unmanaged != nil {
+		return b.unmanaged, nil
+	}
+	type accessBinding AccessBinding
+	return json.Marshal((*accessBinding)(b))
 }
 
 type Dataset struct {
@@ -77,15 +123,23 @@ func (r *DatasetResource) SetIamPolicy(ctx context.Context, h *ApiHandle, p *Pol
 	return policy, nil
 }
 
+// TestIamPermissions is not supported. Dataset access is managed through the dataset itself,
+// which has no testIamPermissions method, so unlike tables and routines the datasets override
+// in internal/resource_overrides.go sets no TestPermissionsMethod.
+func (r *DatasetResource) TestIamPermissions(context.Context, *ApiHandle, []string) ([]string, error) {
+	return nil, ErrTestPermissionsNotSupported
+}
+
 func policyAsDataset(p *Policy) (*Dataset, error) {
 	if p == nil {
 		return nil, errors.New("Policy cannot be nil")
 	}
 
 	ds := &Dataset{Etag: p.Etag}
+	ds.Access = append(ds.Access, p.datasetAccess...)
 	for _, binding := range p.Bindings {
 		if binding.Condition != nil {
-			return nil, errors.New("Bigquery Datasets do not support conditional IAM")
+			return nil, fmt.Errorf("BigQuery Datasets do not support conditional IAM (binding for role %q): %w", binding.Role, ErrConditionsNotSupported)
 		}
 		for _, member := range binding.Members {
 			var email, iamType string
@@ -119,6 +173,11 @@ func datasetAsPolicy(ds *Dataset) *Policy {
 	policy := &Policy{Etag: ds.Etag}
 	bindingMap := make(map[string]*Binding)
 	for _, accessBinding := range ds.Access {
+		if accessBinding.unmanaged != nil {
+			policy.datasetAccess = append(policy.datasetAccess, accessBinding)
+			continue
+		}
+
 		var iamMember string
 
 		// Role mapping must be applied for datasets in order to properly
@@ -4,15 +4,21 @@
 package iamutil
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
 	"reflect"
 	"sort"
 	"strings"
 	"testing"
 
 	"github.com/hashicorp/go-gcp-common/gcputil"
+	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/util"
 )
 
 func TestPolicyToDataset(t *testing.T) {
@@ -76,6 +82,12 @@ func TestConditionalDatasetResource(t *testing.T) {
 	if err == nil {
 		t.Fatalf("Datasets do not support conditions, but error was not triggered")
 	}
+	if !errors.Is(err, ErrConditionsNotSupported) {
+		t.Fatalf("expected ErrConditionsNotSupported, got %v", err)
+	}
+	if SupportsConditions(testResource()) {
+		t.Fatalf("expected dataset resource to not support conditions")
+	}
 }
 
 func verifyDatasetResourceWithPolicy(t *testing.T, expectedP *Policy) {
@@ -242,3 +254,158 @@ func testResource() *DatasetResource {
 		},
 	}
 }
+
+// unmanagedAccessEntries are the entries of testdata/dataset_access.json that are not roles
+// granted to a user or group by email, in order.
+var unmanagedAccessEntries = []string{
+	`{"role": "WRITER", "specialGroup": "projectWriters"}`,
+	`{"role": "OWNER", "specialGroup": "projectOwners"}`,
+	`{"role": "READER", "specialGroup": "projectReaders"}`,
+	`{"role": "READER", "domain": "example.com"}`,
+	`{"role": "READER", "iamMember": "allAuthenticatedUsers"}`,
+	`{"role": "roles/bigquery.dataViewer", "userByEmail": "conditional@example.com", "condition": {"title": "expires", "expression": "request.time < timestamp(\"2030-01-01T00:00:00Z\")"}}`,
+	`{"view": {"projectId": "my-project", "datasetId": "reporting", "tableId": "daily_summary"}}`,
+	`{"routine": {"projectId": "my-project", "datasetId": "udfs", "routineId": "mask_email"}}`,
+	`{"dataset": {"dataset": {"projectId": "my-project", "datasetId": "shared_views"}, "targetTypes": ["VIEWS"]}}`,
+}
+
+func TestDatasetAccessRoundTrip(t *testing.T) {
+	var ds Dataset
+	if err := json.Unmarshal(readTestData(t, "dataset_access.json"), &ds); err != nil {
+		t.Fatal(err)
+	}
+
+	p := datasetAsPolicy(&ds)
+	expectedP := &Policy{
+		Etag: "KMSWpcGuVVj2OiOeX7DoSA==",
+		Bindings: []*Binding{
+			{Role: "roles/bigquery.dataOwner", Members: []string{"user:owner@example.com"}},
+			{Role: "roles/bigquery.dataViewer", Members: []string{"group:analysts@example.com"}},
+		},
+	}
+	if p.Etag != expectedP.Etag || !policyEq(&Policy{Bindings: p.Bindings}, &Policy{Bindings: expectedP.Bindings}) {
+		t.Fatalf("expected policy %+v, got %+v", expectedP, p)
+	}
+	if len(p.datasetAccess) != len(unmanagedAccessEntries) {
+		t.Fatalf("expected %d unmanaged access entries, got %d", len(unmanagedAccessEntries), len(p.datasetAccess))
+	}
+
+	_, p = p.RemoveMembers(func(member string) bool { return member == "user:owner@example.com" })
+	actual, err := policyAsDataset(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(actual)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifyAccessEntries(t, data, append(unmanagedAccessEntries,
+		`{"role": "roles/bigquery.dataViewer", "groupByEmail": "analysts@example.com"}`,
+	))
+}
+
+func TestDatasetResource_PreservesAccess(t *testing.T) {
+	payload := readTestData(t, "dataset_access.json")
+	var patched []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/bigquery/v2/projects/my-project/datasets/my_dataset" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		switch r.Method {
+		case http.MethodGet:
+			w.Write(payload)
+		case http.MethodPatch:
+			var err error
+			if patched, err = ioutil.ReadAll(r.Body); err != nil {
+				t.Error(err)
+			}
+			w.Write(patched)
+		default:
+			t.Errorf("unexpected method %q", r.Method)
+		}
+	}))
+	defer srv.Close()
+
+	r := testResource()
+	r.relativeId.IdTuples = map[string]string{"projects": "my-project", "datasets": "my_dataset"}
+	r.config.GetMethod.BaseURL = srv.URL + "/"
+	r.config.SetMethod.BaseURL = srv.URL + "/"
+
+	delta := &PolicyDelta{
+		Roles: util.ToSet([]string{"roles/bigquery.dataViewer"}),
+		Email: "vault-rs@my-project.iam.gserviceaccount.com",
+	}
+	changed, err := UpdatePolicy(context.Background(), GetApiHandle(srv.Client(), ""), r, func(p *Policy) (bool, *Policy) {
+		return p.AddBindings(delta)
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed {
+		t.Fatal("expected dataset access to change")
+	}
+
+	verifyAccessEntries(t, patched, append(unmanagedAccessEntries,
+		`{"role": "roles/bigquery.dataOwner", "userByEmail": "owner@example.com"}`,
+		`{"role": "roles/bigquery.dataViewer", "groupByEmail": "analysts@example.com"}`,
+		`{"role": "roles/bigquery.dataViewer", "userByEmail": "vault-rs@my-project.iam.gserviceaccount.com"}`,
+	))
+}
+
+func readTestData(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// verifyAccessEntries checks that the access list of the dataset JSON has exactly the expected
+// entries. Entries the plugin does not manage must keep their order, the rest may be in any order.
+func verifyAccessEntries(t *testing.T, datasetJson []byte, expected []string) {
+	t.Helper()
+	var actual struct {
+		Access []json.RawMessage `json:"access"`
+	}
+	if err := json.Unmarshal(datasetJson, &actual); err != nil {
+		t.Fatal(err)
+	}
+
+	decode := func(entries []string) []interface{} {
+		decoded := make([]interface{}, 0, len(entries))
+		for _, e := range entries {
+			var v interface{}
+			if err := json.Unmarshal([]byte(e), &v); err != nil {
+				t.Fatal(err)
+			}
+			decoded = append(decoded, v)
+		}
+		return decoded
+	}
+	actualEntries := make([]string, 0, len(actual.Access))
+	for _, e := range actual.Access {
+		actualEntries = append(actualEntries, string(e))
+	}
+
+	actualDecoded, expectedDecoded := decode(actualEntries), decode(expected)
+	if len(actualDecoded) != len(expectedDecoded) {
+		t.Fatalf("expected access entries %v, got %v", expected, actualEntries)
+	}
+	n := len(unmanagedAccessEntries)
+	if !reflect.DeepEqual(actualDecoded[:n], expectedDecoded[:n]) {
+		t.Fatalf("expected unmanaged access entries %v unchanged, got %v", expected[:n], actualEntries[:n])
+	}
+	for _, e := range expectedDecoded[n:] {
+		found := false
+		for _, a := range actualDecoded[n:] {
+			if reflect.DeepEqual(a, e) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Fatalf("expected access entry %v in %v", e, actualEntries)
+		}
+	}
+}
@@ -5,18 +5,26 @@ package iamutil
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/util"
 )
 
 const (
 	ServiceAccountMemberTmpl = "serviceAccount:%s"
+
+	// ConditionalPolicyVersion is the policy version required for policies with conditional bindings.
+	ConditionalPolicyVersion = 3
 )
 
 type Policy struct {
 	Bindings []*Binding `json:"bindings,omitempty"`
 	Etag     string     `json:"etag,omitempty"`
 	Version  int        `json:"version,omitempty"`
+
+	// datasetAccess holds the access entries of a BigQuery dataset that are not roles granted
+	// to a user or group, such as authorized views. They are set back unchanged with the policy.
+	datasetAccess []*AccessBinding
 }
 
 type Binding struct {
@@ -31,9 +39,34 @@ type Condition struct {
 	Expression  string `json:"expression,omitempty"`
 }
 
+// PolicyDelta is a set of roles to bind or unbind for a member. Email is the email of a service
+// account member, unless Member is set to a full member string such as "user:alice@example.com".
+// If Condition is set, the delta only applies to bindings with the same condition.
 type PolicyDelta struct {
-	Roles util.StringSet
-	Email string
+	Roles     util.StringSet
+	Email     string
+	Member    string
+	Condition *Condition
+}
+
+func (d *PolicyDelta) member() string {
+	if d.Member != "" {
+		return d.Member
+	}
+	return fmt.Sprintf(ServiceAccountMemberTmpl, d.Email)
+}
+
+// appliesTo returns whether the delta applies to the given binding.
+func (d *PolicyDelta) appliesTo(bind *Binding) bool {
+	return d.Roles.Includes(bind.Role) && d.Condition.Equals(bind.Condition)
+}
+
+// Equals returns whether two conditions are the same. Nil conditions are only equal to each other.
+func (c *Condition) Equals(other *Condition) bool {
+	if c == nil || other == nil {
+		return c == other
+	}
+	return *c == *other
 }
 
 func (p *Policy) AddBindings(toAdd *PolicyDelta) (changed bool, updated *Policy) {
@@ -51,10 +84,10 @@ func (p *Policy) ChangeBindings(toAdd *PolicyDelta, toRemove *PolicyDelta) (chan
 
 	var toAddMem, toRemoveMem string
 	if toAdd != nil {
-		toAddMem = fmt.Sprintf(ServiceAccountMemberTmpl, toAdd.Email)
+		toAddMem = toAdd.member()
 	}
 	if toRemove != nil {
-		toRemoveMem = fmt.Sprintf(ServiceAccountMemberTmpl, toRemove.Email)
+		toRemoveMem = toRemove.member()
 	}
 
 	changed = false
@@ -66,7 +99,7 @@ func (p *Policy) ChangeBindings(toAdd *PolicyDelta, toRemove *PolicyDelta) (chan
 		memberSet := util.ToSet(bind.Members)
 
 		if toAdd != nil {
-			if toAdd.Roles.Includes(bind.Role) {
+			if toAdd.appliesTo(bind) {
 				changed = true
 				alreadyAdded.Add(bind.Role)
 				memberSet.Add(toAddMem)
@@ -74,7 +107,7 @@ func (p *Policy) ChangeBindings(toAdd *PolicyDelta, toRemove *PolicyDelta) (chan
 		}
 
 		if toRemove != nil {
-			if toRemove.Roles.Includes(bind.Role) {
+			if toRemove.appliesTo(bind) {
 				if memberSet.Includes(toRemoveMem) {
 					changed = true
 					delete(memberSet, toRemoveMem)
@@ -96,19 +129,147 @@ func (p *Policy) ChangeBindings(toAdd *PolicyDelta, toRemove *PolicyDelta) (chan
 			if !alreadyAdded.Includes(r) {
 				changed = true
 				newBindings = append(newBindings, &Binding{
-					Role:    r,
-					Members: []string{toAddMem},
+					Role:      r,
+					Members:   []string{toAddMem},
+					Condition: toAdd.Condition,
 				})
 			}
 		}
 	}
 
 	if changed {
+		version := p.Version
+		if (toAdd != nil && toAdd.Condition != nil) || hasConditions(newBindings) {
+			version = ConditionalPolicyVersion
+		}
 		return true, &Policy{
-			Bindings: newBindings,
-			Etag:     p.Etag,
-			Version:  p.Version,
+			Bindings:      newBindings,
+			Etag:          p.Etag,
+			Version:       version,
+			datasetAccess: p.datasetAccess,
 		}
 	}
 	return false, p
 }
+
+// hasConditions returns whether any of the bindings has a condition. A policy with conditional
+// bindings must be set with version 3, or the request is rejected.
+func hasConditions(bindings []*Binding) bool {
+	for _, bind := range bindings {
+		if bind.Condition != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveMembers removes every member for which remove returns true from all bindings,
+// regardless of role or condition.
+func (p *Policy) RemoveMembers(remove func(member string) bool) (changed bool, updated *Policy) {
+	newBindings := make([]*Binding, 0, len(p.Bindings))
+	for _, bind := range p.Bindings {
+		members := make([]string, 0, len(bind.Members))
+		for _, m := range bind.Members {
+			if remove(m) {
+				changed = true
+				continue
+			}
+			members = append(members, m)
+		}
+		if len(members) > 0 {
+			newBindings = append(newBindings, &Binding{
+				Role:      bind.Role,
+				Members:   members,
+				Condition: bind.Condition,
+			})
+		}
+	}
+
+	if !changed {
+		return false, p
+	}
+	version := p.Version
+	if hasConditions(newBindings) {
+		version = ConditionalPolicyVersion
+	}
+	return true, &Policy{
+		Bindings:      newBindings,
+		Etag:          p.Etag,
+		Version:       version,
+		datasetAccess: p.datasetAccess,
+	}
+}
+
+// BindingDiff is the change in members of a single role and condition between two policies.
+type BindingDiff struct {
+	Role           string
+	Condition      *Condition
+	MembersAdded   []string
+	MembersRemoved []string
+}
+
+// DiffPolicies returns the members added and removed for each role and condition from before to
+// after. Bindings whose members did not change are omitted. The result is sorted by role.
+func DiffPolicies(before, after *Policy) []*BindingDiff {
+	type bindingKey struct {
+		role      string
+		condition Condition
+		hasCond   bool
+	}
+	keyOf := func(bind *Binding) bindingKey {
+		k := bindingKey{role: bind.Role}
+		if bind.Condition != nil {
+			k.condition = *bind.Condition
+			k.hasCond = true
+		}
+		return k
+	}
+
+	var order []bindingKey
+	membersBefore := make(map[bindingKey]util.StringSet)
+	membersAfter := make(map[bindingKey]util.StringSet)
+	collect := func(p *Policy, into map[bindingKey]util.StringSet) {
+		if p == nil {
+			return
+		}
+		for _, bind := range p.Bindings {
+			k := keyOf(bind)
+			_, seenBefore := membersBefore[k]
+			_, seenAfter := membersAfter[k]
+			if !seenBefore && !seenAfter {
+				order = append(order, k)
+			}
+			if _, ok := into[k]; !ok {
+				into[k] = make(util.StringSet)
+			}
+			into[k].Update(bind.Members...)
+		}
+	}
+	collect(before, membersBefore)
+	collect(after, membersAfter)
+
+	var diffs []*BindingDiff
+	for _, k := range order {
+		added := membersAfter[k].Sub(membersBefore[k])
+		removed := membersBefore[k].Sub(membersAfter[k])
+		if len(added) == 0 && len(removed) == 0 {
+			continue
+		}
+		diff := &BindingDiff{
+			Role:           k.role,
+			MembersAdded:   added.ToSlice(),
+			MembersRemoved: removed.ToSlice(),
+		}
+		if k.hasCond {
+			cond := k.condition
+			diff.Condition = &cond
+		}
+		sort.Strings(diff.MembersAdded)
+		sort.Strings(diff.MembersRemoved)
+		diffs = append(diffs, diff)
+	}
+	sort.SliceStable(diffs, func(i, j int) bool {
+		return diffs[i].Role < diffs[j].Role
+	})
+	return diffs
+}
@@ -4,6 +4,7 @@
 package iamutil
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/util"
@@ -77,3 +78,174 @@ func TestConditionsAfterIamPolicyUpdate(t *testing.T) {
 		t.Fatalf("number of conditions changed after removing bindings: before - %v now - %v", conditions_before, conditions_after)
 	}
 }
+
+func TestChangeBindingsWithMemberAndCondition(t *testing.T) {
+	cond := &Condition{
+		Title:      "expiring",
+		Expression: `request.time < timestamp("2030-01-01T00:00:00Z")`,
+	}
+	p := &Policy{
+		Version: 1,
+		Bindings: []*Binding{
+			{
+				Members: []string{"user:other@example.com"},
+				Role:    "roles/arole",
+			},
+		},
+	}
+
+	d := &PolicyDelta{
+		Roles:     util.ToSet([]string{"roles/arole"}),
+		Member:    "user:oncall@example.com",
+		Condition: cond,
+	}
+
+	changed, np := p.AddBindings(d)
+	if !changed {
+		t.Fatal("expected policy to change")
+	}
+	if np.Version != ConditionalPolicyVersion {
+		t.Fatalf("expected policy version %d, got %d", ConditionalPolicyVersion, np.Version)
+	}
+	if len(np.Bindings) != 2 {
+		t.Fatalf("expected conditional binding to be added separately, got %d bindings", len(np.Bindings))
+	}
+	for _, b := range np.Bindings {
+		members := util.ToSet(b.Members)
+		if b.Condition == nil && members.Includes(d.Member) {
+			t.Fatal("member was added to unconditional binding")
+		}
+		if b.Condition != nil && !(b.Condition.Equals(cond) && members.Includes(d.Member)) {
+			t.Fatalf("unexpected conditional binding %+v", b)
+		}
+	}
+
+	// Removing with a different condition should not touch the binding.
+	changed, _ = np.RemoveBindings(&PolicyDelta{
+		Roles:     d.Roles,
+		Member:    d.Member,
+		Condition: &Condition{Title: "other", Expression: "true"},
+	})
+	if changed {
+		t.Fatal("expected no change when removing binding with a different condition")
+	}
+
+	changed, np = np.RemoveBindings(d)
+	if !changed {
+		t.Fatal("expected policy to change")
+	}
+	if len(np.Bindings) != 1 || np.Bindings[0].Condition != nil {
+		t.Fatalf("expected only the unconditional binding to remain, got %+v", np.Bindings)
+	}
+}
+
+func TestDiffPolicies(t *testing.T) {
+	cond := &Condition{Title: "expires", Expression: "true"}
+	before := &Policy{
+		Bindings: []*Binding{
+			{Role: "roles/viewer", Members: []string{"user:a@example.com", "serviceAccount:old@example.com"}},
+			{Role: "roles/editor", Members: []string{"user:a@example.com"}},
+		},
+	}
+
+	_, after := before.ChangeBindings(
+		&PolicyDelta{Roles: util.ToSet([]string{"roles/viewer", "roles/editor"}), Email: "new@example.com", Condition: cond},
+		&PolicyDelta{Roles: util.ToSet([]string{"roles/viewer"}), Email: "old@example.com"},
+	)
+
+	diffs := DiffPolicies(before, after)
+	if len(diffs) != 3 {
+		t.Fatalf("expected 3 binding diffs, got %d", len(diffs))
+	}
+
+	expected := []BindingDiff{
+		{Role: "roles/editor", Condition: cond, MembersAdded: []string{"serviceAccount:new@example.com"}, MembersRemoved: []string{}},
+		{Role: "roles/viewer", MembersAdded: []string{}, MembersRemoved: []string{"serviceAccount:old@example.com"}},
+		{Role: "roles/viewer", Condition: cond, MembersAdded: []string{"serviceAccount:new@example.com"}, MembersRemoved: []string{}},
+	}
+	for i, e := range expected {
+		d := diffs[i]
+		if d.Role != e.Role || !d.Condition.Equals(e.Condition) ||
+			!util.ToSet(d.MembersAdded).Equals(util.ToSet(e.MembersAdded)) ||
+			!util.ToSet(d.MembersRemoved).Equals(util.ToSet(e.MembersRemoved)) {
+			t.Fatalf("diff %d: expected %+v, got %+v", i, e, *d)
+		}
+	}
+
+	if diffs := DiffPolicies(before, before); len(diffs) != 0 {
+		t.Fatalf("expected no diffs for unchanged policy, got %d", len(diffs))
+	}
+}
+
+func TestRemoveMembers(t *testing.T) {
+	cond := &Condition{Title: "expires", Expression: "true"}
+	p := &Policy{
+		Bindings: []*Binding{
+			{Role: "roles/viewer", Members: []string{"user:a@example.com", "deleted:serviceAccount:old@example.com?uid=1"}},
+			{Role: "roles/editor", Members: []string{"deleted:serviceAccount:old@example.com?uid=1"}, Condition: cond},
+		},
+		Etag:    "etag",
+		Version: ConditionalPolicyVersion,
+	}
+	isDeleted := func(m string) bool {
+		return strings.HasPrefix(m, "deleted:")
+	}
+
+	changed, updated := p.RemoveMembers(isDeleted)
+	if !changed {
+		t.Fatal("expected policy to change")
+	}
+	if len(updated.Bindings) != 1 || updated.Bindings[0].Role != "roles/viewer" ||
+		len(updated.Bindings[0].Members) != 1 || updated.Bindings[0].Members[0] != "user:a@example.com" {
+		t.Fatalf("unexpected bindings after removing members: %+v", updated.Bindings)
+	}
+	if updated.Etag != p.Etag || updated.Version != p.Version {
+		t.Fatalf("expected etag and version to be kept, got %q and %d", updated.Etag, updated.Version)
+	}
+
+	if changed, _ := updated.RemoveMembers(isDeleted); changed {
+		t.Fatal("expected no change when no members match")
+	}
+}
+
+func TestRemoveBindingsKeepsConditionalPolicyVersion(t *testing.T) {
+	cond := &Condition{
+		Title:      "expiring",
+		Expression: `request.time < timestamp("2030-01-01T00:00:00Z")`,
+	}
+	p := &Policy{
+		Version: 1,
+		Bindings: []*Binding{
+			{
+				Members: []string{"serviceAccount:sa@project.iam.gserviceaccount.com"},
+				Role:    "roles/arole",
+			},
+			{
+				Members:   []string{"user:other@example.com"},
+				Role:      "roles/arole",
+				Condition: cond,
+			},
+		},
+	}
+
+	changed, np := p.RemoveBindings(&PolicyDelta{
+		Roles: util.ToSet([]string{"roles/arole"}),
+		Email: "sa@project.iam.gserviceaccount.com",
+	})
+	if !changed {
+		t.Fatal("expected policy to change")
+	}
+	if np.Version != ConditionalPolicyVersion {
+		t.Fatalf("expected policy with remaining conditional bindings to have version %d, got %d", ConditionalPolicyVersion, np.Version)
+	}
+
+	changed, np = p.RemoveMembers(func(member string) bool {
+		return member == "serviceAccount:sa@project.iam.gserviceaccount.com"
+	})
+	if !changed {
+		t.Fatal("expected policy to change")
+	}
+	if np.Version != ConditionalPolicyVersion {
+		t.Fatalf("expected policy with remaining conditional bindings to have version %d, got %d", ConditionalPolicyVersion, np.Version)
+	}
+}
@@ -51,3 +51,15 @@ func (r *IamResource) SetIamPolicy(ctx context.Context, h *ApiHandle, p *Policy)
 	}
 	return &policy, nil
 }
+
+func (r *IamResource) TestIamPermissions(ctx context.Context, h *ApiHandle, permissions []string) ([]string, error) {
+	if r.config.TestPermissionsMethod.Path == "" {
+		return nil, ErrTestPermissionsNotSupported
+	}
+
+	var resp testPermissionsResponse
+	if err := h.DoTestPermissionsRequest(ctx, r, permissions, &resp); err != nil {
+		return nil, errwrap.Wrapf("unable to test permissions: {{err}}", err)
+	}
+	return resp.Permissions, nil
+}
@@ -55,8 +55,8 @@ func TestIamResource(t *testing.T) {
 		t.Fatalf("Could not construct GetIamPolicyRequest: %v", err)
 	}
 	expectedURLBase := "https://agcpservice.googleapis.com/v1/f/foo1/b/bar2"
-	if getR.URL.String() != expectedURLBase+":getIamPolicy" {
-		t.Fatalf("expected get request URL %s, got %s", expectedURLBase+":getIamPolicy", getR.URL.String())
+	if getR.URL.String() != expectedURLBase+":getIamPolicy?options.requestedPolicyVersion=3" {
+		t.Fatalf("expected get request URL %s, got %s", expectedURLBase+":getIamPolicy?options.requestedPolicyVersion=3", getR.URL.String())
 	}
 	if getR.Method != "GET" {
 		t.Fatalf("expected get request method %s, got %s", "GET", getR.Method)
@@ -320,3 +320,37 @@ func TestConditionalIamResource(t *testing.T) {
 		}
 	}
 }
+
+func TestIamResourcePolicyVersionQueryParam(t *testing.T) {
+	tests := []struct {
+		name        string
+		resource    string
+		expectedURL string
+	}{
+		{
+			name:        "storage bucket",
+			resource:    "//storage.googleapis.com/b/my-bucket",
+			expectedURL: "https://storage.googleapis.com/storage/v1/b/my-bucket/iam?optionsRequestedPolicyVersion=3",
+		},
+		{
+			name:        "compute instance",
+			resource:    "https://compute.googleapis.com/compute/v1/projects/my-project/zones/us-central1-a/instances/my-instance",
+			expectedURL: "https://compute.googleapis.com/compute/v1/projects/my-project/zones/us-central1-a/instances/my-instance/getIamPolicy?optionsRequestedPolicyVersion=3",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := GetEnabledResources().Parse(tt.resource)
+			if err != nil {
+				t.Fatal(err)
+			}
+			getR, err := constructRequest(r, &r.GetConfig().GetMethod, nil)
+			if err != nil {
+				t.Fatalf("Could not construct GetIamPolicyRequest: %v", err)
+			}
+			if getR.URL.String() != tt.expectedURL {
+				t.Fatalf("expected get request URL %s, got %s", tt.expectedURL, getR.URL.String())
+			}
+		})
+	}
+}
@@ -73,6 +73,7 @@ func checkResource(name string, fullPath string, resource *discovery.RestResourc
 
 	getM, hasGet := resource.Methods["getIamPolicy"]
 	setM, hasSet := resource.Methods["setIamPolicy"]
+	testM, hasTest := resource.Methods["testIamPermissions"]
 
 	if !hasGet || !hasSet {
 		// Can't manage anything without both setIamPolicy and getIamPolicy
@@ -129,6 +130,15 @@ func checkResource(name string, fullPath string, resource *discovery.RestResourc
 		Parameters:                getM.ParameterOrder,
 		CollectionReplacementKeys: replacementMap,
 	}
+	if hasTest {
+		// testIamPermissions is optional; without it, permissions are not checked before
+		// changing the resource's policy.
+		r.TestPermissionsMethod = iamutil.RestMethod{
+			HttpMethod: testM.HttpMethod,
+			BaseURL:    doc.RootUrl + doc.ServicePath,
+			Path:       testM.Path,
+		}
+	}
 
 	addToConfig(typeKey, doc.Name, doc.Version, r, config)
 	if saneKey, ok := sanizitedTypeKeys[typeKey]; ok {
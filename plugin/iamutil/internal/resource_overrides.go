@@ -8,6 +8,8 @@ import (
 )
 
 var resourceOverrides = map[string]map[string]map[string]iamutil.RestResource{
+	// Dataset access is read and written as part of the dataset, which has no
+	// testIamPermissions method, so no TestPermissionsMethod is set.
 	"projects/datasets": {
 		"bigquery": {
 			"v2": iamutil.RestResource{
@@ -57,6 +59,11 @@ var resourceOverrides = map[string]map[string]map[string]iamutil.RestResource{
 					Path:          "bigquery/v2/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: iamutil.RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://bigquery.googleapis.com",
+					Path:       "bigquery/v2/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -83,6 +90,11 @@ var resourceOverrides = map[string]map[string]map[string]iamutil.RestResource{
 					Path:          "bigquery/v2/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: iamutil.RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://bigquery.googleapis.com",
+					Path:       "bigquery/v2/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
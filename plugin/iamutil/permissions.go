@@ -0,0 +1,81 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package iamutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/util"
+)
+
+// ErrTestPermissionsNotSupported is returned when testing permissions on a resource that
+// has no testIamPermissions method.
+var ErrTestPermissionsNotSupported = errors.New("resource does not support testing IAM permissions")
+
+// permissionServices maps the API service names whose IAM permissions use a different
+// service prefix.
+var permissionServices = map[string]string{
+	"cloudresourcemanager": "resourcemanager",
+	"bigtableadmin":        "bigtable",
+}
+
+// permissionCollections maps the "<service>/<collection>" resource types whose IAM permissions
+// use a different collection name.
+var permissionCollections = map[string]string{
+	"compute/regionDisks": "disks",
+}
+
+// policyPermissionOverrides maps the "<service>/<collection>" resource types whose policies are
+// not managed with getIamPolicy and setIamPolicy to the permissions they require.
+var policyPermissionOverrides = map[string][]string{
+	// BigQuery dataset access is read and written as part of the dataset.
+	"bigquery/datasets": {"bigquery.datasets.get", "bigquery.datasets.update"},
+}
+
+type testPermissionsResponse struct {
+	Permissions []string `json:"permissions"`
+}
+
+// PolicyPermissions returns the permissions required to get and set the IAM policy of the
+// resource, for example "storage.buckets.getIamPolicy".
+func PolicyPermissions(r Resource) []string {
+	config := r.GetConfig()
+	typeKey := fmt.Sprintf("%s/%s", config.Service, config.Name)
+	if permissions, ok := policyPermissionOverrides[typeKey]; ok {
+		return append([]string{}, permissions...)
+	}
+
+	service := config.Service
+	if s, ok := permissionServices[service]; ok {
+		service = s
+	}
+	collection := config.Name
+	if c, ok := permissionCollections[typeKey]; ok {
+		collection = c
+	}
+	return []string{
+		fmt.Sprintf("%s.%s.getIamPolicy", service, collection),
+		fmt.Sprintf("%s.%s.setIamPolicy", service, collection),
+	}
+}
+
+// MissingPermissions returns which of the given permissions the caller does not have on the
+// resource.
+func MissingPermissions(ctx context.Context, h *ApiHandle, r Resource, permissions []string) ([]string, error) {
+	granted, err := r.TestIamPermissions(ctx, h, permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	grantedSet := util.ToSet(granted)
+	var missing []string
+	for _, p := range permissions {
+		if !grantedSet.Includes(p) {
+			missing = append(missing, p)
+		}
+	}
+	return missing, nil
+}
@@ -0,0 +1,110 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package iamutil
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/go-gcp-common/gcputil"
+)
+
+func testPermissionsResource(baseURL, httpMethod, path string) *IamResource {
+	return &IamResource{
+		relativeId: &gcputil.RelativeResourceName{
+			Name:                 "buckets",
+			TypeKey:              "b",
+			IdTuples:             map[string]string{"b": "my-bucket"},
+			OrderedCollectionIds: []string{"b"},
+		},
+		config: &RestResource{
+			Name:       "buckets",
+			TypeKey:    "b",
+			Service:    "storage",
+			Parameters: []string{"bucket"},
+			CollectionReplacementKeys: map[string]string{
+				"b": "bucket",
+			},
+			TestPermissionsMethod: RestMethod{
+				HttpMethod: httpMethod,
+				BaseURL:    baseURL,
+				Path:       path,
+			},
+		},
+	}
+}
+
+func TestMissingPermissions(t *testing.T) {
+	permissions := []string{"storage.buckets.getIamPolicy", "storage.buckets.setIamPolicy"}
+
+	for _, httpMethod := range []string{http.MethodGet, http.MethodPost} {
+		t.Run(httpMethod, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/b/my-bucket/iam/testPermissions" {
+					t.Errorf("unexpected path %q", r.URL.Path)
+				}
+
+				var requested []string
+				if r.Method == http.MethodGet {
+					requested = r.URL.Query()["permissions"]
+				} else {
+					var body testPermissionsResponse
+					if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+						t.Error(err)
+					}
+					requested = body.Permissions
+				}
+				if !reflect.DeepEqual(requested, permissions) {
+					t.Errorf("expected permissions %v to be tested, got %v", permissions, requested)
+				}
+
+				// Only the first permission is granted.
+				json.NewEncoder(w).Encode(&testPermissionsResponse{Permissions: requested[:1]})
+			}))
+			defer srv.Close()
+
+			r := testPermissionsResource(srv.URL+"/", httpMethod, "b/{bucket}/iam/testPermissions")
+			missing, err := MissingPermissions(context.Background(), GetApiHandle(srv.Client(), ""), r, permissions)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(missing, permissions[1:]) {
+				t.Fatalf("expected missing permissions %v, got %v", permissions[1:], missing)
+			}
+		})
+	}
+}
+
+func TestMissingPermissions_NotSupported(t *testing.T) {
+	r := testPermissionsResource("", "", "")
+	if _, err := MissingPermissions(context.Background(), nil, r, []string{"storage.buckets.getIamPolicy"}); !errors.Is(err, ErrTestPermissionsNotSupported) {
+		t.Fatalf("expected ErrTestPermissionsNotSupported, got %v", err)
+	}
+}
+
+func TestPolicyPermissions(t *testing.T) {
+	tests := map[string][]string{
+		"//storage.googleapis.com/b/my-bucket":                                                           {"storage.buckets.getIamPolicy", "storage.buckets.setIamPolicy"},
+		"//cloudresourcemanager.googleapis.com/projects/my-project":                                      {"resourcemanager.projects.getIamPolicy", "resourcemanager.projects.setIamPolicy"},
+		"//compute.googleapis.com/projects/my-project/regions/us-central1/disks/my-disk":                 {"compute.disks.getIamPolicy", "compute.disks.setIamPolicy"},
+		"//bigquery.googleapis.com/projects/my-project/datasets/my_dataset":                              {"bigquery.datasets.get", "bigquery.datasets.update"},
+		"//bigquery.googleapis.com/projects/my-project/datasets/my_dataset/tables/my_table":              {"bigquery.tables.getIamPolicy", "bigquery.tables.setIamPolicy"},
+		"//iam.googleapis.com/projects/my-project/serviceAccounts/sa@my-project.iam.gserviceaccount.com": {"iam.serviceAccounts.getIamPolicy", "iam.serviceAccounts.setIamPolicy"},
+	}
+	parser := GetEnabledResources()
+	for name, expected := range tests {
+		r, err := parser.Parse(name)
+		if err != nil {
+			t.Fatalf("unable to parse %q: %v", name, err)
+		}
+		if actual := PolicyPermissions(r); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("expected %v for resource %q, got %v", expected, name, actual)
+		}
+	}
+}
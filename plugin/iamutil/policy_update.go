@@ -0,0 +1,160 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package iamutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+const (
+	// DefaultPolicyUpdateAttempts is the default number of times a read-modify-write of an IAM
+	// policy is attempted before an etag conflict is returned.
+	DefaultPolicyUpdateAttempts = 5
+
+	// DefaultPolicyUpdateConcurrency is the default number of resources whose IAM policies are
+	// updated at the same time.
+	DefaultPolicyUpdateConcurrency = 8
+
+	defaultPolicyUpdateInitialBackoff = 500 * time.Millisecond
+	defaultPolicyUpdateMaxBackoff     = 8 * time.Second
+)
+
+// PolicyChangeFunc returns the policy to set given the current policy of a resource, and whether
+// it differs from the current policy.
+type PolicyChangeFunc func(p *Policy) (changed bool, updated *Policy)
+
+// PolicyUpdateOptions configures the etag conflict retries of UpdatePolicy. The zero value uses
+// the defaults.
+type PolicyUpdateOptions struct {
+	// MaxAttempts is the maximum number of read-modify-write attempts.
+	MaxAttempts int
+
+	// InitialBackoff and MaxBackoff bound the delay between attempts, which doubles after each
+	// conflict and has jitter added.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	// OnConflict, if set, is called with the attempt number and error of each etag conflict.
+	OnConflict func(attempt int, err error)
+}
+
+// IsEtagConflictErr returns whether err is GCP rejecting an IAM policy update because the policy
+// changed since it was read.
+func IsEtagConflictErr(err error) bool {
+	var gErr *googleapi.Error
+	if !errors.As(err, &gErr) {
+		return false
+	}
+	return gErr.Code == http.StatusConflict || gErr.Code == http.StatusPreconditionFailed
+}
+
+// UpdatePolicy reads the IAM policy of r, applies change and sets the result, using the policy
+// etag so concurrent changes are not overwritten. If another change to the policy wins, the
+// read-modify-write is retried with backoff. It returns whether the policy was changed.
+func UpdatePolicy(ctx context.Context, h *ApiHandle, r Resource, change PolicyChangeFunc, opts *PolicyUpdateOptions) (bool, error) {
+	if opts == nil {
+		opts = &PolicyUpdateOptions{}
+	}
+	maxAttempts := opts.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultPolicyUpdateAttempts
+	}
+	backoff := opts.InitialBackoff
+	if backoff <= 0 {
+		backoff = defaultPolicyUpdateInitialBackoff
+	}
+	maxBackoff := opts.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultPolicyUpdateMaxBackoff
+	}
+
+	for attempt := 1; ; attempt++ {
+		p, err := r.GetIamPolicy(ctx, h)
+		if err != nil {
+			return false, err
+		}
+
+		changed, newP := change(p)
+		if !changed || newP == nil {
+			return false, nil
+		}
+
+		_, err = r.SetIamPolicy(ctx, h, newP)
+		if err == nil {
+			return true, nil
+		}
+		if !IsEtagConflictErr(err) {
+			return false, err
+		}
+		if opts.OnConflict != nil {
+			opts.OnConflict(attempt, err)
+		}
+		if attempt >= maxAttempts {
+			return false, fmt.Errorf("IAM policy changed concurrently %d times: %w", attempt, err)
+		}
+
+		delay := backoff + time.Duration(rand.Int63n(int64(backoff)))
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// ForEachConcurrently calls f for each of the given names, running at most concurrency calls at
+// the same time. It returns the errors of the calls keyed by name. If ctx is done before a call
+// starts, the call is skipped and ctx.Err() is returned for its name.
+func ForEachConcurrently(ctx context.Context, concurrency int, names []string, f func(ctx context.Context, name string) error) map[string]error {
+	if concurrency <= 0 {
+		concurrency = DefaultPolicyUpdateConcurrency
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs = make(map[string]error)
+		sem  = make(chan struct{}, concurrency)
+	)
+	for i, name := range names {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if err := ctx.Err(); err != nil {
+			mu.Lock()
+			for _, skipped := range names[i:] {
+				errs[skipped] = err
+			}
+			mu.Unlock()
+			break
+		}
+		wg.Add(1)
+		go func(name string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := f(ctx, name); err != nil {
+				mu.Lock()
+				errs[name] = err
+				mu.Unlock()
+			}
+		}(name)
+	}
+	wg.Wait()
+	return errs
+}
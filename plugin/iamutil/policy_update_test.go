@@ -0,0 +1,164 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package iamutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/go-gcp-common/gcputil"
+	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/util"
+	"google.golang.org/api/googleapi"
+)
+
+// conflictingResource is an in-memory resource whose first conflicts SetIamPolicy calls fail
+// with an etag conflict, as if another client changed the policy each time.
+type conflictingResource struct {
+	mu        sync.Mutex
+	policy    *Policy
+	conflicts int
+	sets      int
+}
+
+func (r *conflictingResource) GetIamPolicy(context.Context, *ApiHandle) (*Policy, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.policy, nil
+}
+
+func (r *conflictingResource) SetIamPolicy(_ context.Context, _ *ApiHandle, p *Policy) (*Policy, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sets++
+	if r.sets <= r.conflicts {
+		return nil, errwrap.Wrapf("unable to set policy: {{err}}", &googleapi.Error{Code: http.StatusConflict})
+	}
+	r.policy = p
+	return p, nil
+}
+
+func (r *conflictingResource) TestIamPermissions(context.Context, *ApiHandle, []string) ([]string, error) {
+	return nil, ErrTestPermissionsNotSupported
+}
+
+func (r *conflictingResource) GetConfig() *RestResource { return nil }
+
+func (r *conflictingResource) GetRelativeId() *gcputil.RelativeResourceName { return nil }
+
+func TestUpdatePolicy_RetriesConflicts(t *testing.T) {
+	delta := &PolicyDelta{Roles: util.ToSet([]string{"roles/viewer"}), Email: "sa@project.iam.gserviceaccount.com"}
+	opts := func(conflicts *int) *PolicyUpdateOptions {
+		return &PolicyUpdateOptions{
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+			OnConflict:     func(int, error) { *conflicts++ },
+		}
+	}
+
+	r := &conflictingResource{policy: &Policy{Etag: "atag"}, conflicts: 2}
+	var conflicts int
+	changed, err := UpdatePolicy(context.Background(), nil, r, func(p *Policy) (bool, *Policy) {
+		return p.AddBindings(delta)
+	}, opts(&conflicts))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed || conflicts != 2 || len(r.policy.Bindings) != 1 {
+		t.Fatalf("expected policy to change after 2 conflicts, got changed=%v conflicts=%d policy=%+v", changed, conflicts, r.policy)
+	}
+
+	r = &conflictingResource{policy: &Policy{Etag: "atag"}, conflicts: 3}
+	conflicts = 0
+	_, err = UpdatePolicy(context.Background(), nil, r, func(p *Policy) (bool, *Policy) {
+		return p.AddBindings(delta)
+	}, opts(&conflicts))
+	if !IsEtagConflictErr(err) {
+		t.Fatalf("expected etag conflict error after max attempts, got %v", err)
+	}
+	if conflicts != 3 {
+		t.Fatalf("expected 3 conflicts, got %d", conflicts)
+	}
+}
+
+func TestUpdatePolicy_NoChange(t *testing.T) {
+	r := &conflictingResource{policy: &Policy{Etag: "atag"}}
+	changed, err := UpdatePolicy(context.Background(), nil, r, func(p *Policy) (bool, *Policy) {
+		return false, p
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed || r.sets != 0 {
+		t.Fatalf("expected no policy update, got changed=%v sets=%d", changed, r.sets)
+	}
+}
+
+func TestIsEtagConflictErr(t *testing.T) {
+	tests := map[error]bool{
+		&googleapi.Error{Code: http.StatusConflict}:            true,
+		&googleapi.Error{Code: http.StatusPreconditionFailed}:  true,
+		&googleapi.Error{Code: http.StatusForbidden}:           false,
+		fmt.Errorf("wrapped: %w", &googleapi.Error{Code: 412}): true,
+		errors.New("some error"):                               false,
+	}
+	for err, expected := range tests {
+		if actual := IsEtagConflictErr(err); actual != expected {
+			t.Errorf("IsEtagConflictErr(%v) = %v, expected %v", err, actual, expected)
+		}
+	}
+}
+
+func TestForEachConcurrently(t *testing.T) {
+	names := []string{"a", "b", "c", "d", "e", "f"}
+
+	var running, maxRunning int32
+	errs := ForEachConcurrently(context.Background(), 2, names, func(_ context.Context, name string) error {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		if name == "c" {
+			return errors.New("failed")
+		}
+		return nil
+	})
+
+	if maxRunning > 2 {
+		t.Fatalf("expected at most 2 concurrent calls, got %d", maxRunning)
+	}
+	if len(errs) != 1 || errs["c"] == nil {
+		t.Fatalf("expected only c to fail, got %v", errs)
+	}
+}
+
+func TestForEachConcurrently_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var called int32
+	errs := ForEachConcurrently(ctx, 1, []string{"a", "b", "c"}, func(_ context.Context, name string) error {
+		atomic.AddInt32(&called, 1)
+		cancel()
+		return nil
+	})
+
+	if called != 1 {
+		t.Fatalf("expected only the first call to run, got %d calls", called)
+	}
+	if len(errs) != 2 || !errors.Is(errs["b"], context.Canceled) || !errors.Is(errs["c"], context.Canceled) {
+		t.Fatalf("expected b and c to be skipped as cancelled, got %v", errs)
+	}
+}
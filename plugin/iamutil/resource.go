@@ -5,19 +5,32 @@ package iamutil
 
 import (
 	"context"
+	"errors"
 
 	"github.com/hashicorp/go-gcp-common/gcputil"
 )
 
+// ErrConditionsNotSupported is returned when setting a policy with conditional bindings
+// on a resource that does not support IAM Conditions.
+var ErrConditionsNotSupported = errors.New("resource does not support conditional IAM bindings")
+
 // Resource handles constructing HTTP requests for getting and
 // setting IAM policies.
 type Resource interface {
 	GetIamPolicy(context.Context, *ApiHandle) (*Policy, error)
 	SetIamPolicy(context.Context, *ApiHandle, *Policy) (*Policy, error)
+	TestIamPermissions(context.Context, *ApiHandle, []string) ([]string, error)
 	GetConfig() *RestResource
 	GetRelativeId() *gcputil.RelativeResourceName
 }
 
+// SupportsConditions returns whether bindings on the resource can have IAM Conditions.
+// BigQuery datasets use an access list instead of an IAM policy and do not.
+func SupportsConditions(r Resource) bool {
+	_, isDataset := r.(*DatasetResource)
+	return !isDataset
+}
+
 type RestResource struct {
 	// Name is the base name of the resource
 	// i.e. for a GCE instance: "instance"
@@ -40,6 +53,10 @@ type RestResource struct {
 	// HTTP metadata for setting Policy data in GCP
 	SetMethod RestMethod
 
+	// HTTP metadata for testing the caller's permissions on the resource in GCP.
+	// Empty if the resource type has no testIamPermissions method.
+	TestPermissionsMethod RestMethod
+
 	// Ordered parameters to be replaced in method paths
 	Parameters []string
 
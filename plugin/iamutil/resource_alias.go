@@ -0,0 +1,148 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package iamutil
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const resourceAliasErrorTmpl = `invalid resource alias "%s": expected format %s`
+
+// resourceAlias rewrites a short resource URI, with the scheme removed, to a full resource name.
+type resourceAlias struct {
+	format  string
+	resolve func(path string) (string, bool)
+}
+
+// resourceAliases are the short resource URIs accepted in place of resource names, by scheme.
+var resourceAliases = map[string]resourceAlias{
+	"gs": {
+		format:  "gs://bucket or gs://bucket/managed/folder/",
+		resolve: resolveStorageAlias,
+	},
+	"bq": {
+		format:  "bq://project.dataset or bq://project.dataset.table",
+		resolve: resolveBigQueryAlias,
+	},
+	"pubsub": {
+		format: "pubsub://project/collection/name, e.g. pubsub://project/topics/name",
+		resolve: func(path string) (string, bool) {
+			parts, ok := splitAliasPath(path, 3, 3)
+			if !ok {
+				return "", false
+			}
+			return fmt.Sprintf("//pubsub.googleapis.com/projects/%s/%s/%s", parts[0], parts[1], parts[2]), true
+		},
+	},
+	"ar": {
+		format: "ar://location/project/repository",
+		resolve: func(path string) (string, bool) {
+			parts, ok := splitAliasPath(path, 3, 3)
+			if !ok {
+				return "", false
+			}
+			return fmt.Sprintf("//artifactregistry.googleapis.com/projects/%s/locations/%s/repositories/%s", parts[1], parts[0], parts[2]), true
+		},
+	},
+	"secret": {
+		format: "secret://project/name",
+		resolve: func(path string) (string, bool) {
+			parts, ok := splitAliasPath(path, 2, 2)
+			if !ok {
+				return "", false
+			}
+			return fmt.Sprintf("//secretmanager.googleapis.com/projects/%s/secrets/%s", parts[0], parts[1]), true
+		},
+	},
+	"kms": {
+		format: "kms://project/location/key_ring or kms://project/location/key_ring/crypto_key",
+		resolve: func(path string) (string, bool) {
+			parts, ok := splitAliasPath(path, 3, 4)
+			if !ok {
+				return "", false
+			}
+			name := fmt.Sprintf("//cloudkms.googleapis.com/projects/%s/locations/%s/keyRings/%s", parts[0], parts[1], parts[2])
+			if len(parts) == 4 {
+				name += "/cryptoKeys/" + parts[3]
+			}
+			return name, true
+		},
+	},
+}
+
+// ResolveResourceAlias returns the full resource name for a short resource URI such as
+// "gs://my-bucket". If rawName does not use an alias scheme, it is returned unchanged and
+// isAlias is false.
+func ResolveResourceAlias(rawName string) (name string, isAlias bool, err error) {
+	scheme, path, ok := strings.Cut(rawName, "://")
+	if !ok {
+		return rawName, false, nil
+	}
+	alias, ok := resourceAliases[scheme]
+	if !ok {
+		return rawName, false, nil
+	}
+
+	name, ok = alias.resolve(path)
+	if !ok {
+		return "", true, fmt.Errorf(resourceAliasErrorTmpl, rawName, alias.format)
+	}
+	return name, true, nil
+}
+
+// resolveStorageAlias resolves a bucket, or a managed folder if there is a path after the
+// bucket name. Managed folder names keep their trailing slash and are escaped in the full
+// resource name so they are parsed as a single ID.
+func resolveStorageAlias(path string) (string, bool) {
+	bucket, folder, _ := strings.Cut(path, "/")
+	if bucket == "" {
+		return "", false
+	}
+	if folder == "" {
+		return fmt.Sprintf("//storage.googleapis.com/b/%s", bucket), true
+	}
+	return fmt.Sprintf("//storage.googleapis.com/b/%s/managedFolders/%s", bucket, url.PathEscape(folder)), true
+}
+
+// resolveBigQueryAlias resolves a dataset or table. Domain-scoped project IDs such as
+// "example.com:project" can contain dots, so the dataset starts at the first dot after the colon.
+func resolveBigQueryAlias(path string) (string, bool) {
+	start := strings.Index(path, ":") + 1
+	sep := strings.Index(path[start:], ".")
+	if sep < 0 {
+		return "", false
+	}
+	project := path[:start+sep]
+	ids := strings.Split(path[start+sep+1:], ".")
+	if project == "" || len(ids) > 2 {
+		return "", false
+	}
+	for _, id := range ids {
+		if id == "" {
+			return "", false
+		}
+	}
+
+	name := fmt.Sprintf("//bigquery.googleapis.com/projects/%s/datasets/%s", project, ids[0])
+	if len(ids) == 2 {
+		name += "/tables/" + ids[1]
+	}
+	return name, true
+}
+
+// splitAliasPath splits path into between min and max non-empty segments.
+func splitAliasPath(path string, min, max int) ([]string, bool) {
+	parts := strings.Split(path, "/")
+	if len(parts) < min || len(parts) > max {
+		return nil, false
+	}
+	for _, p := range parts {
+		if p == "" {
+			return nil, false
+		}
+	}
+	return parts, true
+}
@@ -0,0 +1,164 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package iamutil
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolveResourceAlias(t *testing.T) {
+	tests := []struct {
+		alias    string
+		name     string
+		typeKey  string
+		idTuples map[string]string
+	}{
+		{
+			alias:    "gs://my-bucket",
+			name:     "//storage.googleapis.com/b/my-bucket",
+			typeKey:  "b",
+			idTuples: map[string]string{"b": "my-bucket"},
+		},
+		{
+			alias:    "gs://my-bucket/managed/folder/",
+			name:     "//storage.googleapis.com/b/my-bucket/managedFolders/managed%2Ffolder%2F",
+			typeKey:  "b/managedFolders",
+			idTuples: map[string]string{"b": "my-bucket", "managedFolders": "managed/folder/"},
+		},
+		{
+			alias:    "bq://my-project.my_dataset",
+			name:     "//bigquery.googleapis.com/projects/my-project/datasets/my_dataset",
+			typeKey:  "projects/datasets",
+			idTuples: map[string]string{"projects": "my-project", "datasets": "my_dataset"},
+		},
+		{
+			alias:    "bq://example.com:my-project.my_dataset.my_table",
+			name:     "//bigquery.googleapis.com/projects/example.com:my-project/datasets/my_dataset/tables/my_table",
+			typeKey:  "projects/datasets/tables",
+			idTuples: map[string]string{"projects": "example.com:my-project", "datasets": "my_dataset", "tables": "my_table"},
+		},
+		{
+			alias:    "pubsub://my-project/topics/my-topic",
+			name:     "//pubsub.googleapis.com/projects/my-project/topics/my-topic",
+			typeKey:  "projects/topics",
+			idTuples: map[string]string{"projects": "my-project", "topics": "my-topic"},
+		},
+		{
+			alias:    "ar://us-central1/my-project/my-repo",
+			name:     "//artifactregistry.googleapis.com/projects/my-project/locations/us-central1/repositories/my-repo",
+			typeKey:  "projects/locations/repositories",
+			idTuples: map[string]string{"projects": "my-project", "locations": "us-central1", "repositories": "my-repo"},
+		},
+		{
+			alias:    "secret://my-project/my-secret",
+			name:     "//secretmanager.googleapis.com/projects/my-project/secrets/my-secret",
+			typeKey:  "projects/secrets",
+			idTuples: map[string]string{"projects": "my-project", "secrets": "my-secret"},
+		},
+		{
+			alias:    "kms://my-project/global/my-ring",
+			name:     "//cloudkms.googleapis.com/projects/my-project/locations/global/keyRings/my-ring",
+			typeKey:  "projects/locations/keyRings",
+			idTuples: map[string]string{"projects": "my-project", "locations": "global", "keyRings": "my-ring"},
+		},
+		{
+			alias:    "kms://my-project/global/my-ring/my-key",
+			name:     "//cloudkms.googleapis.com/projects/my-project/locations/global/keyRings/my-ring/cryptoKeys/my-key",
+			typeKey:  "projects/locations/keyRings/cryptoKeys",
+			idTuples: map[string]string{"projects": "my-project", "locations": "global", "keyRings": "my-ring", "cryptoKeys": "my-key"},
+		},
+	}
+
+	enabledApis := GetEnabledResources()
+	for _, tc := range tests {
+		name, isAlias, err := ResolveResourceAlias(tc.alias)
+		if err != nil {
+			t.Errorf("unexpected error resolving %q: %v", tc.alias, err)
+			continue
+		}
+		if !isAlias || name != tc.name {
+			t.Errorf("expected %q to resolve to %q, got %q (alias: %v)", tc.alias, tc.name, name, isAlias)
+		}
+
+		r, err := enabledApis.Parse(tc.alias)
+		if err != nil {
+			t.Errorf("unable to parse alias %q: %v", tc.alias, err)
+			continue
+		}
+		if r.GetConfig().TypeKey != tc.typeKey {
+			t.Errorf("expected alias %q to have type key %q, got %q", tc.alias, tc.typeKey, r.GetConfig().TypeKey)
+		}
+		if !reflect.DeepEqual(r.GetRelativeId().IdTuples, tc.idTuples) {
+			t.Errorf("expected alias %q to have IDs %v, got %v", tc.alias, tc.idTuples, r.GetRelativeId().IdTuples)
+		}
+	}
+}
+
+func TestResolveResourceAlias_NotAlias(t *testing.T) {
+	for _, name := range []string{
+		"projects/my-project",
+		"//storage.googleapis.com/b/my-bucket",
+		"https://www.googleapis.com/compute/v1/projects/my-project/zones/us-central1-a/instances/my-instance",
+	} {
+		resolved, isAlias, err := ResolveResourceAlias(name)
+		if err != nil || isAlias || resolved != name {
+			t.Errorf("expected %q to be unchanged, got %q (alias: %v, err: %v)", name, resolved, isAlias, err)
+		}
+	}
+}
+
+func TestResolveResourceAlias_Invalid(t *testing.T) {
+	for _, alias := range []string{
+		"gs://",
+		"bq://my-project",
+		"bq://my-project.my_dataset.my_table.extra",
+		"bq://my-project..my_table",
+		"pubsub://my-project/my-topic",
+		"ar://us-central1/my-project",
+		"secret://my-project/my-secret/versions/1",
+		"kms://my-project/global",
+		"kms://my-project//my-ring",
+	} {
+		if _, isAlias, err := ResolveResourceAlias(alias); err == nil || !isAlias {
+			t.Errorf("expected error resolving invalid alias %q", alias)
+		}
+		if _, err := GetEnabledResources().Parse(alias); err == nil {
+			t.Errorf("expected error parsing invalid alias %q", alias)
+		}
+	}
+}
+
+func TestResolveResourceAlias_RequestURL(t *testing.T) {
+	tests := []struct {
+		alias string
+		url   string
+	}{
+		{
+			alias: "gs://my-bucket",
+			url:   "https://storage.googleapis.com/storage/v1/b/my-bucket/iam?optionsRequestedPolicyVersion=3",
+		},
+		{
+			alias: "gs://my-bucket/managed/folder/",
+			url:   "https://storage.googleapis.com/storage/v1/b/my-bucket/managedFolders/managed%2Ffolder%2F/iam?optionsRequestedPolicyVersion=3",
+		},
+	}
+
+	enabledApis := GetEnabledResources()
+	for _, tc := range tests {
+		r, err := enabledApis.Parse(tc.alias)
+		if err != nil {
+			t.Errorf("unable to parse alias %q: %v", tc.alias, err)
+			continue
+		}
+		req, err := constructRequest(r, &r.GetConfig().GetMethod, nil)
+		if err != nil {
+			t.Errorf("unable to construct request for alias %q: %v", tc.alias, err)
+			continue
+		}
+		if req.URL.String() != tc.url {
+			t.Errorf("expected alias %q to request %q, got %q", tc.alias, tc.url, req.URL.String())
+		}
+	}
+}
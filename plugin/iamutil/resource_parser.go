@@ -7,6 +7,7 @@ package iamutil
 import (
 	"fmt"
 	"net/url"
+	"sort"
 	"strings"
 
 	"github.com/hashicorp/go-gcp-common/gcputil"
@@ -24,7 +25,21 @@ type ResourceParser interface {
 	Parse(string) (Resource, error)
 }
 
-// GeneratedResources implements ResourceParser - a value
+// ResourceRegistry is a ResourceParser that can describe the resource types it supports.
+type ResourceRegistry interface {
+	ResourceParser
+	TypeKeys(service string) []string
+	Versions(typeKey string) []*ResourceVersion
+}
+
+// ResourceVersion is the config of a resource type for one service and API version.
+type ResourceVersion struct {
+	Service string
+	Version string
+	Config  RestResource
+}
+
+// GeneratedResources implements ResourceRegistry - a value
 // is generated using internal/generate_iam.go
 type GeneratedResources map[string]map[string]map[string]RestResource
 
@@ -84,49 +99,73 @@ func (apis GeneratedResources) GetRestConfig(rawName string, fullName *gcputil.F
 	return nil, fmt.Errorf(resourceParsingErrorTmpl, rawName, errorMultipleServices)
 }
 
-func (apis GeneratedResources) Parse(rawName string) (Resource, error) {
-	rUrl, err := url.Parse(rawName)
+// ParseResourceName resolves rawName if it is a resource alias, then parses it as a self-link, full
+// resource name or relative resource name. It returns the name with the service, if given, and
+// the self-link prefix, if any, used to find the resource config.
+func ParseResourceName(rawName string) (*gcputil.FullResourceName, string, error) {
+	name, _, err := ResolveResourceAlias(rawName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rUrl, err := url.Parse(name)
 	if err != nil {
-		return nil, fmt.Errorf(`resource "%s" is invalid URI`, rawName)
+		return nil, "", fmt.Errorf(`resource "%s" is invalid URI`, rawName)
 	}
 
 	var relName *gcputil.RelativeResourceName
 	var prefix, service string
 	if rUrl.Scheme != "" {
-		selfLink, err := gcputil.ParseProjectResourceSelfLink(rawName)
+		selfLink, err := gcputil.ParseProjectResourceSelfLink(name)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		relName = selfLink.RelativeResourceName
 		prefix = selfLink.Prefix
 	} else if rUrl.Host != "" {
-		fullName, err := gcputil.ParseFullResourceName(rawName)
+		fullName, err := gcputil.ParseFullResourceName(name)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		relName = fullName.RelativeResourceName
 		service = fullName.Service
 	} else {
-		relName, err = gcputil.ParseRelativeName(rawName)
+		relName, err = gcputil.ParseRelativeName(name)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 	}
 
 	if relName == nil {
-		return nil, fmt.Errorf(resourceParsingErrorTmpl, rawName, "nil relative name")
+		return nil, "", fmt.Errorf(resourceParsingErrorTmpl, rawName, "nil relative name")
 	}
 
-	cfg, err := apis.GetRestConfig(
-		rawName,
-		&gcputil.FullResourceName{
-			Service:              service,
-			RelativeResourceName: relName,
-		},
-		prefix)
+	// IDs containing a slash, such as managed folders, are escaped in the name so they are
+	// parsed as one ID. Keep the raw ID, as the request path is escaped when it is expanded.
+	for collection, id := range relName.IdTuples {
+		rawId, err := url.PathUnescape(id)
+		if err != nil {
+			return nil, "", fmt.Errorf(resourceParsingErrorTmpl, rawName, fmt.Errorf("invalid escaping in ID %q: %w", id, err))
+		}
+		relName.IdTuples[collection] = rawId
+	}
+	return &gcputil.FullResourceName{
+		Service:              service,
+		RelativeResourceName: relName,
+	}, prefix, nil
+}
+
+func (apis GeneratedResources) Parse(rawName string) (Resource, error) {
+	fullName, prefix, err := ParseResourceName(rawName)
 	if err != nil {
 		return nil, err
 	}
+
+	cfg, err := apis.GetRestConfig(rawName, fullName, prefix)
+	if err != nil {
+		return nil, err
+	}
+	relName := fullName.RelativeResourceName
 	switch cfg.TypeKey {
 	case "projects/datasets":
 		return &DatasetResource{relativeId: relName, config: cfg}, nil
@@ -134,3 +173,42 @@ func (apis GeneratedResources) Parse(rawName string) (Resource, error) {
 		return &IamResource{relativeId: relName, config: cfg}, nil
 	}
 }
+
+// TypeKeys returns the supported resource type keys in sorted order. If service is not empty,
+// only the types of that service are returned.
+func (apis GeneratedResources) TypeKeys(service string) []string {
+	keys := make([]string, 0, len(apis))
+	for typeKey, serviceMap := range apis {
+		if typeKey == "" {
+			continue
+		}
+		if _, ok := serviceMap[service]; service != "" && !ok {
+			continue
+		}
+		keys = append(keys, typeKey)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Versions returns the config of a resource type for each service and API version, sorted by
+// service and version.
+func (apis GeneratedResources) Versions(typeKey string) []*ResourceVersion {
+	var versions []*ResourceVersion
+	for service, versionMap := range apis[typeKey] {
+		for version, config := range versionMap {
+			versions = append(versions, &ResourceVersion{
+				Service: service,
+				Version: version,
+				Config:  config,
+			})
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		if versions[i].Service != versions[j].Service {
+			return versions[i].Service < versions[j].Service
+		}
+		return versions[i].Version < versions[j].Version
+	})
+	return versions
+}
@@ -6,12 +6,14 @@ package iamutil
 import (
 	"fmt"
 	"net/http"
+	"sort"
 	"strings"
 	"testing"
 
 	"net/url"
 
 	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/go-secure-stdlib/strutil"
 )
 
 var letters = "ABCDEFGHIJKLMNOP"
@@ -264,3 +266,59 @@ func verifyConstructRequest(req *http.Request, resourceType string) error {
 	}
 	return nil
 }
+
+func TestGeneratedResources_TypeKeys(t *testing.T) {
+	enabledApis := GetEnabledResources()
+
+	all := enabledApis.TypeKeys("")
+	if len(all) != len(generatedResources)-1 {
+		t.Fatalf("expected %d type keys, got %d", len(generatedResources)-1, len(all))
+	}
+	if !sort.StringsAreSorted(all) {
+		t.Fatalf("expected type keys to be sorted")
+	}
+
+	pubsub := enabledApis.TypeKeys("pubsub")
+	if !strutil.StrListContains(pubsub, "projects/topics") {
+		t.Fatalf("expected pubsub type keys to include projects/topics, got %v", pubsub)
+	}
+	for _, typeKey := range pubsub {
+		if _, ok := generatedResources[typeKey]["pubsub"]; !ok {
+			t.Fatalf("expected only pubsub type keys, got %q", typeKey)
+		}
+	}
+
+	if keys := enabledApis.TypeKeys("notaservice"); len(keys) != 0 {
+		t.Fatalf("expected no type keys for unknown service, got %v", keys)
+	}
+}
+
+func TestGeneratedResources_Versions(t *testing.T) {
+	apis := GeneratedResources{
+		"projects/things": {
+			"b": {
+				"v1": RestResource{TypeKey: "projects/things", Service: "b"},
+			},
+			"a": {
+				"v2":      RestResource{TypeKey: "projects/things", Service: "a"},
+				"v1beta1": RestResource{TypeKey: "projects/things", Service: "a"},
+			},
+		},
+	}
+
+	var actual []string
+	for _, v := range apis.Versions("projects/things") {
+		if v.Config.Service != v.Service {
+			t.Fatalf("expected config for service %q, got %q", v.Service, v.Config.Service)
+		}
+		actual = append(actual, v.Service+"/"+v.Version)
+	}
+	expected := []string{"a/v1beta1", "a/v2", "b/v1"}
+	if strings.Join(actual, ",") != strings.Join(expected, ",") {
+		t.Fatalf("expected versions %v, got %v", expected, actual)
+	}
+
+	if versions := apis.Versions("projects/unknown"); len(versions) != 0 {
+		t.Fatalf("expected no versions for unknown type, got %d", len(versions))
+	}
+}
@@ -29,6 +29,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://iap.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "v1beta1",
@@ -48,6 +53,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://iap.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -71,6 +81,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://accesscontextmanager.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -97,6 +112,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "b/{bucket}/iam",
 					RequestFormat: `%s`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "GET",
+					BaseURL:    "https://storage.googleapis.com/storage/v1/",
+					Path:       "b/{bucket}/iam/testPermissions",
+				},
 			},
 		},
 	},
@@ -124,6 +144,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "b/{bucket}/managedFolders/{managedFolder}/iam",
 					RequestFormat: `%s`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "GET",
+					BaseURL:    "https://storage.googleapis.com/storage/v1/",
+					Path:       "b/{bucket}/managedFolders/{managedFolder}/iam/testPermissions",
+				},
 			},
 		},
 	},
@@ -152,6 +177,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "b/{bucket}/o/{object}/iam",
 					RequestFormat: `%s`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "GET",
+					BaseURL:    "https://storage.googleapis.com/storage/v1/",
+					Path:       "b/{bucket}/o/{object}/iam/testPermissions",
+				},
 			},
 		},
 	},
@@ -175,6 +205,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://cloudbilling.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -201,6 +236,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "b/{bucket}/iam",
 					RequestFormat: `%s`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "GET",
+					BaseURL:    "https://storage.googleapis.com/storage/v1/",
+					Path:       "b/{bucket}/iam/testPermissions",
+				},
 			},
 		},
 	},
@@ -229,6 +269,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "b/{bucket}/o/{object}/iam",
 					RequestFormat: `%s`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "GET",
+					BaseURL:    "https://storage.googleapis.com/storage/v1/",
+					Path:       "b/{bucket}/o/{object}/iam/testPermissions",
+				},
 			},
 		},
 	},
@@ -296,6 +341,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "locations/global/firewallPolicies/{resource}/setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://compute.googleapis.com/compute/v1/",
+					Path:       "locations/global/firewallPolicies/{resource}/testIamPermissions",
+				},
 			},
 		},
 	},
@@ -319,6 +369,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v2/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://cloudresourcemanager.googleapis.com/",
+					Path:       "v2/{+resource}:testIamPermissions",
+				},
 			},
 			"v2beta1": RestResource{
 				Name:                      "folders",
@@ -338,6 +393,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v2/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://cloudresourcemanager.googleapis.com/",
+					Path:       "v2/{+resource}:testIamPermissions",
+				},
 			},
 			"v3": RestResource{
 				Name:                      "folders",
@@ -357,6 +417,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v3/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://cloudresourcemanager.googleapis.com/",
+					Path:       "v3/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -380,6 +445,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v2/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://logging.googleapis.com/",
+					Path:       "v2/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -403,6 +473,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v2/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://logging.googleapis.com/",
+					Path:       "v2/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -426,6 +501,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://iam.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -449,6 +529,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://cloudresourcemanager.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "organizations",
@@ -468,6 +553,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://cloudresourcemanager.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 			"v3": RestResource{
 				Name:                      "organizations",
@@ -487,6 +577,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v3/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://cloudresourcemanager.googleapis.com/",
+					Path:       "v3/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -510,6 +605,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://apigee.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -533,6 +633,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://apigee.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -556,6 +661,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v2/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://logging.googleapis.com/",
+					Path:       "v2/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -579,6 +689,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://dataplex.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -602,6 +717,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://securitycenter.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "sources",
@@ -621,6 +741,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://securitycenter.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -644,6 +769,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://apigee.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -669,6 +799,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/projects/{resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://cloudresourcemanager.googleapis.com/",
+					Path:       "v1/projects/{resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:               "projects",
@@ -690,6 +825,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/projects/{resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://cloudresourcemanager.googleapis.com/",
+					Path:       "v1beta1/projects/{resource}:testIamPermissions",
+				},
 			},
 			"v3": RestResource{
 				Name:                      "projects",
@@ -709,6 +849,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v3/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://cloudresourcemanager.googleapis.com/",
+					Path:       "v3/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -732,6 +877,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://binaryauthorization.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "attestors",
@@ -751,6 +901,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://binaryauthorization.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -821,6 +976,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "projects/{project}/global/backendBuckets/{resource}/setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://compute.googleapis.com/compute/v1/",
+					Path:       "projects/{project}/global/backendBuckets/{resource}/testIamPermissions",
+				},
 			},
 		},
 	},
@@ -891,6 +1051,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "projects/{project}/global/backendServices/{resource}/setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://compute.googleapis.com/compute/v1/",
+					Path:       "projects/{project}/global/backendServices/{resource}/testIamPermissions",
+				},
 			},
 		},
 	},
@@ -914,6 +1079,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://biglake.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -937,6 +1107,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://biglake.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -960,6 +1135,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://biglake.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -983,6 +1163,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://runtimeconfig.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -1029,6 +1214,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "bigquery/v2/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://bigquery.googleapis.com",
+					Path:       "bigquery/v2/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -1052,6 +1242,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "bigquery/v2/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://bigquery.googleapis.com",
+					Path:       "bigquery/v2/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -1100,6 +1295,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "deploymentmanager/v2/projects/{project}/global/deployments/{resource}/setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://deploymentmanager.googleapis.com/",
+					Path:       "deploymentmanager/v2/projects/{project}/global/deployments/{resource}/testIamPermissions",
+				},
 			},
 			"v2beta": RestResource{
 				Name:               "deployments",
@@ -1122,6 +1322,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "deploymentmanager/v2beta/projects/{project}/global/deployments/{resource}/setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://deploymentmanager.googleapis.com/",
+					Path:       "deploymentmanager/v2beta/projects/{project}/global/deployments/{resource}/testIamPermissions",
+				},
 			},
 		},
 	},
@@ -1192,6 +1397,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "projects/{project}/global/firewallPolicies/{resource}/setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://compute.googleapis.com/compute/v1/",
+					Path:       "projects/{project}/global/firewallPolicies/{resource}/testIamPermissions",
+				},
 			},
 		},
 	},
@@ -1262,6 +1472,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "projects/{project}/global/images/{resource}/setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://compute.googleapis.com/compute/v1/",
+					Path:       "projects/{project}/global/images/{resource}/testIamPermissions",
+				},
 			},
 		},
 	},
@@ -1332,6 +1547,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "projects/{project}/global/instanceTemplates/{resource}/setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://compute.googleapis.com/compute/v1/",
+					Path:       "projects/{project}/global/instanceTemplates/{resource}/testIamPermissions",
+				},
 			},
 		},
 	},
@@ -1355,6 +1575,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v2/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://bigtableadmin.googleapis.com/",
+					Path:       "v2/{+resource}:testIamPermissions",
+				},
 			},
 		},
 		"spanner": {
@@ -1376,6 +1601,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://spanner.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -1399,6 +1629,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://spanner.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -1422,6 +1657,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v2/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://bigtableadmin.googleapis.com/",
+					Path:       "v2/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -1445,6 +1685,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://spanner.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -1468,6 +1713,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://spanner.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -1491,6 +1741,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v2/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://bigtableadmin.googleapis.com/",
+					Path:       "v2/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -1514,6 +1769,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v2/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://bigtableadmin.googleapis.com/",
+					Path:       "v2/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -1537,6 +1797,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v2/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://bigtableadmin.googleapis.com/",
+					Path:       "v2/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -1560,6 +1825,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v2/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://bigtableadmin.googleapis.com/",
+					Path:       "v2/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -1583,6 +1853,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v2/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://bigtableadmin.googleapis.com/",
+					Path:       "v2/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -1653,6 +1928,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "projects/{project}/global/interconnectAttachmentGroups/{resource}/setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://compute.googleapis.com/compute/v1/",
+					Path:       "projects/{project}/global/interconnectAttachmentGroups/{resource}/testIamPermissions",
+				},
 			},
 		},
 	},
@@ -1723,6 +2003,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "projects/{project}/global/interconnectGroups/{resource}/setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://compute.googleapis.com/compute/v1/",
+					Path:       "projects/{project}/global/interconnectGroups/{resource}/testIamPermissions",
+				},
 			},
 		},
 	},
@@ -1772,6 +2057,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://ml.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -1868,6 +2158,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "projects/{project}/global/licenses/{resource}/setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://compute.googleapis.com/compute/v1/",
+					Path:       "projects/{project}/global/licenses/{resource}/testIamPermissions",
+				},
 			},
 		},
 	},
@@ -1891,6 +2186,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://networksecurity.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "addressGroups",
@@ -1910,6 +2210,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://networksecurity.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -1933,6 +2238,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://apigateway.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta": RestResource{
 				Name:                      "apis",
@@ -1952,6 +2262,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://apigateway.googleapis.com/",
+					Path:       "v1beta/{+resource}:testIamPermissions",
+				},
 			},
 		},
 		"apigeeregistry": {
@@ -1973,6 +2288,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://apigeeregistry.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -1996,6 +2316,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://apigeeregistry.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -2019,6 +2344,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://apigateway.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta": RestResource{
 				Name:                      "configs",
@@ -2038,6 +2368,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://apigateway.googleapis.com/",
+					Path:       "v1beta/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -2061,6 +2396,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://apigeeregistry.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -2084,6 +2424,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://apigeeregistry.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -2107,6 +2452,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://apigeeregistry.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -2130,6 +2480,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://apigeeregistry.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -2153,6 +2508,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://apigeeregistry.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -2176,6 +2536,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://beyondcorp.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1alpha": RestResource{
 				Name:                      "appConnections",
@@ -2195,6 +2560,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1alpha/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://beyondcorp.googleapis.com/",
+					Path:       "v1alpha/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -2218,6 +2588,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://beyondcorp.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1alpha": RestResource{
 				Name:                      "appConnectors",
@@ -2237,6 +2612,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1alpha/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://beyondcorp.googleapis.com/",
+					Path:       "v1alpha/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -2260,6 +2640,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://beyondcorp.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1alpha": RestResource{
 				Name:                      "appGateways",
@@ -2279,6 +2664,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1alpha/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://beyondcorp.googleapis.com/",
+					Path:       "v1alpha/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -2302,6 +2692,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1alpha/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://beyondcorp.googleapis.com/",
+					Path:       "v1alpha/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -2325,6 +2720,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://apphub.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1alpha": RestResource{
 				Name:                      "applications",
@@ -2344,6 +2744,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1alpha/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://apphub.googleapis.com/",
+					Path:       "v1alpha/{+resource}:testIamPermissions",
+				},
 			},
 		},
 		"beyondcorp": {
@@ -2365,6 +2770,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1alpha/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://beyondcorp.googleapis.com/",
+					Path:       "v1alpha/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -2388,6 +2798,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://apigeeregistry.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -2411,6 +2826,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://dataplex.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -2434,6 +2854,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://networksecurity.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "authorizationPolicies",
@@ -2453,7 +2878,12 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
-			},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://networksecurity.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
+			},
 		},
 	},
 	"projects/locations/authorizedViewSets/authorizedViews": {
@@ -2476,6 +2906,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://contactcenterinsights.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -2499,6 +2934,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://networksecurity.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "authzPolicies",
@@ -2518,6 +2958,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://networksecurity.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -2541,6 +2986,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://dataproc.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -2564,6 +3014,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://gkebackup.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -2587,6 +3042,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://gkebackup.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -2610,6 +3070,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://gkebackup.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -2633,6 +3098,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://gkeonprem.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -2656,6 +3126,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://gkeonprem.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -2679,6 +3154,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://gkeonprem.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -2702,6 +3182,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v2/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://logging.googleapis.com/",
+					Path:       "v2/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -2725,6 +3210,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://privateca.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -2748,6 +3238,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://privateca.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -2771,6 +3266,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://privateca.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -2794,6 +3294,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://privateca.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -2817,6 +3322,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://privateca.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -2840,6 +3350,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://dataplex.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -2863,6 +3378,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://eventarc.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -2886,6 +3406,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://eventarc.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -2909,6 +3434,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://networksecurity.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "clientTlsPolicies",
@@ -2928,6 +3458,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://networksecurity.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -3012,6 +3547,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://datamigration.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "connectionProfiles",
@@ -3031,6 +3571,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://datamigration.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -3054,6 +3599,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1alpha/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://beyondcorp.googleapis.com/",
+					Path:       "v1alpha/{+resource}:testIamPermissions",
+				},
 			},
 		},
 		"bigqueryconnection": {
@@ -3075,6 +3625,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://bigqueryconnection.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "connections",
@@ -3094,6 +3649,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://bigqueryconnection.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 		"cloudbuild": {
@@ -3115,6 +3675,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v2/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://cloudbuild.googleapis.com/",
+					Path:       "v2/{+resource}:testIamPermissions",
+				},
 			},
 		},
 		"connectors": {
@@ -3136,6 +3701,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://connectors.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -3159,6 +3729,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://networkmanagement.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "connectivityTests",
@@ -3178,6 +3753,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://networkmanagement.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -3201,6 +3781,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1alpha/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://beyondcorp.googleapis.com/",
+					Path:       "v1alpha/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -3224,6 +3809,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://datamigration.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -3247,6 +3837,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://clouddeploy.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -3270,6 +3865,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://dataplex.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -3293,6 +3893,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://analyticshub.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "dataExchanges",
@@ -3312,6 +3917,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://analyticshub.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -3335,6 +3945,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://analyticshub.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "listings",
@@ -3354,6 +3969,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://analyticshub.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -3377,6 +3997,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://bigquerydatapolicy.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v2": RestResource{
 				Name:                      "dataPolicies",
@@ -3396,6 +4021,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v2/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://bigquerydatapolicy.googleapis.com/",
+					Path:       "v2/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -3419,6 +4049,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://dataplex.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -3442,6 +4077,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://dataplex.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -3465,6 +4105,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://dataplex.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -3488,6 +4133,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://dataplex.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -3511,6 +4161,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://aiplatform.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "datasets",
@@ -3530,6 +4185,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://aiplatform.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 		"healthcare": {
@@ -3551,6 +4211,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://healthcare.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "datasets",
@@ -3570,6 +4235,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://healthcare.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -3593,6 +4263,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://healthcare.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "consentStores",
@@ -3612,6 +4287,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://healthcare.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -3635,6 +4315,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://healthcare.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "dataMapperWorkspaces",
@@ -3654,6 +4339,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://healthcare.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -3677,6 +4367,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://healthcare.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "dicomStores",
@@ -3696,6 +4391,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://healthcare.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -3719,6 +4419,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://healthcare.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "fhirStores",
@@ -3738,6 +4443,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://healthcare.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -3761,6 +4471,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://clouddeploy.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -3784,6 +4499,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://clouddeploy.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -3807,6 +4527,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://config.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -3830,6 +4555,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://apigeeregistry.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -3853,6 +4583,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://managedidentities.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1alpha1": RestResource{
 				Name:                      "domains",
@@ -3872,6 +4607,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1alpha1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://managedidentities.googleapis.com/",
+					Path:       "v1alpha1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "domains",
@@ -3891,6 +4631,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://managedidentities.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -3914,6 +4659,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://managedidentities.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1alpha1": RestResource{
 				Name:                      "backups",
@@ -3933,6 +4683,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1alpha1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://managedidentities.googleapis.com/",
+					Path:       "v1alpha1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "backups",
@@ -3952,6 +4707,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://managedidentities.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -3975,6 +4735,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://networkservices.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -3998,6 +4763,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://networkservices.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -4021,6 +4791,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://networkservices.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -4044,6 +4819,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://cloudkms.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -4067,6 +4847,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://cloudkms.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -4090,6 +4875,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://aiplatform.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -4113,6 +4903,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://eventarc.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -4136,6 +4931,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://datacatalog.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "entryGroups",
@@ -4155,6 +4955,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://datacatalog.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 		"dataplex": {
@@ -4176,6 +4981,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://dataplex.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -4199,6 +5009,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://dataplex.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -4222,6 +5037,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://dataplex.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -4245,6 +5065,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://aiplatform.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "featureGroups",
@@ -4264,6 +5089,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://aiplatform.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -4287,6 +5117,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://aiplatform.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "featureOnlineStores",
@@ -4306,6 +5141,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://aiplatform.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -4329,6 +5169,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://aiplatform.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "featureViews",
@@ -4348,6 +5193,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://aiplatform.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -4371,6 +5221,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://gkehub.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1alpha": RestResource{
 				Name:                      "features",
@@ -4390,6 +5245,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1alpha/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://gkehub.googleapis.com/",
+					Path:       "v1alpha/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta": RestResource{
 				Name:                      "features",
@@ -4409,6 +5269,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://gkehub.googleapis.com/",
+					Path:       "v1beta/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -4432,6 +5297,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://aiplatform.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "featurestores",
@@ -4451,6 +5321,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://aiplatform.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -4474,6 +5349,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://aiplatform.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "entityTypes",
@@ -4493,6 +5373,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://aiplatform.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -4516,6 +5401,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://metastore.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1alpha": RestResource{
 				Name:                      "federations",
@@ -4535,6 +5425,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1alpha/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://metastore.googleapis.com/",
+					Path:       "v1alpha/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta": RestResource{
 				Name:                      "federations",
@@ -4554,6 +5449,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://metastore.googleapis.com/",
+					Path:       "v1beta/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -4577,6 +5477,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://dataform.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "folders",
@@ -4596,6 +5501,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://dataform.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -4619,6 +5529,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://cloudfunctions.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v2": RestResource{
 				Name:                      "functions",
@@ -4638,6 +5553,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v2/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://cloudfunctions.googleapis.com/",
+					Path:       "v2/{+resource}:testIamPermissions",
+				},
 			},
 			"v2alpha": RestResource{
 				Name:                      "functions",
@@ -4657,6 +5577,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v2alpha/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://cloudfunctions.googleapis.com/",
+					Path:       "v2alpha/{+resource}:testIamPermissions",
+				},
 			},
 			"v2beta": RestResource{
 				Name:                      "functions",
@@ -4676,6 +5601,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v2beta/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://cloudfunctions.googleapis.com/",
+					Path:       "v2beta/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -4699,6 +5629,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://apigateway.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta": RestResource{
 				Name:                      "gateways",
@@ -4718,6 +5653,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://apigateway.googleapis.com/",
+					Path:       "v1beta/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -4741,6 +5681,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://dataplex.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -4764,6 +5709,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://dataplex.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -4787,6 +5737,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://dataplex.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -4810,6 +5765,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://eventarc.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -4833,6 +5793,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://dataplex.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -4856,6 +5821,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://networkconnectivity.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1alpha1": RestResource{
 				Name:                      "hubs",
@@ -4875,6 +5845,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1alpha1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://networkconnectivity.googleapis.com/",
+					Path:       "v1alpha1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -4898,6 +5873,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://networkconnectivity.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -4921,6 +5901,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://apigeeregistry.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 		"datafusion": {
@@ -4942,6 +5927,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://datafusion.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "instances",
@@ -4961,6 +5951,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://datafusion.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 		"notebooks": {
@@ -4982,6 +5977,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://notebooks.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v2": RestResource{
 				Name:                      "instances",
@@ -5001,6 +6001,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v2/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://notebooks.googleapis.com/",
+					Path:       "v2/{+resource}:testIamPermissions",
+				},
 			},
 		},
 		"run": {
@@ -5022,6 +6027,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://run.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 		"securesourcemanager": {
@@ -5043,6 +6053,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://securesourcemanager.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -5066,6 +6081,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://datafusion.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -5089,6 +6109,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://networkconnectivity.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1alpha1": RestResource{
 				Name:                      "internalRanges",
@@ -5108,6 +6133,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1alpha1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://networkconnectivity.googleapis.com/",
+					Path:       "v1alpha1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -5131,6 +6161,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://run.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v2": RestResource{
 				Name:                      "jobs",
@@ -5150,6 +6185,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v2/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://run.googleapis.com/",
+					Path:       "v2/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -5173,6 +6213,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://cloudkms.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -5196,6 +6241,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://cloudkms.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -5219,6 +6269,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://cloudkms.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -5242,6 +6297,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://dataplex.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -5265,6 +6325,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://dataplex.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -5288,6 +6353,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://dataplex.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -5311,6 +6381,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://dataplex.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -5334,6 +6409,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://dataplex.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -5357,6 +6437,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://backupdr.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -5380,6 +6465,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://gkehub.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1alpha": RestResource{
 				Name:                      "memberships",
@@ -5399,6 +6489,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1alpha/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://gkehub.googleapis.com/",
+					Path:       "v1alpha/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta": RestResource{
 				Name:                      "memberships",
@@ -5418,6 +6513,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://gkehub.googleapis.com/",
+					Path:       "v1beta/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "memberships",
@@ -5437,6 +6537,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://gkehub.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -5460,6 +6565,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://eventarc.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -5483,6 +6593,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://datamigration.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "migrationJobs",
@@ -5502,6 +6617,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://datamigration.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -5525,6 +6645,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://datamigration.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -5548,6 +6673,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://aiplatform.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "models",
@@ -5567,6 +6697,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://aiplatform.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -5590,6 +6725,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://servicedirectory.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "namespaces",
@@ -5609,6 +6749,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://servicedirectory.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -5632,6 +6777,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://servicedirectory.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "services",
@@ -5651,6 +6801,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://servicedirectory.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -5674,6 +6829,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://servicedirectory.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -5697,6 +6857,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://aiplatform.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "notebookRuntimeTemplates",
@@ -5716,6 +6881,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://aiplatform.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -5739,6 +6909,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://containeranalysis.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "notes",
@@ -5758,6 +6933,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://containeranalysis.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -5781,6 +6961,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://containeranalysis.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "occurrences",
@@ -5800,6 +6985,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://containeranalysis.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -5823,6 +7013,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://managedidentities.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1alpha1": RestResource{
 				Name:                      "peerings",
@@ -5842,6 +7037,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1alpha1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://managedidentities.googleapis.com/",
+					Path:       "v1alpha1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "peerings",
@@ -5861,6 +7061,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://managedidentities.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -5884,6 +7089,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://eventarc.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -5907,6 +7117,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://networkconnectivity.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -5930,6 +7145,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://dataplex.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -5953,6 +7173,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://vmwareengine.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -5976,6 +7201,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://vmwareengine.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -5999,6 +7229,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://vmwareengine.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -6022,6 +7257,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://datamigration.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -6045,6 +7285,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://connectors.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -6068,6 +7313,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v2/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://cloudtasks.googleapis.com/",
+					Path:       "v2/{+resource}:testIamPermissions",
+				},
 			},
 			"v2beta2": RestResource{
 				Name:                      "queues",
@@ -6087,6 +7337,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v2beta2/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://cloudtasks.googleapis.com/",
+					Path:       "v2beta2/{+resource}:testIamPermissions",
+				},
 			},
 			"v2beta3": RestResource{
 				Name:                      "queues",
@@ -6106,6 +7361,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v2beta3/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://cloudtasks.googleapis.com/",
+					Path:       "v2beta3/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -6129,6 +7389,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://aiplatform.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "reasoningEngines",
@@ -6148,6 +7413,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://aiplatform.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -6171,6 +7441,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://domains.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1alpha2": RestResource{
 				Name:                      "registrations",
@@ -6190,6 +7465,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1alpha2/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://domains.googleapis.com/",
+					Path:       "v1alpha2/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "registrations",
@@ -6209,6 +7489,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://domains.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -6232,6 +7517,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://artifactregistry.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "repositories",
@@ -6251,6 +7541,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://artifactregistry.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta2": RestResource{
 				Name:                      "repositories",
@@ -6270,6 +7565,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta2/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://artifactregistry.googleapis.com/",
+					Path:       "v1beta2/{+resource}:testIamPermissions",
+				},
 			},
 		},
 		"dataform": {
@@ -6291,6 +7591,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://dataform.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "repositories",
@@ -6310,6 +7615,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://dataform.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 		"securesourcemanager": {
@@ -6331,6 +7641,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://securesourcemanager.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -6354,6 +7669,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://dataform.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "workspaces",
@@ -6373,6 +7693,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://dataform.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -6396,6 +7721,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://bigqueryreservation.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -6419,6 +7749,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://bigqueryreservation.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -6442,6 +7777,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://gkebackup.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -6465,6 +7805,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://gkebackup.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -6488,6 +7833,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://gkebackup.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -6511,6 +7861,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://privateca.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -6534,6 +7889,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://apigeeregistry.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -6557,6 +7917,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://notebooks.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -6580,6 +7945,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://gkehub.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1alpha": RestResource{
 				Name:                      "scopes",
@@ -6599,6 +7969,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1alpha/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://gkehub.googleapis.com/",
+					Path:       "v1alpha/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta": RestResource{
 				Name:                      "scopes",
@@ -6618,6 +7993,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://gkehub.googleapis.com/",
+					Path:       "v1beta/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -6641,6 +8021,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://secretmanager.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta2": RestResource{
 				Name:                      "secrets",
@@ -6660,6 +8045,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta2/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://secretmanager.googleapis.com/",
+					Path:       "v1beta2/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -6683,6 +8073,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://beyondcorp.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1alpha": RestResource{
 				Name:                      "securityGateways",
@@ -6702,6 +8097,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1alpha/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://beyondcorp.googleapis.com/",
+					Path:       "v1alpha/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -6725,6 +8125,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://beyondcorp.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1alpha": RestResource{
 				Name:                      "applications",
@@ -6744,6 +8149,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1alpha/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://beyondcorp.googleapis.com/",
+					Path:       "v1alpha/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -6767,6 +8177,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://networksecurity.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "serverTlsPolicies",
@@ -6786,6 +8201,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://networksecurity.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -6809,6 +8229,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://networkconnectivity.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -6832,6 +8257,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://networkconnectivity.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -6855,6 +8285,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://networkconnectivity.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -6878,6 +8313,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://metastore.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1alpha": RestResource{
 				Name:                      "services",
@@ -6897,6 +8337,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1alpha/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://metastore.googleapis.com/",
+					Path:       "v1alpha/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta": RestResource{
 				Name:                      "services",
@@ -6916,6 +8361,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://metastore.googleapis.com/",
+					Path:       "v1beta/{+resource}:testIamPermissions",
+				},
 			},
 		},
 		"run": {
@@ -6937,6 +8387,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://run.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v2": RestResource{
 				Name:                      "services",
@@ -6956,6 +8411,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v2/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://run.googleapis.com/",
+					Path:       "v2/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -6979,6 +8439,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://metastore.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1alpha": RestResource{
 				Name:                      "backups",
@@ -6998,6 +8463,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1alpha/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://metastore.googleapis.com/",
+					Path:       "v1alpha/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta": RestResource{
 				Name:                      "backups",
@@ -7017,6 +8487,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://metastore.googleapis.com/",
+					Path:       "v1beta/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -7040,6 +8515,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://metastore.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1alpha": RestResource{
 				Name:                      "databases",
@@ -7059,6 +8539,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1alpha/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://metastore.googleapis.com/",
+					Path:       "v1alpha/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta": RestResource{
 				Name:                      "databases",
@@ -7078,6 +8563,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://metastore.googleapis.com/",
+					Path:       "v1beta/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -7101,6 +8591,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://metastore.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1alpha": RestResource{
 				Name:                      "tables",
@@ -7120,6 +8615,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1alpha/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://metastore.googleapis.com/",
+					Path:       "v1alpha/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta": RestResource{
 				Name:                      "tables",
@@ -7139,6 +8639,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://metastore.googleapis.com/",
+					Path:       "v1beta/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -7162,6 +8667,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://networkconnectivity.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1alpha1": RestResource{
 				Name:                      "spokes",
@@ -7181,6 +8691,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1alpha1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://networkconnectivity.googleapis.com/",
+					Path:       "v1alpha1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -7204,6 +8719,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://analyticshub.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -7227,6 +8747,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://datacatalog.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "tagTemplates",
@@ -7246,6 +8771,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://datacatalog.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -7269,6 +8799,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://clouddeploy.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -7292,6 +8827,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://datacatalog.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "taxonomies",
@@ -7311,6 +8851,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://datacatalog.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -7334,6 +8879,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://datacatalog.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "policyTags",
@@ -7353,6 +8903,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://datacatalog.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -7376,6 +8931,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://dataform.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "teamFolders",
@@ -7395,6 +8955,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://dataform.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -7418,6 +8983,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://eventarc.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -7441,6 +9011,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://gkeonprem.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -7464,6 +9039,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://gkeonprem.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -7487,6 +9067,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://gkeonprem.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -7510,6 +9095,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v2/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://run.googleapis.com/",
+					Path:       "v2/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -7533,6 +9123,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://run.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -7556,6 +9151,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://dataproc.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -7579,6 +9179,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://iam.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -7602,6 +9207,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://workstations.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta": RestResource{
 				Name:                      "workstationConfigs",
@@ -7621,6 +9231,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://workstations.googleapis.com/",
+					Path:       "v1beta/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -7644,6 +9259,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://workstations.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta": RestResource{
 				Name:                      "workstations",
@@ -7663,6 +9283,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://workstations.googleapis.com/",
+					Path:       "v1beta/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -7733,6 +9358,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "projects/{project}/global/machineImages/{resource}/setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://compute.googleapis.com/compute/v1/",
+					Path:       "projects/{project}/global/machineImages/{resource}/testIamPermissions",
+				},
 			},
 		},
 	},
@@ -7756,6 +9386,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "dns/v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://dns.googleapis.com/",
+					Path:       "dns/v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta2": RestResource{
 				Name:                      "managedZones",
@@ -7775,6 +9410,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "dns/v1beta2/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://dns.googleapis.com/",
+					Path:       "dns/v1beta2/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -7798,6 +9438,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://ml.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -7821,6 +9466,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://containeranalysis.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1alpha1": RestResource{
 				Name:                      "notes",
@@ -7840,6 +9490,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1alpha1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://containeranalysis.googleapis.com/",
+					Path:       "v1alpha1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "notes",
@@ -7859,6 +9514,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://containeranalysis.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -7882,6 +9542,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://containeranalysis.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1alpha1": RestResource{
 				Name:                      "occurrences",
@@ -7901,6 +9566,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1alpha1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://containeranalysis.googleapis.com/",
+					Path:       "v1alpha1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "occurrences",
@@ -7920,6 +9590,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://containeranalysis.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -7943,6 +9618,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://binaryauthorization.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "policy",
@@ -7962,6 +9642,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://binaryauthorization.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -8011,6 +9696,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://dataproc.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -8134,6 +9824,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "projects/{project}/regions/{region}/backendServices/{resource}/setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://compute.googleapis.com/compute/v1/",
+					Path:       "projects/{project}/regions/{region}/backendServices/{resource}/testIamPermissions",
+				},
 			},
 		},
 	},
@@ -8157,6 +9852,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://dataproc.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -8230,6 +9930,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "projects/{project}/regions/{region}/disks/{resource}/setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://compute.googleapis.com/compute/v1/",
+					Path:       "projects/{project}/regions/{region}/disks/{resource}/testIamPermissions",
+				},
 			},
 		},
 	},
@@ -8303,6 +10008,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "projects/{project}/regions/{region}/firewallPolicies/{resource}/setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://compute.googleapis.com/compute/v1/",
+					Path:       "projects/{project}/regions/{region}/firewallPolicies/{resource}/testIamPermissions",
+				},
 			},
 		},
 	},
@@ -8426,6 +10136,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "projects/{project}/regions/{region}/instantSnapshots/{resource}/setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://compute.googleapis.com/compute/v1/",
+					Path:       "projects/{project}/regions/{region}/instantSnapshots/{resource}/testIamPermissions",
+				},
 			},
 		},
 	},
@@ -8476,6 +10191,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://dataproc.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -8549,6 +10269,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "projects/{project}/regions/{region}/networkAttachments/{resource}/setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://compute.googleapis.com/compute/v1/",
+					Path:       "projects/{project}/regions/{region}/networkAttachments/{resource}/testIamPermissions",
+				},
 			},
 		},
 	},
@@ -8622,6 +10347,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "projects/{project}/regions/{region}/nodeTemplates/{resource}/setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://compute.googleapis.com/compute/v1/",
+					Path:       "projects/{project}/regions/{region}/nodeTemplates/{resource}/testIamPermissions",
+				},
 			},
 		},
 	},
@@ -8645,6 +10375,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://dataproc.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -8745,6 +10480,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "projects/{project}/regions/{region}/resourcePolicies/{resource}/setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://compute.googleapis.com/compute/v1/",
+					Path:       "projects/{project}/regions/{region}/resourcePolicies/{resource}/testIamPermissions",
+				},
 			},
 		},
 	},
@@ -8818,6 +10558,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "projects/{project}/regions/{region}/serviceAttachments/{resource}/setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://compute.googleapis.com/compute/v1/",
+					Path:       "projects/{project}/regions/{region}/serviceAttachments/{resource}/testIamPermissions",
+				},
 			},
 		},
 	},
@@ -8941,6 +10686,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "projects/{project}/regions/{region}/subnetworks/{resource}/setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://compute.googleapis.com/compute/v1/",
+					Path:       "projects/{project}/regions/{region}/subnetworks/{resource}/testIamPermissions",
+				},
 			},
 		},
 	},
@@ -8964,6 +10714,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://dataproc.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -8987,6 +10742,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://pubsub.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -9010,6 +10770,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://secretmanager.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta1": RestResource{
 				Name:                      "secrets",
@@ -9029,6 +10794,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://secretmanager.googleapis.com/",
+					Path:       "v1beta1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta2": RestResource{
 				Name:                      "secrets",
@@ -9048,6 +10818,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta2/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://secretmanager.googleapis.com/",
+					Path:       "v1beta2/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -9071,6 +10846,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://iam.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -9189,6 +10969,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "projects/{project}/global/snapshots/{resource}/setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://compute.googleapis.com/compute/v1/",
+					Path:       "projects/{project}/global/snapshots/{resource}/testIamPermissions",
+				},
 			},
 		},
 		"pubsub": {
@@ -9210,6 +10995,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://pubsub.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -9233,6 +11023,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://pubsub.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta2": RestResource{
 				Name:                      "subscriptions",
@@ -9252,6 +11047,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta2/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://pubsub.googleapis.com/",
+					Path:       "v1beta2/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -9275,6 +11075,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v2/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://identitytoolkit.googleapis.com/",
+					Path:       "v2/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -9298,6 +11103,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://pubsub.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 			"v1beta2": RestResource{
 				Name:                      "topics",
@@ -9317,6 +11127,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1beta2/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://pubsub.googleapis.com/",
+					Path:       "v1beta2/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -9390,6 +11205,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "projects/{project}/zones/{zone}/disks/{resource}/setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://compute.googleapis.com/compute/v1/",
+					Path:       "projects/{project}/zones/{zone}/disks/{resource}/testIamPermissions",
+				},
 			},
 		},
 	},
@@ -9463,6 +11283,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "projects/{project}/zones/{zone}/instances/{resource}/setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://compute.googleapis.com/compute/v1/",
+					Path:       "projects/{project}/zones/{zone}/instances/{resource}/testIamPermissions",
+				},
 			},
 		},
 	},
@@ -9586,6 +11411,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "projects/{project}/zones/{zone}/instantSnapshots/{resource}/setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://compute.googleapis.com/compute/v1/",
+					Path:       "projects/{project}/zones/{zone}/instantSnapshots/{resource}/testIamPermissions",
+				},
 			},
 		},
 	},
@@ -9659,6 +11489,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "projects/{project}/zones/{zone}/nodeGroups/{resource}/setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://compute.googleapis.com/compute/v1/",
+					Path:       "projects/{project}/zones/{zone}/nodeGroups/{resource}/testIamPermissions",
+				},
 			},
 		},
 	},
@@ -9709,6 +11544,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "projects/{project}/zones/{zone}/{+parentResource}/reservationSubBlocks/{resource}/setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://compute.googleapis.com/compute/v1/",
+					Path:       "projects/{project}/zones/{zone}/{+parentResource}/reservationSubBlocks/{resource}/testIamPermissions",
+				},
 			},
 		},
 	},
@@ -9782,6 +11622,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "projects/{project}/zones/{zone}/reservations/{resource}/setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://compute.googleapis.com/compute/v1/",
+					Path:       "projects/{project}/zones/{zone}/reservations/{resource}/testIamPermissions",
+				},
 			},
 		},
 	},
@@ -9834,6 +11679,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "projects/{project}/zones/{zone}/reservations/{parentResource}/reservationBlocks/{resource}/setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://compute.googleapis.com/compute/v1/",
+					Path:       "projects/{project}/zones/{zone}/reservations/{parentResource}/reservationBlocks/{resource}/testIamPermissions",
+				},
 			},
 		},
 	},
@@ -9907,6 +11757,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "projects/{project}/zones/{zone}/storagePools/{resource}/setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://compute.googleapis.com/compute/v1/",
+					Path:       "projects/{project}/zones/{zone}/storagePools/{resource}/testIamPermissions",
+				},
 			},
 		},
 	},
@@ -9930,6 +11785,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1alpha1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://containeranalysis.googleapis.com/",
+					Path:       "v1alpha1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -9953,6 +11813,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://servicemanagement.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -9976,6 +11841,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v1/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://servicemanagement.googleapis.com/",
+					Path:       "v1/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -9999,6 +11869,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v3/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://cloudresourcemanager.googleapis.com/",
+					Path:       "v3/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
@@ -10022,6 +11897,11 @@ var generatedResources = map[string]map[string]map[string]RestResource{
 					Path:          "v3/{+resource}:setIamPolicy",
 					RequestFormat: `{"policy": %s}`,
 				},
+				TestPermissionsMethod: RestMethod{
+					HttpMethod: "POST",
+					BaseURL:    "https://cloudresourcemanager.googleapis.com/",
+					Path:       "v3/{+resource}:testIamPermissions",
+				},
 			},
 		},
 	},
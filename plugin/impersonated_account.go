@@ -7,6 +7,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
+	"slices"
+	"strings"
 
 	"github.com/hashicorp/go-gcp-common/gcputil"
 	"github.com/hashicorp/go-multierror"
@@ -36,8 +39,20 @@ type ImpersonatedAccount struct {
 	Name string
 	gcputil.ServiceAccountId
 
-	TokenScopes []string
-	Ttl         int
+	TokenScopes      []string
+	Ttl              int
+	AllowedAudiences []string
+
+	// Delegates is the chain of service accounts to impersonate, in order,
+	// to reach the target service account.
+	Delegates []string
+	// Subject is the user to act as through domain-wide delegation.
+	Subject string
+	// AllowedSubjects are the glob patterns that token requests may override Subject with.
+	AllowedSubjects []string
+
+	// AccessBoundary, if set, downscopes access tokens generated under this account.
+	AccessBoundary *AccessBoundary
 }
 
 func (a *ImpersonatedAccount) validate() error {
@@ -54,9 +69,45 @@ func (a *ImpersonatedAccount) validate() error {
 		err = multierror.Append(err, fmt.Errorf("access token impersonated account should have defined scopes"))
 	}
 
+	seen := make(map[string]struct{}, len(a.Delegates))
+	for _, delegate := range a.Delegates {
+		if !strings.Contains(delegate, "@") {
+			err = multierror.Append(err, fmt.Errorf("delegate %q must be a service account email", delegate))
+		}
+		if delegate == a.EmailOrId {
+			err = multierror.Append(err, fmt.Errorf("delegate %q cannot be the impersonated service account", delegate))
+		}
+		if _, ok := seen[delegate]; ok {
+			err = multierror.Append(err, fmt.Errorf("delegate %q is listed more than once", delegate))
+		}
+		seen[delegate] = struct{}{}
+	}
+
+	if a.Subject != "" && !strings.Contains(a.Subject, "@") {
+		err = multierror.Append(err, fmt.Errorf("subject %q must be a user email", a.Subject))
+	}
+
+	for _, pattern := range a.AllowedSubjects {
+		if pattern == "" {
+			err = multierror.Append(err, errors.New("allowed_subjects cannot contain empty patterns"))
+		}
+	}
+
 	return err.ErrorOrNil()
 }
 
+// subjectForRequest returns the subject to use for a token request. The requested subject
+// may only override the configured subject if it matches one of the allowed subject patterns.
+func (a *ImpersonatedAccount) subjectForRequest(requested string) (string, error) {
+	if requested == "" || requested == a.Subject {
+		return a.Subject, nil
+	}
+	if !strutil.StrListContainsGlob(a.AllowedSubjects, requested) {
+		return "", fmt.Errorf("subject %q is not allowed for impersonated account %q", requested, a.Name)
+	}
+	return requested, nil
+}
+
 // parseOkInputServiceAccountEmail checks that when creating a static account, a service account
 // email is provided. A service account email can be provided while updating the static account
 // but it must be the same as the one in the static account and cannot be updated.
@@ -135,6 +186,11 @@ func (b *backend) createImpersonatedAccount(ctx context.Context, req *logical.Re
 		ServiceAccountId: acctId,
 		TokenScopes:      input.TokenScopes,
 		Ttl:              input.Ttl,
+		AllowedAudiences: input.AllowedAudiences,
+		Delegates:        input.Delegates,
+		Subject:          input.Subject,
+		AllowedSubjects:  input.AllowedSubjects,
+		AccessBoundary:   input.AccessBoundary,
 	}
 
 	// Save to storage.
@@ -172,6 +228,37 @@ func (b *backend) updateImpersonatedAccount(ctx context.Context, req *logical.Re
 		madeChange = true
 	}
 
+	if !strutil.EquivalentSlices(updateInput.AllowedAudiences, a.AllowedAudiences) {
+		b.Logger().Debug("detected allowed audiences change, updating allowed audiences for impersonated account")
+		a.AllowedAudiences = updateInput.AllowedAudiences
+		madeChange = true
+	}
+
+	// Delegation chains are ordered, so compare them as is.
+	if !slices.Equal(updateInput.Delegates, a.Delegates) {
+		b.Logger().Debug("detected delegates change, updating delegates for impersonated account")
+		a.Delegates = updateInput.Delegates
+		madeChange = true
+	}
+
+	if updateInput.Subject != a.Subject {
+		b.Logger().Debug("detected subject change, updating subject for impersonated account")
+		a.Subject = updateInput.Subject
+		madeChange = true
+	}
+
+	if !strutil.EquivalentSlices(updateInput.AllowedSubjects, a.AllowedSubjects) {
+		b.Logger().Debug("detected allowed subjects change, updating allowed subjects for impersonated account")
+		a.AllowedSubjects = updateInput.AllowedSubjects
+		madeChange = true
+	}
+
+	if !reflect.DeepEqual(updateInput.AccessBoundary, a.AccessBoundary) {
+		b.Logger().Debug("detected access boundary change, updating access boundary for impersonated account")
+		a.AccessBoundary = updateInput.AccessBoundary
+		madeChange = true
+	}
+
 	if !madeChange {
 		return nil, nil
 	}
@@ -179,6 +266,7 @@ func (b *backend) updateImpersonatedAccount(ctx context.Context, req *logical.Re
 	if err := a.save(ctx, req.Storage); err != nil {
 		return nil, err
 	}
+	b.tokenCache.ExpireAccount(a.EmailOrId)
 
 	return
 }
@@ -0,0 +1,130 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"testing"
+
+	"github.com/hashicorp/go-gcp-common/gcputil"
+)
+
+func Test_ImpersonatedAccountValidate(t *testing.T) {
+	base := func() *ImpersonatedAccount {
+		return &ImpersonatedAccount{
+			Name:             "test",
+			ServiceAccountId: gcputil.ServiceAccountId{EmailOrId: "target@project.iam.gserviceaccount.com"},
+			TokenScopes:      []string{"https://www.googleapis.com/auth/cloud-platform"},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(a *ImpersonatedAccount)
+		wantErr bool
+	}{
+		{
+			name:   "no delegates or subject",
+			modify: func(a *ImpersonatedAccount) {},
+		},
+		{
+			name: "valid delegates and subject",
+			modify: func(a *ImpersonatedAccount) {
+				a.Delegates = []string{"hop-a@project.iam.gserviceaccount.com", "hop-b@project.iam.gserviceaccount.com"}
+				a.Subject = "admin@example.com"
+				a.AllowedSubjects = []string{"*@example.com"}
+			},
+		},
+		{
+			name: "delegate is not an email",
+			modify: func(a *ImpersonatedAccount) {
+				a.Delegates = []string{"hop-a"}
+			},
+			wantErr: true,
+		},
+		{
+			name: "delegate is the target account",
+			modify: func(a *ImpersonatedAccount) {
+				a.Delegates = []string{"target@project.iam.gserviceaccount.com"}
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate delegates",
+			modify: func(a *ImpersonatedAccount) {
+				a.Delegates = []string{"hop-a@project.iam.gserviceaccount.com", "hop-a@project.iam.gserviceaccount.com"}
+			},
+			wantErr: true,
+		},
+		{
+			name: "subject is not an email",
+			modify: func(a *ImpersonatedAccount) {
+				a.Subject = "admin"
+			},
+			wantErr: true,
+		},
+		{
+			name: "empty allowed subject pattern",
+			modify: func(a *ImpersonatedAccount) {
+				a.AllowedSubjects = []string{""}
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := base()
+			tt.modify(a)
+			err := a.validate()
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_ImpersonatedAccountSubjectForRequest(t *testing.T) {
+	a := &ImpersonatedAccount{
+		Name:            "test",
+		Subject:         "admin@example.com",
+		AllowedSubjects: []string{"*@example.com"},
+	}
+
+	tests := []struct {
+		name      string
+		requested string
+		want      string
+		wantErr   bool
+	}{
+		{
+			name: "no requested subject uses configured subject",
+			want: "admin@example.com",
+		},
+		{
+			name:      "configured subject is always allowed",
+			requested: "admin@example.com",
+			want:      "admin@example.com",
+		},
+		{
+			name:      "requested subject matches allowed subjects",
+			requested: "user@example.com",
+			want:      "user@example.com",
+		},
+		{
+			name:      "requested subject does not match allowed subjects",
+			requested: "user@example.org",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := a.subjectForRequest(tt.requested)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("subjectForRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("subjectForRequest() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/go-secure-stdlib/strutil"
+	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/iamutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	jitGrantConditionTitleTmpl = "vault-jit-%s"
+	jitGrantConditionDescTmpl  = "Temporary grant %s issued by Vault JIT grant role %q"
+	jitGrantConditionExprTmpl  = `request.time < timestamp("%s")`
+
+	// Limits on IAM Condition fields.
+	conditionTitleMaxLen       = 100
+	conditionDescriptionMaxLen = 256
+)
+
+// jitGrantMemberTypes are the IAM member types that can be granted temporary access.
+var jitGrantMemberTypes = []string{"user", "group", "serviceAccount"}
+
+// JitGrantRole grants a caller-specified principal time-bound access to a set of bindings.
+// Each grant is an IAM binding with a condition that expires with the lease, so access
+// ends even if the lease is never revoked.
+type JitGrantRole struct {
+	Name string
+
+	RawBindings string
+	Bindings    ResourceBindings
+
+	// AllowedPrincipals are the glob patterns, e.g. "user:*@example.com", that principals
+	// requesting a grant must match.
+	AllowedPrincipals []string
+
+	// Ttl and MaxTtl are the default and maximum grant lifetimes in seconds.
+	// If unset, the mount's TTLs are used.
+	Ttl    int
+	MaxTtl int
+}
+
+func (b *backend) getJitGrantRole(name string, ctx context.Context, s logical.Storage) (*JitGrantRole, error) {
+	b.Logger().Debug("getting JIT grant role from storage", "jit_grant_role_name", name)
+	entry, err := s.Get(ctx, fmt.Sprintf("%s/%s", jitGrantStoragePrefix, name))
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	r := &JitGrantRole{}
+	if err := entry.DecodeJSON(r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *JitGrantRole) validate() error {
+	err := &multierror.Error{}
+	if r.Name == "" {
+		err = multierror.Append(err, errors.New("JIT grant role name is empty"))
+	}
+
+	if len(r.Bindings) == 0 {
+		err = multierror.Append(err, errors.New("JIT grant role must have at least one binding"))
+	}
+
+	if len(r.AllowedPrincipals) == 0 {
+		err = multierror.Append(err, errors.New("JIT grant role must have at least one allowed_principals pattern"))
+	}
+	for _, pattern := range r.AllowedPrincipals {
+		if pattern == "" {
+			err = multierror.Append(err, errors.New("allowed_principals cannot contain empty patterns"))
+		}
+	}
+
+	if r.Ttl < 0 || r.MaxTtl < 0 {
+		err = multierror.Append(err, errors.New("ttl and max_ttl cannot be negative"))
+	}
+	if r.MaxTtl > 0 && r.Ttl > r.MaxTtl {
+		err = multierror.Append(err, errors.New("ttl cannot be greater than max_ttl"))
+	}
+	return err.ErrorOrNil()
+}
+
+func (r *JitGrantRole) save(ctx context.Context, s logical.Storage) error {
+	if err := r.validate(); err != nil {
+		return err
+	}
+
+	entry, err := logical.StorageEntryJSON(fmt.Sprintf("%s/%s", jitGrantStoragePrefix, r.Name), r)
+	if err != nil {
+		return err
+	}
+
+	return s.Put(ctx, entry)
+}
+
+// checkPrincipal returns an error if the principal is not an IAM member this role may grant access to.
+func (r *JitGrantRole) checkPrincipal(principal string) error {
+	memberType, email, ok := strings.Cut(principal, ":")
+	if !ok || !strutil.StrListContains(jitGrantMemberTypes, memberType) || !strings.Contains(email, "@") {
+		return fmt.Errorf("principal %q must be of the form <type>:<email>, where type is one of %s", principal, strings.Join(jitGrantMemberTypes, ", "))
+	}
+	if !strutil.StrListContainsGlob(r.AllowedPrincipals, principal) {
+		return fmt.Errorf("principal %q is not allowed for JIT grant role %q", principal, r.Name)
+	}
+	return nil
+}
+
+// grantCondition returns the IAM Condition for a grant that expires at the given time.
+// The grant ID is included so the bindings of separate grants can be told apart.
+func (r *JitGrantRole) grantCondition(grantId string, expiry time.Time) *iamutil.Condition {
+	return &iamutil.Condition{
+		Title:       truncate(fmt.Sprintf(jitGrantConditionTitleTmpl, r.Name), conditionTitleMaxLen),
+		Description: truncate(fmt.Sprintf(jitGrantConditionDescTmpl, grantId, r.Name), conditionDescriptionMaxLen),
+		Expression:  fmt.Sprintf(jitGrantConditionExprTmpl, expiry.UTC().Format(time.RFC3339)),
+	}
+}
+
+func truncate(s string, maxLen int) string {
+	if len(s) > maxLen {
+		return s[:maxLen]
+	}
+	return s
+}
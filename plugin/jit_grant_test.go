@@ -0,0 +1,145 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/util"
+	"github.com/mitchellh/mapstructure"
+)
+
+func Test_JitGrantRoleCheckPrincipal(t *testing.T) {
+	r := &JitGrantRole{
+		Name:              "oncall",
+		AllowedPrincipals: []string{"user:*@example.com", "group:oncall@example.com"},
+	}
+
+	tests := []struct {
+		principal string
+		wantErr   bool
+	}{
+		{principal: "user:alice@example.com"},
+		{principal: "group:oncall@example.com"},
+		{principal: "user:alice@other.com", wantErr: true},
+		{principal: "group:admins@example.com", wantErr: true},
+		{principal: "alice@example.com", wantErr: true},
+		{principal: "domain:example.com", wantErr: true},
+		{principal: "user:", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.principal, func(t *testing.T) {
+			err := r.checkPrincipal(tt.principal)
+			if tt.wantErr && err == nil {
+				t.Fatal("expected error")
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func Test_JitGrantRoleValidate(t *testing.T) {
+	base := func() *JitGrantRole {
+		return &JitGrantRole{
+			Name: "oncall",
+			Bindings: ResourceBindings{
+				"//cloudresourcemanager.googleapis.com/projects/project": util.ToSet([]string{"roles/viewer"}),
+			},
+			AllowedPrincipals: []string{"user:*@example.com"},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(r *JitGrantRole)
+		wantErr bool
+	}{
+		{
+			name:   "valid",
+			modify: func(r *JitGrantRole) {},
+		},
+		{
+			name: "no bindings",
+			modify: func(r *JitGrantRole) {
+				r.Bindings = nil
+			},
+			wantErr: true,
+		},
+		{
+			name: "no allowed principals",
+			modify: func(r *JitGrantRole) {
+				r.AllowedPrincipals = nil
+			},
+			wantErr: true,
+		},
+		{
+			name: "ttl greater than max_ttl",
+			modify: func(r *JitGrantRole) {
+				r.Ttl = 7200
+				r.MaxTtl = 3600
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := base()
+			tt.modify(r)
+			err := r.validate()
+			if tt.wantErr && err == nil {
+				t.Fatal("expected error")
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func Test_JitGrantInternalDataRoundTrip(t *testing.T) {
+	r := &JitGrantRole{Name: "oncall"}
+	expiry := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	grant := jitGrant{
+		Member:    "user:alice@example.com",
+		Condition: *r.grantCondition("grant-id", expiry),
+		Bindings: map[string][]string{
+			"//cloudresourcemanager.googleapis.com/projects/project": {"roles/viewer"},
+		},
+	}
+
+	if expected := `request.time < timestamp("2030-01-02T03:04:05Z")`; grant.Condition.Expression != expected {
+		t.Fatalf("expected condition expression %q, got %q", expected, grant.Condition.Expression)
+	}
+
+	// Secret internal data is stored as JSON, so make sure the grant survives being decoded from it.
+	internalD := map[string]interface{}{
+		"jit_grant_role": r.Name,
+		"member":         grant.Member,
+		"condition":      grant.Condition,
+		"bindings":       grant.Bindings,
+	}
+	raw, err := json.Marshal(internalD)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stored map[string]interface{}
+	if err := json.Unmarshal(raw, &stored); err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded jitGrant
+	if err := mapstructure.Decode(stored, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, grant) {
+		t.Fatalf("expected grant %+v, got %+v", grant, decoded)
+	}
+}
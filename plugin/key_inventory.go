@@ -0,0 +1,275 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/go-gcp-common/gcputil"
+	"github.com/hashicorp/vault/sdk/logical"
+	"google.golang.org/api/iam/v1"
+)
+
+const (
+	// leasedKeyIndexPrefix is the storage prefix of the index of service account keys owned by
+	// leases. Leases are local to a cluster, so the index is in local storage.
+	leasedKeyIndexPrefix = "leased-key-index"
+
+	// maxUserManagedKeys is GCP's limit of user-managed keys per service account.
+	maxUserManagedKeys = 10
+
+	// userManagedKeysWarnThreshold is the number of user-managed keys at which to warn about
+	// approaching the limit.
+	userManagedKeysWarnThreshold = maxUserManagedKeys - 2
+
+	keyStatusTokenGenerator = "token_generator"
+	keyStatusCurrent        = "current"
+	keyStatusPrevious       = "previous"
+	keyStatusLeased         = "leased"
+	keyStatusUnknown        = "unknown"
+)
+
+// leasedKey is an entry in the leased key index.
+type leasedKey struct {
+	KeyName   string    `json:"key_name"`
+	IssueTime time.Time `json:"issue_time"`
+}
+
+// leasedKeyIndexParent returns the leased key index prefix of a role set or static account.
+func leasedKeyIndexParent(parentPrefix, name string) string {
+	return path.Join(leasedKeyIndexPrefix, parentPrefix, name) + "/"
+}
+
+// leasedKeyIndexParentForSecret returns the leased key index prefix of the role set or static
+// account a secret was generated for, from the secret's internal data.
+func leasedKeyIndexParentForSecret(internalData map[string]interface{}) (string, bool) {
+	if v, ok := internalData["role_set"].(string); ok {
+		return leasedKeyIndexParent(rolesetStoragePrefix, v), true
+	}
+	if v, ok := internalData["static_account"].(string); ok {
+		return leasedKeyIndexParent(staticAccountStoragePrefix, v), true
+	}
+	return "", false
+}
+
+// keyId returns the ID of a key from its full resource name.
+func keyId(keyName string) string {
+	return path.Base(keyName)
+}
+
+func indexLeasedKey(ctx context.Context, s logical.Storage, internalData map[string]interface{}, keyName string) error {
+	parent, ok := leasedKeyIndexParentForSecret(internalData)
+	if !ok {
+		return nil
+	}
+	entry, err := logical.StorageEntryJSON(parent+keyId(keyName), &leasedKey{
+		KeyName:   keyName,
+		IssueTime: time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+	return s.Put(ctx, entry)
+}
+
+func unindexLeasedKey(ctx context.Context, s logical.Storage, internalData map[string]interface{}, keyName string) error {
+	parent, ok := leasedKeyIndexParentForSecret(internalData)
+	if !ok {
+		return nil
+	}
+	return s.Delete(ctx, parent+keyId(keyName))
+}
+
+// leasedKeys returns the indexed leased keys under the given index prefix by key name.
+func leasedKeys(ctx context.Context, s logical.Storage, parent string) (map[string]*leasedKey, error) {
+	ids, err := s.List(ctx, parent)
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string]*leasedKey, len(ids))
+	for _, id := range ids {
+		entry, err := s.Get(ctx, parent+id)
+		if err != nil {
+			return nil, err
+		}
+		if entry == nil {
+			continue
+		}
+		lk := &leasedKey{}
+		if err := entry.DecodeJSON(lk); err != nil {
+			return nil, err
+		}
+		keys[lk.KeyName] = lk
+	}
+	return keys, nil
+}
+
+// keyInventoryEntry is a user-managed key on a service account annotated with what owns it.
+type keyInventoryEntry struct {
+	Key      *iam.ServiceAccountKey
+	Status   string
+	LeaseKey *leasedKey
+}
+
+func (e *keyInventoryEntry) asOutput() map[string]interface{} {
+	out := map[string]interface{}{
+		"key_name":          e.Key.Name,
+		"status":            e.Status,
+		"key_origin":        e.Key.KeyOrigin,
+		"key_algorithm":     e.Key.KeyAlgorithm,
+		"valid_after_time":  e.Key.ValidAfterTime,
+		"valid_before_time": e.Key.ValidBeforeTime,
+		"disabled":          e.Key.Disabled,
+	}
+	if e.LeaseKey != nil {
+		out["lease_issue_time"] = e.LeaseKey.IssueTime.Format(time.RFC3339)
+	}
+	return out
+}
+
+// ownedKeys maps the names of the keys a role set or static account keeps in storage to their
+// inventory status.
+func ownedKeys(tokenGen *TokenGenerator, rk *RotatingKey) map[string]string {
+	owned := make(map[string]string)
+	if tokenGen != nil {
+		owned[tokenGen.KeyName] = keyStatusTokenGenerator
+	}
+	if rk != nil {
+		if rk.Current != nil {
+			owned[rk.Current.KeyName] = keyStatusCurrent
+		}
+		if rk.Previous != nil {
+			owned[rk.Previous.KeyName] = keyStatusPrevious
+		}
+	}
+	return owned
+}
+
+// keyInventory lists the user-managed keys of a service account and annotates each with
+// whether it is owned by the role set or static account, owned by a lease, or unknown.
+func (b *backend) keyInventory(ctx context.Context, s logical.Storage, accountId *gcputil.ServiceAccountId, owned map[string]string, indexParent string) ([]*keyInventoryEntry, error) {
+	iamAdmin, err := b.IAMAdminClient(s)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := iamAdmin.Projects.ServiceAccounts.Keys.List(accountId.ResourceName()).KeyTypes("USER_MANAGED").Context(ctx).Do()
+	if err != nil {
+		return nil, errwrap.Wrapf(fmt.Sprintf("unable to list keys of service account %q: {{err}}", accountId.EmailOrId), err)
+	}
+
+	leased, err := leasedKeys(ctx, s, indexParent)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*keyInventoryEntry, 0, len(resp.Keys))
+	for _, key := range resp.Keys {
+		entry := &keyInventoryEntry{Key: key, Status: keyStatusUnknown}
+		if status, ok := owned[key.Name]; ok {
+			entry.Status = status
+		} else if lk, ok := leased[key.Name]; ok {
+			entry.Status = keyStatusLeased
+			entry.LeaseKey = lk
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Key.ValidAfterTime < entries[j].Key.ValidAfterTime
+	})
+	return entries, nil
+}
+
+// keyInventoryResponse returns the inventory as a list response keyed by key ID. If accountId is
+// set, a warning is added if the account is close to the key limit.
+func keyInventoryResponse(accountId *gcputil.ServiceAccountId, entries []*keyInventoryEntry) *logical.Response {
+	keys := make([]string, 0, len(entries))
+	keyInfo := make(map[string]interface{}, len(entries))
+	for _, e := range entries {
+		id := keyId(e.Key.Name)
+		keys = append(keys, id)
+		keyInfo[id] = e.asOutput()
+	}
+	resp := logical.ListResponseWithInfo(keys, keyInfo)
+	if accountId != nil {
+		if w := keyLimitWarning(accountId, len(entries)); w != "" {
+			resp.AddWarning(w)
+		}
+	}
+	return resp
+}
+
+// keyLimitWarning returns a warning if a service account with the given number of
+// user-managed keys is close to GCP's limit.
+func keyLimitWarning(accountId *gcputil.ServiceAccountId, count int) string {
+	if count < userManagedKeysWarnThreshold {
+		return ""
+	}
+	return fmt.Sprintf("service account %q has %d of the maximum %d user-managed keys; new keys can't be created once the limit is reached", accountId.EmailOrId, count, maxUserManagedKeys)
+}
+
+// pruneKeys deletes the unknown keys in the inventory. Keys younger than the mount's max lease
+// TTL may belong to leases that are not indexed, either because they were issued before the
+// index existed or on another cluster, so they are skipped.
+func (b *backend) pruneKeys(ctx context.Context, s logical.Storage, entries []*keyInventoryEntry) (pruned []string, skipped []string, err error) {
+	iamAdmin, err := b.IAMAdminClient(s)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	minAge := b.System().MaxLeaseTTL()
+	for _, e := range entries {
+		if e.Status != keyStatusUnknown {
+			continue
+		}
+		created, err := time.Parse(time.RFC3339, e.Key.ValidAfterTime)
+		if err != nil || time.Since(created) < minAge {
+			skipped = append(skipped, e.Key.Name)
+			continue
+		}
+
+		b.Logger().Info("pruning unknown service account key", "key", e.Key.Name)
+		_, err = iamAdmin.Projects.ServiceAccounts.Keys.Delete(e.Key.Name).Context(ctx).Do()
+		if err != nil && !isGoogleAccountKeyNotFoundErr(err) {
+			return pruned, skipped, errwrap.Wrapf(fmt.Sprintf("unable to delete key %q: {{err}}", e.Key.Name), err)
+		}
+		pruned = append(pruned, e.Key.Name)
+	}
+	return pruned, skipped, nil
+}
+
+// selectPruneKeys returns the entries of the given keys, by key ID or full resource name. It
+// returns an error if a key is not an unknown key of the inventory.
+func selectPruneKeys(entries []*keyInventoryEntry, keys []string) ([]*keyInventoryEntry, error) {
+	byId := make(map[string]*keyInventoryEntry, len(entries))
+	for _, e := range entries {
+		byId[keyId(e.Key.Name)] = e
+	}
+
+	selected := make([]*keyInventoryEntry, 0, len(keys))
+	for _, k := range keys {
+		e, ok := byId[keyId(k)]
+		if !ok {
+			return nil, fmt.Errorf("key %q not found on the service account", k)
+		}
+		if e.Status != keyStatusUnknown {
+			return nil, fmt.Errorf("key %q is owned by Vault (%s) and cannot be pruned", k, e.Status)
+		}
+		selected = append(selected, e)
+	}
+	return selected, nil
+}
+
+// countUserManagedKeys returns the number of user-managed keys of a service account.
+func countUserManagedKeys(ctx context.Context, iamAdmin *iam.Service, accountId *gcputil.ServiceAccountId) (int, error) {
+	resp, err := iamAdmin.Projects.ServiceAccounts.Keys.List(accountId.ResourceName()).KeyTypes("USER_MANAGED").Context(ctx).Do()
+	if err != nil {
+		return 0, err
+	}
+	return len(resp.Keys), nil
+}
@@ -0,0 +1,101 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/go-gcp-common/gcputil"
+	"github.com/hashicorp/vault/sdk/logical"
+	"google.golang.org/api/iam/v1"
+)
+
+func Test_LeasedKeyIndex(t *testing.T) {
+	ctx := context.Background()
+	s := &logical.InmemStorage{}
+
+	keyName := "projects/project/serviceAccounts/sa@project.iam.gserviceaccount.com/keys/0123abcd"
+	rsData := map[string]interface{}{"role_set": "my-role", "role_set_bindings": "hash"}
+	saData := map[string]interface{}{"static_account": "my-role", "static_account_bindings": "hash"}
+
+	if err := indexLeasedKey(ctx, s, rsData, keyName); err != nil {
+		t.Fatal(err)
+	}
+
+	rsKeys, err := leasedKeys(ctx, s, leasedKeyIndexParent(rolesetStoragePrefix, "my-role"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lk, ok := rsKeys[keyName]; !ok || lk.IssueTime.IsZero() {
+		t.Fatalf("expected key %q to be indexed for role set, got %v", keyName, rsKeys)
+	}
+
+	// A static account with the same name has its own index.
+	saKeys, err := leasedKeys(ctx, s, leasedKeyIndexParent(staticAccountStoragePrefix, "my-role"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saKeys) != 0 {
+		t.Fatalf("expected no keys indexed for static account, got %v", saKeys)
+	}
+	if err := unindexLeasedKey(ctx, s, saData, keyName); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := unindexLeasedKey(ctx, s, rsData, keyName); err != nil {
+		t.Fatal(err)
+	}
+	rsKeys, err = leasedKeys(ctx, s, leasedKeyIndexParent(rolesetStoragePrefix, "my-role"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rsKeys) != 0 {
+		t.Fatalf("expected key to be removed from index, got %v", rsKeys)
+	}
+
+	// Secrets without a parent are not indexed.
+	if err := indexLeasedKey(ctx, s, map[string]interface{}{}, keyName); err != nil {
+		t.Fatal(err)
+	}
+	if all, err := s.List(ctx, leasedKeyIndexPrefix+"/"); err != nil || len(all) != 0 {
+		t.Fatalf("expected empty index, got %v (err: %v)", all, err)
+	}
+}
+
+func Test_KeyLimitWarning(t *testing.T) {
+	id := &gcputil.ServiceAccountId{Project: "project", EmailOrId: "sa@project.iam.gserviceaccount.com"}
+	if w := keyLimitWarning(id, userManagedKeysWarnThreshold-1); w != "" {
+		t.Fatalf("expected no warning below threshold, got %q", w)
+	}
+	if w := keyLimitWarning(id, userManagedKeysWarnThreshold); w == "" {
+		t.Fatal("expected warning at threshold")
+	}
+}
+
+func Test_SelectPruneKeys(t *testing.T) {
+	keyName := func(id string) string {
+		return "projects/project/serviceAccounts/sa@project.iam.gserviceaccount.com/keys/" + id
+	}
+	entries := []*keyInventoryEntry{
+		{Key: &iam.ServiceAccountKey{Name: keyName("unknown1")}, Status: keyStatusUnknown},
+		{Key: &iam.ServiceAccountKey{Name: keyName("unknown2")}, Status: keyStatusUnknown},
+		{Key: &iam.ServiceAccountKey{Name: keyName("leased")}, Status: keyStatusLeased},
+	}
+
+	selected, err := selectPruneKeys(entries, []string{"unknown1", keyName("unknown2")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(selected) != 2 || selected[0] != entries[0] || selected[1] != entries[1] {
+		t.Fatalf("expected both unknown keys to be selected, got %v", selected)
+	}
+
+	if _, err := selectPruneKeys(entries, []string{"leased"}); err == nil {
+		t.Fatal("expected error selecting a leased key")
+	}
+	if _, err := selectPruneKeys(entries, []string{"missing"}); err == nil {
+		t.Fatal("expected error selecting a missing key")
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-gcp-common/gcputil"
+	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/helper/locksutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// keyAccountEmail returns the email of the service account a key belongs to, from the key's full
+// resource name "projects/<project>/serviceAccounts/<email>/keys/<id>".
+func keyAccountEmail(keyName string) string {
+	parts := strings.Split(keyName, "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if parts[i] == "serviceAccounts" {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
+
+// usesKeyPool returns whether service_account_key secrets of the role set are sharded over a
+// pool of accounts.
+func (rs *RoleSet) usesKeyPool() bool {
+	return rs.SecretType == SecretTypeKey && (rs.MaxAccounts > 1 || len(rs.PoolAccounts) > 0)
+}
+
+// leastLoadedAccount returns the account with the fewest leased keys that has fewer than
+// keysPerAccount keys. Only the first maxAccounts accounts are considered, so accounts beyond
+// a lowered max_accounts are left to drain. It returns nil if all accounts are full.
+func leastLoadedAccount(accounts []*gcputil.ServiceAccountId, leased map[string]int, maxAccounts, keysPerAccount int) *gcputil.ServiceAccountId {
+	if maxAccounts < 1 {
+		maxAccounts = 1
+	}
+	var best *gcputil.ServiceAccountId
+	for i, id := range accounts {
+		if i >= maxAccounts {
+			break
+		}
+		count := leased[id.EmailOrId]
+		if count >= keysPerAccount {
+			continue
+		}
+		if best == nil || count < leased[best.EmailOrId] {
+			best = id
+		}
+	}
+	return best
+}
+
+// leasedKeysPerAccount returns the number of indexed leased keys of the role set by account email.
+func leasedKeysPerAccount(ctx context.Context, s logical.Storage, rsName string) (map[string]int, error) {
+	keys, err := leasedKeys(ctx, s, leasedKeyIndexParent(rolesetStoragePrefix, rsName))
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int)
+	for keyName := range keys {
+		counts[keyAccountEmail(keyName)]++
+	}
+	return counts, nil
+}
+
+// keyPoolLock returns the lock that serializes key issuance from the key pool of a role set.
+// It must be acquired before the role set lock.
+func (b *backend) keyPoolLock(rsName string) *locksutil.LockEntry {
+	return locksutil.LockForKey(b.keyPoolLocks, rsName)
+}
+
+// poolAccountForKey returns the pool account to create a new key on, adding an account to the
+// pool if all accounts are full. The role set's key pool lock must be held.
+func (b *backend) poolAccountForKey(ctx context.Context, req *logical.Request, rs *RoleSet) (*gcputil.ServiceAccountId, error) {
+	leased, err := leasedKeysPerAccount(ctx, req.Storage, rs.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	maxAccounts := rs.MaxAccounts
+	if id := leastLoadedAccount(rs.accounts(), leased, maxAccounts, rs.keysPerAccount()); id != nil {
+		return id, nil
+	}
+	if len(rs.accounts()) >= maxAccounts {
+		return nil, fmt.Errorf("all %d service accounts of role set %q have %d leased keys; revoke leases or increase max_accounts", len(rs.accounts()), rs.Name, rs.keysPerAccount())
+	}
+
+	// Only the primary can save the role set with a new account, so have the request forwarded.
+	if b.System().ReplicationState().HasState(consts.ReplicationPerformanceSecondary) {
+		return nil, logical.ErrReadOnly
+	}
+	return b.addPoolAccount(ctx, req, rs)
+}
+
+// addPoolAccount creates a service account with the role set's bindings and saves it to the pool.
+// The role set lock is only taken to save the account, so the GCP calls don't block other role sets.
+func (b *backend) addPoolAccount(ctx context.Context, req *logical.Request, rs *RoleSet) (*gcputil.ServiceAccountId, error) {
+	// Account names are unique per second, so wait for a name not in use by the role set.
+	saName := generateAccountNameForRoleSet(rs.Name)
+	for rs.usesAccount(gcputil.ServiceAccountId{Project: rs.AccountId.Project, EmailOrId: emailForServiceAccountName(rs.AccountId.Project, saName)}) {
+		time.Sleep(time.Second)
+		saName = generateAccountNameForRoleSet(rs.Name)
+	}
+
+	resources := &gcpAccountResources{
+		accountId: gcputil.ServiceAccountId{
+			Project:   rs.AccountId.Project,
+			EmailOrId: emailForServiceAccountName(rs.AccountId.Project, saName),
+		},
+		bindings:            rs.Bindings,
+		conditionalBindings: rs.ConditionalBindings,
+	}
+
+	b.Logger().Debug("adding service account to roleset key pool", "roleset", rs.Name, "service_account", resources.accountId.EmailOrId)
+	walIds, err := b.addWalsForRoleSetResources(ctx, req, rs.Name, resources)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := b.createRoleSetAccount(ctx, req, rs.Name, saName, resources); err != nil {
+		return nil, err
+	}
+
+	if err := b.savePoolAccount(ctx, req, rs.Name, rs.bindingHash(), &resources.accountId); err != nil {
+		if warnings := b.tryDeleteRoleSetResources(ctx, req, resources, walIds); len(warnings) > 0 {
+			b.Logger().Warn("unable to delete unsaved pool service account, WALs exist to clean up", "service_account", resources.accountId.EmailOrId, "errors", warnings)
+		}
+		return nil, err
+	}
+	b.tryDeleteWALs(ctx, req.Storage, walIds...)
+	return &resources.accountId, nil
+}
+
+// savePoolAccount adds an account to the key pool of the role set, unless the role set was
+// deleted or its bindings changed since the account was created.
+func (b *backend) savePoolAccount(ctx context.Context, req *logical.Request, rsName, bindingHash string, accountId *gcputil.ServiceAccountId) error {
+	b.rolesetLock.Lock()
+	defer b.rolesetLock.Unlock()
+
+	rs, err := getRoleSet(rsName, ctx, req.Storage)
+	if err != nil {
+		return err
+	}
+	if rs == nil || rs.bindingHash() != bindingHash {
+		return fmt.Errorf("role set %q was changed while adding a service account to its key pool", rsName)
+	}
+
+	rs.PoolAccounts = append(rs.PoolAccounts, accountId)
+	return rs.save(ctx, req.Storage)
+}
+
+// retireDrainedPoolAccount removes the pool account a revoked key belonged to once it has no
+// leased keys left. The role set's own service account is never retired.
+func (b *backend) retireDrainedPoolAccount(ctx context.Context, req *logical.Request, rsName, keyName string) error {
+	if b.System().ReplicationState().HasState(consts.ReplicationPerformanceSecondary | consts.ReplicationPerformanceStandby) {
+		return nil
+	}
+
+	poolLock := b.keyPoolLock(rsName)
+	poolLock.Lock()
+	defer poolLock.Unlock()
+
+	b.rolesetLock.Lock()
+	defer b.rolesetLock.Unlock()
+
+	rs, err := getRoleSet(rsName, ctx, req.Storage)
+	if err != nil || rs == nil {
+		return err
+	}
+
+	email := keyAccountEmail(keyName)
+	idx := -1
+	for i, id := range rs.PoolAccounts {
+		if id.EmailOrId == email {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return nil
+	}
+
+	leased, err := leasedKeysPerAccount(ctx, req.Storage, rs.Name)
+	if err != nil {
+		return err
+	}
+	if leased[email] > 0 {
+		return nil
+	}
+
+	resources := rs.poolResources()[idx]
+	walIds, err := b.addWalsForRoleSetResources(ctx, req, rs.Name, resources)
+	if err != nil {
+		return err
+	}
+
+	b.Logger().Debug("retiring drained service account from roleset key pool", "roleset", rs.Name, "service_account", email)
+	rs.PoolAccounts = append(rs.PoolAccounts[:idx], rs.PoolAccounts[idx+1:]...)
+	if err := rs.save(ctx, req.Storage); err != nil {
+		return err
+	}
+	if warnings := b.tryDeleteRoleSetResources(ctx, req, resources, walIds); len(warnings) > 0 {
+		b.Logger().Warn("unable to delete retired pool service account, WALs exist to clean up", "service_account", email, "errors", warnings)
+	}
+	return nil
+}
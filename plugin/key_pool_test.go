@@ -0,0 +1,215 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/go-gcp-common/gcputil"
+	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/iamutil"
+	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/util"
+)
+
+func Test_KeyAccountEmail(t *testing.T) {
+	keyName := "projects/project/serviceAccounts/sa@project.iam.gserviceaccount.com/keys/0123abcd"
+	if email := keyAccountEmail(keyName); email != "sa@project.iam.gserviceaccount.com" {
+		t.Fatalf("unexpected email %q", email)
+	}
+	if email := keyAccountEmail("keys/0123abcd"); email != "" {
+		t.Fatalf("expected no email, got %q", email)
+	}
+}
+
+func Test_LeastLoadedAccount(t *testing.T) {
+	accounts := []*gcputil.ServiceAccountId{
+		{Project: "project", EmailOrId: "a@project.iam.gserviceaccount.com"},
+		{Project: "project", EmailOrId: "b@project.iam.gserviceaccount.com"},
+		{Project: "project", EmailOrId: "c@project.iam.gserviceaccount.com"},
+	}
+
+	tests := map[string]struct {
+		leased         map[string]int
+		maxAccounts    int
+		keysPerAccount int
+		expected       string
+	}{
+		"no keys picks the first account": {
+			leased:         map[string]int{},
+			maxAccounts:    3,
+			keysPerAccount: 10,
+			expected:       "a@project.iam.gserviceaccount.com",
+		},
+		"picks the account with the fewest keys": {
+			leased: map[string]int{
+				"a@project.iam.gserviceaccount.com": 4,
+				"b@project.iam.gserviceaccount.com": 2,
+				"c@project.iam.gserviceaccount.com": 3,
+			},
+			maxAccounts:    3,
+			keysPerAccount: 10,
+			expected:       "b@project.iam.gserviceaccount.com",
+		},
+		"skips full accounts": {
+			leased: map[string]int{
+				"a@project.iam.gserviceaccount.com": 5,
+				"b@project.iam.gserviceaccount.com": 5,
+				"c@project.iam.gserviceaccount.com": 4,
+			},
+			maxAccounts:    3,
+			keysPerAccount: 5,
+			expected:       "c@project.iam.gserviceaccount.com",
+		},
+		"accounts beyond max_accounts drain": {
+			leased: map[string]int{
+				"a@project.iam.gserviceaccount.com": 3,
+				"b@project.iam.gserviceaccount.com": 3,
+			},
+			maxAccounts:    2,
+			keysPerAccount: 5,
+			expected:       "a@project.iam.gserviceaccount.com",
+		},
+		"all accounts full": {
+			leased: map[string]int{
+				"a@project.iam.gserviceaccount.com": 5,
+				"b@project.iam.gserviceaccount.com": 5,
+			},
+			maxAccounts:    2,
+			keysPerAccount: 5,
+			expected:       "",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			id := leastLoadedAccount(accounts, tt.leased, tt.maxAccounts, tt.keysPerAccount)
+			var actual string
+			if id != nil {
+				actual = id.EmailOrId
+			}
+			if actual != tt.expected {
+				t.Fatalf("expected %q, got %q", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_RoleSetKeyPoolValidate(t *testing.T) {
+	newRoleSet := func(secretType string, maxAccounts, keysPerAccount int) *RoleSet {
+		return &RoleSet{
+			Name:           "my-role",
+			SecretType:     secretType,
+			RawBindings:    "bindings",
+			AccountId:      &gcputil.ServiceAccountId{Project: "project", EmailOrId: "a@project.iam.gserviceaccount.com"},
+			Bindings:       ResourceBindings{"resource": {"roles/viewer": struct{}{}}},
+			MaxAccounts:    maxAccounts,
+			KeysPerAccount: keysPerAccount,
+		}
+	}
+
+	if err := newRoleSet(SecretTypeKey, 5, 8).validate(); err != nil {
+		t.Fatalf("expected valid role set, got %v", err)
+	}
+	if err := newRoleSet(SecretTypeKey, -1, 0).validate(); err == nil {
+		t.Fatal("expected error for negative max_accounts")
+	}
+	if err := newRoleSet(SecretTypeKey, 2, maxUserManagedKeys+1).validate(); err == nil {
+		t.Fatal("expected error for keys_per_account above the key limit")
+	}
+	if err := newRoleSet(SecretTypeHmacKey, 2, 0).validate(); err == nil {
+		t.Fatal("expected error for max_accounts on a non-key role set")
+	}
+
+	if !newRoleSet(SecretTypeKey, 2, 0).usesKeyPool() {
+		t.Fatal("expected role set with max_accounts to use key pool")
+	}
+	if newRoleSet(SecretTypeKey, 1, 0).usesKeyPool() {
+		t.Fatal("expected role set without max_accounts not to use key pool")
+	}
+}
+
+func testPoolRoleSet() *RoleSet {
+	return &RoleSet{
+		Name:      "pool",
+		AccountId: &gcputil.ServiceAccountId{Project: "project", EmailOrId: "a@project.iam.gserviceaccount.com"},
+		PoolAccounts: []*gcputil.ServiceAccountId{
+			{Project: "project", EmailOrId: "b@project.iam.gserviceaccount.com"},
+			{Project: "project", EmailOrId: "c@project.iam.gserviceaccount.com"},
+		},
+		Bindings: ResourceBindings{
+			"//cloudresourcemanager.googleapis.com/projects/project": util.ToSet([]string{"roles/viewer"}),
+		},
+		MaxAccounts: 3,
+	}
+}
+
+func Test_RoleSetRotationPlanPoolAccounts(t *testing.T) {
+	rs := testPoolRoleSet()
+	newBinds := ResourceBindings{
+		"//cloudresourcemanager.googleapis.com/projects/project": util.ToSet([]string{"roles/editor"}),
+	}
+
+	planned, deleted := roleSetRotationPlan(rs, "new@project.iam.gserviceaccount.com", newBinds, nil)
+
+	expectedDeleted := []string{
+		"a@project.iam.gserviceaccount.com",
+		"b@project.iam.gserviceaccount.com",
+		"c@project.iam.gserviceaccount.com",
+	}
+	if !reflect.DeepEqual(deleted, expectedDeleted) {
+		t.Fatalf("expected deleted accounts %v, got %v", expectedDeleted, deleted)
+	}
+
+	removed := make(map[string]bool)
+	for _, pb := range planned {
+		if len(pb.remove) > 0 {
+			removed[pb.member] = true
+		}
+	}
+	for _, email := range expectedDeleted {
+		if member := fmt.Sprintf(iamutil.ServiceAccountMemberTmpl, email); !removed[member] {
+			t.Fatalf("expected bindings of %q to be removed", member)
+		}
+	}
+}
+
+func Test_RoleSetInPlacePlanPoolAccounts(t *testing.T) {
+	rs := testPoolRoleSet()
+	rs.UpdateMode = updateModeInPlace
+	newBinds := ResourceBindings{
+		"//cloudresourcemanager.googleapis.com/projects/project": util.ToSet([]string{"roles/viewer", "roles/editor"}),
+	}
+
+	added := make(map[string]bool)
+	for _, pb := range roleSetInPlacePlan(rs, newBinds, nil) {
+		if len(pb.add) > 0 {
+			added[pb.member] = true
+		}
+	}
+	for _, id := range rs.accounts() {
+		if member := fmt.Sprintf(iamutil.ServiceAccountMemberTmpl, id.EmailOrId); !added[member] {
+			t.Fatalf("expected bindings to be added to %q", member)
+		}
+	}
+}
+
+func Test_DriftReportPoolAccounts(t *testing.T) {
+	report := &driftReport{Parent: driftParentRoleSet, Name: "pool"}
+	poolReport := &driftReport{Parent: driftParentRoleSet, Name: "pool", ServiceAccountMissing: true}
+	report.PoolAccounts = append(report.PoolAccounts, poolReport)
+
+	if report.hasAccountDrift() {
+		t.Fatal("expected role set service account to have no drift")
+	}
+	if !report.hasDrift() {
+		t.Fatal("expected drift of a pool account to be reported for the role set")
+	}
+	if n := len(report.accountReports()); n != 2 {
+		t.Fatalf("expected 2 account reports, got %d", n)
+	}
+	if out := report.asOutput()["pool_accounts"].([]map[string]interface{}); len(out) != 1 || out[0]["service_account_missing"] != true {
+		t.Fatalf("unexpected pool accounts output %v", out)
+	}
+}
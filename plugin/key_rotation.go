@@ -0,0 +1,213 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/automatedrotationutil"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/hashicorp/vault/sdk/rotation"
+	"github.com/robfig/cron/v3"
+)
+
+// maxTokenKeyRotationPeriod is the longest rotation period that keeps token generator keys
+// within the common 90 day key age requirement.
+const maxTokenKeyRotationPeriod = 90 * 24 * time.Hour
+
+// rotateCredential is called by Vault's rotation manager with the path the rotation job was
+// registered from: config for the root credential, or a role set or static account path for its
+// token generator key.
+func (b *backend) rotateCredential(ctx context.Context, req *logical.Request) error {
+	switch {
+	case strings.HasPrefix(req.Path, rolesetStoragePrefix+"/"):
+		return b.rotateRoleSetTokenKey(ctx, req, strings.TrimPrefix(req.Path, rolesetStoragePrefix+"/"))
+	case strings.HasPrefix(req.Path, staticAccountPathPrefix+"/"):
+		return b.rotateStaticAccountTokenKey(ctx, req, strings.TrimPrefix(req.Path, staticAccountPathPrefix+"/"))
+	default:
+		return b.rotateRootCredential(ctx, req)
+	}
+}
+
+func (b *backend) rotateRoleSetTokenKey(ctx context.Context, req *logical.Request, name string) error {
+	b.rolesetLock.Lock()
+	defer b.rolesetLock.Unlock()
+
+	rs, err := getRoleSet(name, ctx, req.Storage)
+	if err != nil {
+		return err
+	}
+	if rs == nil {
+		return fmt.Errorf("role set %q not found", name)
+	}
+	if rs.SecretType != SecretTypeAccessToken {
+		return fmt.Errorf("cannot rotate key for non-access-token role set %q", name)
+	}
+
+	b.Logger().Info("rotating roleset token key", "roleset", name)
+	warn, err := b.saveRoleSetWithNewTokenKey(ctx, req, rs, rs.tokenScopes())
+	if err != nil {
+		return err
+	}
+	if warn != "" {
+		b.Logger().Warn(warn, "roleset", name)
+	}
+	return nil
+}
+
+func (b *backend) rotateStaticAccountTokenKey(ctx context.Context, req *logical.Request, name string) error {
+	b.staticAccountLock.Lock()
+	defer b.staticAccountLock.Unlock()
+
+	acct, err := b.getStaticAccount(name, ctx, req.Storage)
+	if err != nil {
+		return err
+	}
+	if acct == nil {
+		return fmt.Errorf("static account %q not found", name)
+	}
+
+	b.Logger().Info("rotating static account token key", "static_account", name)
+	warn, err := b.saveStaticAccountWithNewTokenKey(ctx, req, acct)
+	if err != nil {
+		return err
+	}
+	if warn != "" {
+		b.Logger().Warn(warn, "static_account", name)
+	}
+	return nil
+}
+
+// automatedRotationFieldsSet returns whether any of the automated rotation fields, other than
+// the ignored ones, were given.
+func automatedRotationFieldsSet(d *framework.FieldData, ignore ...string) bool {
+	fields := make(map[string]*framework.FieldSchema)
+	automatedrotationutil.AddAutomatedRotationFields(fields)
+	for _, name := range ignore {
+		delete(fields, name)
+	}
+	for name := range fields {
+		if _, ok := d.GetOk(name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// saveWithRotationJob calls save to store a role set or static account. If automated rotation
+// fields were given, the rotation job of the request path is registered, updated or deregistered
+// first and the result recorded in params and info, the same way as for the root credential.
+func (b *backend) saveWithRotationJob(ctx context.Context, req *logical.Request, d *framework.FieldData, params *automatedrotationutil.AutomatedRotationParams, info *automatedrotationutil.RotationInfoResponseParams, save func() error) error {
+	if !automatedRotationFieldsSet(d) {
+		return save()
+	}
+
+	rotationResp, err := params.HandleRotationJob(ctx, b.Backend, d, req)
+	if err != nil {
+		return err
+	}
+	info.SetRotationInfo(rotationResp.RotationInfo)
+
+	err = save()
+	_ = rotationResp.HandleStorageErrorAfterRotationJob(req, err)
+	return err
+}
+
+// tokenKeyRotationWarnings returns warnings about an automated rotation that does not rotate
+// keys often enough.
+func tokenKeyRotationWarnings(params *automatedrotationutil.AutomatedRotationParams) []string {
+	if params.DisableAutomatedRotation || params.RotationPeriod <= maxTokenKeyRotationPeriod {
+		return nil
+	}
+	return []string{fmt.Sprintf("rotation_period %s is longer than %s, so keys will be older than that before they are rotated", params.RotationPeriod, maxTokenKeyRotationPeriod)}
+}
+
+// deregisterRotationJob removes the automated rotation job registered from the request path, if
+// any.
+func (b *backend) deregisterRotationJob(ctx context.Context, req *logical.Request, params *automatedrotationutil.AutomatedRotationParams) error {
+	if !params.ShouldRegisterRotationJob() {
+		return nil
+	}
+	return b.System().DeregisterRotationJob(ctx, &rotation.RotationJobDeregisterRequest{
+		MountPoint: req.MountPoint,
+		ReqPath:    req.Path,
+	})
+}
+
+// populateTokenKeyRotationData adds the automated rotation settings and the last and next
+// rotation times of a token generator key to a read response.
+func populateTokenKeyRotationData(data map[string]interface{}, params *automatedrotationutil.AutomatedRotationParams, tokenGen *TokenGenerator) {
+	params.PopulateAutomatedRotationData(data)
+
+	var last time.Time
+	if tokenGen != nil {
+		last = tokenGen.CreateTime
+	}
+	data["last_key_rotation"] = formatRotationTime(last)
+	data["next_key_rotation"] = formatRotationTime(nextTokenKeyRotation(params, last, time.Now()))
+}
+
+// nextTokenKeyRotation returns when the rotation manager will next rotate a key last rotated at
+// last, or the zero time if it is not known.
+func nextTokenKeyRotation(params *automatedrotationutil.AutomatedRotationParams, last, now time.Time) time.Time {
+	switch {
+	case params.DisableAutomatedRotation:
+		return time.Time{}
+	case params.RotationSchedule != "":
+		sched, err := cron.ParseStandard(params.RotationSchedule)
+		if err != nil {
+			return time.Time{}
+		}
+		return sched.Next(now).UTC()
+	case params.RotationPeriod > 0 && !last.IsZero():
+		return last.Add(params.RotationPeriod).UTC()
+	default:
+		return time.Time{}
+	}
+}
+
+func formatRotationTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// withTokenKeyRotationResponseFields adds the automated token key rotation fields to a read
+// response schema.
+func withTokenKeyRotationResponseFields(fields map[string]*framework.FieldSchema) map[string]*framework.FieldSchema {
+	fields["rotation_schedule"] = &framework.FieldSchema{
+		Type:        framework.TypeString,
+		Description: "CRON-style schedule for automated token key rotation.",
+	}
+	fields["rotation_window"] = &framework.FieldSchema{
+		Type:        framework.TypeInt,
+		Description: "Time window in seconds for automated rotation to complete.",
+	}
+	fields["rotation_period"] = &framework.FieldSchema{
+		Type:        framework.TypeInt,
+		Description: "Period in seconds between automated token key rotations.",
+	}
+	fields["disable_automated_rotation"] = &framework.FieldSchema{
+		Type:        framework.TypeBool,
+		Description: "Whether automated rotation is disabled.",
+	}
+	fields["rotation_policy"] = &framework.FieldSchema{
+		Type:        framework.TypeString,
+		Description: "Name of the rotation policy for automated token key rotation.",
+	}
+	fields["last_key_rotation"] = &framework.FieldSchema{
+		Type:        framework.TypeString,
+		Description: "Time the token key was last rotated, if known.",
+	}
+	fields["next_key_rotation"] = &framework.FieldSchema{
+		Type:        framework.TypeString,
+		Description: "Time the token key will next be rotated automatically, if known.",
+	}
+	return fields
+}
@@ -0,0 +1,64 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/helper/automatedrotationutil"
+)
+
+func Test_NextTokenKeyRotation(t *testing.T) {
+	last := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		params   automatedrotationutil.AutomatedRotationParams
+		last     time.Time
+		expected time.Time
+	}{
+		"not configured": {
+			last: last,
+		},
+		"period": {
+			params:   automatedrotationutil.AutomatedRotationParams{RotationPeriod: 24 * time.Hour},
+			last:     last,
+			expected: last.Add(24 * time.Hour),
+		},
+		"period with unknown last rotation": {
+			params: automatedrotationutil.AutomatedRotationParams{RotationPeriod: 24 * time.Hour},
+		},
+		"schedule": {
+			params:   automatedrotationutil.AutomatedRotationParams{RotationSchedule: "0 0 * * *"},
+			last:     last,
+			expected: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+		},
+		"disabled": {
+			params: automatedrotationutil.AutomatedRotationParams{RotationPeriod: 24 * time.Hour, DisableAutomatedRotation: true},
+			last:   last,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual := nextTokenKeyRotation(&tt.params, tt.last, now)
+			if !actual.Equal(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_TokenKeyRotationWarnings(t *testing.T) {
+	params := &automatedrotationutil.AutomatedRotationParams{RotationPeriod: maxTokenKeyRotationPeriod}
+	if w := tokenKeyRotationWarnings(params); len(w) != 0 {
+		t.Fatalf("expected no warnings, got %v", w)
+	}
+
+	params.RotationPeriod = maxTokenKeyRotationPeriod + time.Hour
+	if w := tokenKeyRotationWarnings(params); len(w) != 1 {
+		t.Fatalf("expected a warning, got %v", w)
+	}
+}
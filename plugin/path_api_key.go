@@ -0,0 +1,299 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	apiKeyStoragePrefix = "api-key"
+	apiKeyPathPrefix    = "api-key"
+)
+
+func pathApiKey(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: fmt.Sprintf("%s/%s", apiKeyPathPrefix, framework.GenericNameRegex("name")),
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixGoogleCloud,
+			OperationSuffix: "api-key",
+		},
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Required. Name of the API key role. Cannot be updated.",
+			},
+			"project": {
+				Type:        framework.TypeString,
+				Description: "Required. GCP project to create API keys in. Cannot be updated.",
+			},
+			"display_name_prefix": {
+				Type:        framework.TypeString,
+				Description: fmt.Sprintf("Prefix of the display name of generated API keys, followed by the role name. Defaults to %q.", defaultApiKeyDisplayNamePrefix),
+			},
+			"api_targets": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: `List of services generated API keys may call, e.g. "places.googleapis.com". Restrict a service to specific methods with "<service>:<method>". If empty, the keys can call any enabled API.`,
+			},
+			"allowed_referrers": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: "List of HTTP referrers (wildcards allowed) browser requests may come from.",
+			},
+			"allowed_ips": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: "List of IP addresses or CIDR ranges server requests may come from.",
+			},
+			"allowed_android_apps": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: `List of Android apps that may use the keys, as "<package_name>:<sha1_fingerprint>".`,
+			},
+			"allowed_bundle_ids": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: "List of iOS app bundle IDs that may use the keys.",
+			},
+		},
+		ExistenceCheck: b.pathApiKeyExistenceCheck,
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.DeleteOperation: &framework.PathOperation{
+				Callback: b.pathApiKeyDelete,
+				Summary:  "Delete an API key role.",
+				Responses: map[int][]framework.Response{
+					204: {{Description: "No Content"}},
+				},
+			},
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathApiKeyRead,
+				Summary:  "Return an API key role.",
+				Responses: map[int][]framework.Response{
+					200: {{
+						Description: "OK",
+						Fields: map[string]*framework.FieldSchema{
+							"project": {
+								Type:        framework.TypeString,
+								Description: "GCP project API keys are created in.",
+							},
+							"display_name_prefix": {
+								Type:        framework.TypeString,
+								Description: "Prefix of the display name of generated API keys.",
+							},
+							"api_targets": {
+								Type:        framework.TypeSlice,
+								Description: "Services generated API keys may call.",
+							},
+							"allowed_referrers": {
+								Type:        framework.TypeSlice,
+								Description: "HTTP referrers browser requests may come from.",
+							},
+							"allowed_ips": {
+								Type:        framework.TypeSlice,
+								Description: "IP addresses or CIDR ranges server requests may come from.",
+							},
+							"allowed_android_apps": {
+								Type:        framework.TypeSlice,
+								Description: "Android apps that may use the keys.",
+							},
+							"allowed_bundle_ids": {
+								Type:        framework.TypeSlice,
+								Description: "iOS app bundle IDs that may use the keys.",
+							},
+						},
+					}},
+				},
+			},
+			logical.CreateOperation: &framework.PathOperation{
+				Callback: b.pathApiKeyCreateUpdate,
+				Summary:  "Create an API key role.",
+				Responses: map[int][]framework.Response{
+					204: {{Description: "No Content"}},
+				},
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathApiKeyCreateUpdate,
+				Summary:  "Update an API key role.",
+				Responses: map[int][]framework.Response{
+					204: {{Description: "No Content"}},
+				},
+			},
+		},
+		HelpSynopsis:    pathApiKeyHelpSyn,
+		HelpDescription: pathApiKeyHelpDesc,
+	}
+}
+
+func pathApiKeyList(b *backend) *framework.Path {
+	// Paths for listing API key roles
+	return &framework.Path{
+		Pattern: fmt.Sprintf("%ss?/?", apiKeyPathPrefix),
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixGoogleCloud,
+			OperationVerb:   "list",
+			OperationSuffix: "api-keys|api-keys2",
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ListOperation: &framework.PathOperation{
+				Callback: b.pathApiKeyList,
+				Summary:  "List all API key roles.",
+				Responses: map[int][]framework.Response{
+					200: {{
+						Description: "OK",
+						Fields: map[string]*framework.FieldSchema{
+							"keys": {
+								Type:        framework.TypeSlice,
+								Description: "List of API key role names.",
+							},
+						},
+					}},
+				},
+			},
+		},
+		HelpSynopsis:    pathListApiKeyHelpSyn,
+		HelpDescription: pathListApiKeyHelpDesc,
+	}
+}
+
+func (b *backend) pathApiKeyExistenceCheck(ctx context.Context, req *logical.Request, d *framework.FieldData) (bool, error) {
+	nameRaw, ok := d.GetOk("name")
+	if !ok {
+		return false, errors.New("API key role name is required")
+	}
+
+	role, err := b.getApiKeyRole(nameRaw.(string), ctx, req.Storage)
+	if err != nil {
+		return false, err
+	}
+
+	return role != nil, nil
+}
+
+func (b *backend) pathApiKeyRead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	nameRaw, ok := d.GetOk("name")
+	if !ok {
+		return logical.ErrorResponse("name is required"), nil
+	}
+
+	role, err := b.getApiKeyRole(nameRaw.(string), ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, nil
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"project":              role.Project,
+			"display_name_prefix":  role.DisplayNamePrefix,
+			"api_targets":          role.ApiTargets,
+			"allowed_referrers":    role.AllowedReferrers,
+			"allowed_ips":          role.AllowedIps,
+			"allowed_android_apps": role.AllowedAndroidApps,
+			"allowed_bundle_ids":   role.AllowedBundleIds,
+		},
+	}, nil
+}
+
+func (b *backend) pathApiKeyDelete(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	nameRaw, ok := d.GetOk("name")
+	if !ok {
+		return logical.ErrorResponse("name is required"), nil
+	}
+	name := nameRaw.(string)
+
+	b.apiKeyLock.Lock()
+	defer b.apiKeyLock.Unlock()
+
+	// Keys already issued under this role are owned by their leases and are
+	// deleted when the leases are revoked.
+	b.Logger().Debug("deleting API key role from storage", "name", name)
+	if err := req.Storage.Delete(ctx, fmt.Sprintf("%s/%s", apiKeyStoragePrefix, name)); err != nil {
+		return nil, err
+	}
+
+	b.Logger().Debug("finished deleting API key role from storage", "name", name)
+	return nil, nil
+}
+
+func (b *backend) pathApiKeyCreateUpdate(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("name").(string)
+
+	b.apiKeyLock.Lock()
+	defer b.apiKeyLock.Unlock()
+
+	role, err := b.getApiKeyRole(name, ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	isCreate := role == nil
+	if isCreate {
+		role = &ApiKeyRole{
+			Name: name,
+		}
+	}
+
+	projectRaw, ok := d.GetOk("project")
+	if ok {
+		project := projectRaw.(string)
+		if !isCreate && project != role.Project {
+			return logical.ErrorResponse("cannot change project for API key role %q", name), nil
+		}
+		role.Project = project
+	} else if isCreate {
+		return logical.ErrorResponse("project is required"), nil
+	}
+
+	if v, ok := d.GetOk("display_name_prefix"); ok {
+		role.DisplayNamePrefix = v.(string)
+	}
+	if v, ok := d.GetOk("api_targets"); ok {
+		role.ApiTargets = v.([]string)
+	}
+	if v, ok := d.GetOk("allowed_referrers"); ok {
+		role.AllowedReferrers = v.([]string)
+	}
+	if v, ok := d.GetOk("allowed_ips"); ok {
+		role.AllowedIps = v.([]string)
+	}
+	if v, ok := d.GetOk("allowed_android_apps"); ok {
+		role.AllowedAndroidApps = v.([]string)
+	}
+	if v, ok := d.GetOk("allowed_bundle_ids"); ok {
+		role.AllowedBundleIds = v.([]string)
+	}
+
+	if err := role.save(ctx, req.Storage); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	if w := role.unrestrictedWarning(); w != "" {
+		return &logical.Response{Warnings: []string{w}}, nil
+	}
+	return nil, nil
+}
+
+func (b *backend) pathApiKeyList(ctx context.Context, req *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
+	roles, err := req.Storage.List(ctx, fmt.Sprintf("%s/", apiKeyStoragePrefix))
+	if err != nil {
+		return nil, err
+	}
+	return logical.ListResponse(roles), nil
+}
+
+const pathApiKeyHelpSyn = `Register and manage a role to generate restricted Google API keys under`
+const pathApiKeyHelpDesc = `
+This path allows you to configure a role for generating API keys through the
+API Keys service. Generated keys are created in the configured project with the
+configured API targets and application restrictions, and are deleted when their
+lease is revoked.
+
+Only one kind of application restriction (referrers, IPs, Android apps or iOS
+bundle IDs) can be set on a role. A role without API targets or an application
+restriction generates unrestricted keys, and a warning is returned when it is
+written and whenever it generates a key.`
+
+const pathListApiKeyHelpSyn = `List created API key roles.`
+const pathListApiKeyHelpDesc = `List created API key roles.`
@@ -0,0 +1,90 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func pathApiKeySecret(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: fmt.Sprintf("%s/%s/creds", apiKeyPathPrefix, framework.GenericNameRegex("name")),
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixGoogleCloud,
+			OperationVerb:   "generate",
+		},
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Required. Name of the API key role.",
+			},
+			"ttl": {
+				Type:        framework.TypeDurationSecond,
+				Description: "Lifetime of the API key",
+				Query:       true,
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathApiKeySecret,
+				Summary:  "Generate a restricted API key.",
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationSuffix: "api-key-creds2",
+				},
+				Responses: map[int][]framework.Response{
+					200: {{
+						Description: "OK",
+						Fields:      responseFieldsApiKey(),
+					}},
+				},
+				ForwardPerformanceStandby: true,
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathApiKeySecret,
+				Summary:  "Generate a restricted API key.",
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationSuffix: "api-key-creds",
+				},
+				Responses: map[int][]framework.Response{
+					200: {{
+						Description: "OK",
+						Fields:      responseFieldsApiKey(),
+					}},
+				},
+				ForwardPerformanceStandby: true,
+			},
+		},
+		HelpSynopsis:    pathApiKeySecretSyn,
+		HelpDescription: pathApiKeySecretDesc,
+	}
+}
+
+func (b *backend) pathApiKeySecret(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("name").(string)
+	ttl := d.Get("ttl").(int)
+
+	role, err := b.getApiKeyRole(name, ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return logical.ErrorResponse("API key role %q does not exists", name), nil
+	}
+
+	return b.createApiKeySecret(ctx, req.Storage, role, ttl)
+}
+
+const (
+	pathApiKeySecretSyn  = `Generate a restricted Google API key.`
+	pathApiKeySecretDesc = `
+This path will generate a new API key in the role's project, restricted to the
+role's API targets and application restrictions.
+
+The key is deleted when the lease is revoked.
+`
+)
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/hashicorp/go-gcp-common/gcputil"
+	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/iamutil"
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/helper/automatedrotationutil"
 	"github.com/hashicorp/vault/sdk/helper/pluginidentityutil"
@@ -42,6 +43,30 @@ func pathConfig(b *backend) *framework.Path {
 				Type:        framework.TypeString,
 				Description: `Email ID for the Service Account to impersonate for Workload Identity Federation.`,
 			},
+			"token_cache_enabled": {
+				Type:        framework.TypeBool,
+				Description: "Cache generated access tokens and share them between requests for the same account, scopes and ttl. Defaults to false.",
+			},
+			"token_cache_min_ttl": {
+				Type:        framework.TypeDurationSecond,
+				Description: fmt.Sprintf("Minimum remaining lifetime of a cached access token for it to be returned. If <= 0, defaults to %s.", defaultTokenCacheMinTTL),
+			},
+			"drift_check_interval": {
+				Type:        framework.TypeDurationSecond,
+				Description: "Interval at which role sets and static accounts are checked for IAM drift, which is logged and sent as an event. If <= 0, periodic drift checks are disabled.",
+			},
+			"tidy_interval": {
+				Type:        framework.TypeDurationSecond,
+				Description: "Interval at which orphaned role set keys and bindings are tidied. Periodic tidying does not delete service accounts. If <= 0, periodic tidying is disabled.",
+			},
+			"tidy_safety_buffer": {
+				Type:        framework.TypeDurationSecond,
+				Description: fmt.Sprintf("Minimum age of a service account or key before periodic tidying deletes it. If <= 0, defaults to %s.", defaultTidySafetyBuffer),
+			},
+			"iam_policy_concurrency": {
+				Type:        framework.TypeInt,
+				Description: fmt.Sprintf("Maximum number of resources whose IAM policies are updated at the same time when creating or removing bindings. If <= 0, defaults to %d.", iamutil.DefaultPolicyUpdateConcurrency),
+			},
 		},
 
 		Operations: map[logical.Operation]framework.OperationHandler{
@@ -68,6 +93,30 @@ func pathConfig(b *backend) *framework.Path {
 								Type:        framework.TypeString,
 								Description: "Email ID of the service account used for Workload Identity Federation.",
 							},
+							"token_cache_enabled": {
+								Type:        framework.TypeBool,
+								Description: "Whether generated access tokens are cached.",
+							},
+							"token_cache_min_ttl": {
+								Type:        framework.TypeInt,
+								Description: "Minimum remaining lifetime of a cached access token, in seconds.",
+							},
+							"drift_check_interval": {
+								Type:        framework.TypeInt,
+								Description: "Interval of periodic IAM drift checks, in seconds.",
+							},
+							"tidy_interval": {
+								Type:        framework.TypeInt,
+								Description: "Interval of periodic tidying, in seconds.",
+							},
+							"tidy_safety_buffer": {
+								Type:        framework.TypeInt,
+								Description: "Minimum age of a service account or key before periodic tidying deletes it, in seconds.",
+							},
+							"iam_policy_concurrency": {
+								Type:        framework.TypeInt,
+								Description: "Maximum number of resources whose IAM policies are updated at the same time.",
+							},
 							"identity_token_audience": {
 								Type:        framework.TypeString,
 								Description: "Audience of plugin identity tokens.",
@@ -134,9 +183,15 @@ func (b *backend) pathConfigRead(ctx context.Context, req *logical.Request, data
 	}
 
 	configData := map[string]interface{}{
-		"ttl":                   int64(cfg.TTL / time.Second),
-		"max_ttl":               int64(cfg.MaxTTL / time.Second),
-		"service_account_email": cfg.ServiceAccountEmail,
+		"ttl":                    int64(cfg.TTL / time.Second),
+		"max_ttl":                int64(cfg.MaxTTL / time.Second),
+		"service_account_email":  cfg.ServiceAccountEmail,
+		"token_cache_enabled":    cfg.TokenCacheEnabled,
+		"token_cache_min_ttl":    int64(cfg.TokenCacheMinTTL / time.Second),
+		"drift_check_interval":   int64(cfg.DriftCheckInterval / time.Second),
+		"tidy_interval":          int64(cfg.TidyInterval / time.Second),
+		"tidy_safety_buffer":     int64(cfg.TidySafetyBuffer / time.Second),
+		"iam_policy_concurrency": cfg.IamPolicyConcurrency,
 	}
 
 	cfg.PopulatePluginIdentityTokenData(configData)
@@ -217,6 +272,37 @@ func (b *backend) pathConfigWrite(ctx context.Context, req *logical.Request, dat
 		cfg.MaxTTL = time.Duration(maxTTLRaw.(int)) * time.Second
 	}
 
+	// Update token cache settings.
+	cacheEnabledRaw, ok := data.GetOk("token_cache_enabled")
+	if ok {
+		cfg.TokenCacheEnabled = cacheEnabledRaw.(bool)
+	}
+
+	cacheMinTTLRaw, ok := data.GetOk("token_cache_min_ttl")
+	if ok {
+		cfg.TokenCacheMinTTL = time.Duration(cacheMinTTLRaw.(int)) * time.Second
+	}
+
+	driftCheckIntervalRaw, ok := data.GetOk("drift_check_interval")
+	if ok {
+		cfg.DriftCheckInterval = time.Duration(driftCheckIntervalRaw.(int)) * time.Second
+	}
+
+	tidyIntervalRaw, ok := data.GetOk("tidy_interval")
+	if ok {
+		cfg.TidyInterval = time.Duration(tidyIntervalRaw.(int)) * time.Second
+	}
+
+	tidySafetyBufferRaw, ok := data.GetOk("tidy_safety_buffer")
+	if ok {
+		cfg.TidySafetyBuffer = time.Duration(tidySafetyBufferRaw.(int)) * time.Second
+	}
+
+	iamPolicyConcurrencyRaw, ok := data.GetOk("iam_policy_concurrency")
+	if ok {
+		cfg.IamPolicyConcurrency = iamPolicyConcurrencyRaw.(int)
+	}
+
 	rotationResp, err := cfg.HandleRotationJob(ctx, b.Backend, data, req)
 	if err != nil {
 		return logical.ErrorResponse(err.Error()), nil
@@ -229,6 +315,9 @@ func (b *backend) pathConfigWrite(ctx context.Context, req *logical.Request, dat
 
 	if setNewCreds {
 		b.ClearCaches()
+	} else {
+		// Tokens cached under the previous config may no longer be valid for it.
+		b.tokenCache.Clear()
 	}
 	return nil, nil
 }
@@ -240,6 +329,17 @@ type config struct {
 	MaxTTL time.Duration
 
 	ServiceAccountEmail string
+
+	TokenCacheEnabled bool
+	TokenCacheMinTTL  time.Duration
+
+	DriftCheckInterval time.Duration
+
+	TidyInterval     time.Duration
+	TidySafetyBuffer time.Duration
+
+	IamPolicyConcurrency int
+
 	pluginidentityutil.PluginIdentityTokenParams
 	automatedrotationutil.AutomatedRotationParams
 	automatedrotationutil.RotationInfoResponseParams
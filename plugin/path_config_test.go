@@ -38,6 +38,12 @@ func TestConfig(t *testing.T) {
 		"ttl":                        int64(0),
 		"max_ttl":                    int64(0),
 		"service_account_email":      "",
+		"token_cache_enabled":        false,
+		"token_cache_min_ttl":        int64(0),
+		"drift_check_interval":       int64(0),
+		"tidy_interval":              int64(0),
+		"tidy_safety_buffer":         int64(0),
+		"iam_policy_concurrency":     0,
 		"identity_token_audience":    "",
 		"identity_token_ttl":         int64(0),
 		"rotation_window":            float64(0),
@@ -41,6 +41,26 @@ func pathImpersonatedAccount(b *backend) *framework.Path {
 				Type:        framework.TypeDurationSecond,
 				Description: "Lifetime of the token for the impersonated account.",
 			},
+			"allowed_audiences": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: "List of audience patterns (globs allowed) that ID tokens generated under this account may be issued for. If empty, any audience is allowed.",
+			},
+			"access_boundary": {
+				Type:        framework.TypeString,
+				Description: "Credential Access Boundary JSON (raw or base64-encoded) used to downscope access tokens generated under this account. Set to an empty string to remove.",
+			},
+			"delegates": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: "Ordered list of service account emails in the delegation chain used to reach the impersonated account. Each account must be granted roles/iam.serviceAccountTokenCreator on the next account in the chain.",
+			},
+			"subject": {
+				Type:        framework.TypeString,
+				Description: "Email of the user to act as using domain-wide delegation.",
+			},
+			"allowed_subjects": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: "List of subject patterns (globs allowed) that token requests may use to override the configured subject.",
+			},
 		},
 		ExistenceCheck: b.pathImpersonatedAccountExistenceCheck,
 		Operations: map[logical.Operation]framework.OperationHandler{
@@ -74,6 +94,26 @@ func pathImpersonatedAccount(b *backend) *framework.Path {
 								Type:        framework.TypeInt,
 								Description: "Lifetime of the token in seconds.",
 							},
+							"allowed_audiences": {
+								Type:        framework.TypeSlice,
+								Description: "Audience patterns allowed for ID tokens.",
+							},
+							"access_boundary": {
+								Type:        framework.TypeMap,
+								Description: "Credential Access Boundary used to downscope access tokens.",
+							},
+							"delegates": {
+								Type:        framework.TypeSlice,
+								Description: "Delegation chain used to reach the impersonated account.",
+							},
+							"subject": {
+								Type:        framework.TypeString,
+								Description: "User to act as using domain-wide delegation.",
+							},
+							"allowed_subjects": {
+								Type:        framework.TypeSlice,
+								Description: "Subject patterns token requests may override the subject with.",
+							},
 						},
 					}},
 				},
@@ -162,6 +202,13 @@ func (b *backend) pathImpersonatedAccountRead(ctx context.Context, req *logical.
 		"service_account_email":   acct.EmailOrId,
 		"token_scopes":            acct.TokenScopes,
 		"ttl":                     acct.Ttl,
+		"allowed_audiences":       acct.AllowedAudiences,
+		"delegates":               acct.Delegates,
+		"subject":                 acct.Subject,
+		"allowed_subjects":        acct.AllowedSubjects,
+	}
+	if acct.AccessBoundary != nil {
+		data["access_boundary"] = acct.AccessBoundary.asOutput()
 	}
 
 	return &logical.Response{
@@ -284,6 +331,35 @@ func (b *backend) parseImpersonateInformation(prevValues ImpersonatedAccount, d
 		prevValues.Ttl = ttl.(int)
 	}
 
+	audiences, ok := d.GetOk("allowed_audiences")
+	if ok {
+		prevValues.AllowedAudiences = audiences.([]string)
+	}
+
+	delegates, ok := d.GetOk("delegates")
+	if ok {
+		prevValues.Delegates = delegates.([]string)
+	}
+
+	subject, ok := d.GetOk("subject")
+	if ok {
+		prevValues.Subject = subject.(string)
+	}
+
+	allowedSubjects, ok := d.GetOk("allowed_subjects")
+	if ok {
+		prevValues.AllowedSubjects = allowedSubjects.([]string)
+	}
+
+	boundaryRaw, ok := d.GetOk("access_boundary")
+	if ok {
+		boundary, err := parseAccessBoundary(boundaryRaw.(string))
+		if err != nil {
+			return nil, nil, err
+		}
+		prevValues.AccessBoundary = boundary
+	}
+
 	return &prevValues, warnings, nil
 }
 
@@ -8,10 +8,13 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/hashicorp/errwrap"
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/logical"
+	"golang.org/x/oauth2"
 	"google.golang.org/api/impersonate"
-	"google.golang.org/api/option"
+
+	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/tokencache"
 )
 
 func responseFieldsImpersonatedAccountAccessToken() map[string]*framework.FieldSchema {
@@ -28,6 +31,10 @@ func responseFieldsImpersonatedAccountAccessToken() map[string]*framework.FieldS
 			Type:        framework.TypeInt,
 			Description: "Unix timestamp at which the token expires.",
 		},
+		"scopes": {
+			Type:        framework.TypeCommaStringSlice,
+			Description: "OAuth scopes granted to the token.",
+		},
 	}
 }
 
@@ -43,6 +50,26 @@ func pathImpersonatedAccountSecretAccessToken(b *backend) *framework.Path {
 				Type:        framework.TypeString,
 				Description: "Required. Name of the impersonated account.",
 			},
+			"subject": {
+				Type:        framework.TypeString,
+				Description: "Email of the user to act as using domain-wide delegation. Must match the allowed subjects of the impersonated account.",
+				Query:       true,
+			},
+			"scopes": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: "Subset of the configured token_scopes to assign to the token. Defaults to all configured scopes.",
+				Query:       true,
+			},
+			"ttl": {
+				Type:        framework.TypeDurationSecond,
+				Description: "Lifetime of the token, at most one hour. Cannot exceed the configured ttl or the mount max_ttl.",
+				Query:       true,
+			},
+			"access_boundary": {
+				Type:        framework.TypeString,
+				Description: "Credential Access Boundary JSON (raw or base64-encoded) to downscope the token with. Must be within the configured access_boundary, if any.",
+				Query:       true,
+			},
 		},
 		Operations: map[logical.Operation]framework.OperationHandler{
 			logical.ReadOperation: &framework.PathOperation{
@@ -77,6 +104,56 @@ func pathImpersonatedAccountSecretAccessToken(b *backend) *framework.Path {
 	}
 }
 
+func fieldSchemaImpersonatedAccountIdToken() map[string]*framework.FieldSchema {
+	fields := fieldSchemaIdToken()
+	fields["name"] = &framework.FieldSchema{
+		Type:        framework.TypeString,
+		Description: "Required. Name of the impersonated account.",
+	}
+	return fields
+}
+
+func pathImpersonatedAccountSecretIdToken(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: fmt.Sprintf("%s/%s/id-token", impersonatedAccountPathPrefix, framework.GenericNameRegex("name")),
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixGoogleCloud,
+			OperationVerb:   "generate",
+		},
+		Fields: fieldSchemaImpersonatedAccountIdToken(),
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathImpersonatedAccountIdToken,
+				Summary:  "Generate an OIDC ID token for an impersonated account.",
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationSuffix: "impersonated-account-id-token2",
+				},
+				Responses: map[int][]framework.Response{
+					200: {{
+						Description: "OK",
+						Fields:      responseFieldsIdToken(),
+					}},
+				},
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathImpersonatedAccountIdToken,
+				Summary:  "Generate an OIDC ID token for an impersonated account.",
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationSuffix: "impersonated-account-id-token",
+				},
+				Responses: map[int][]framework.Response{
+					200: {{
+						Description: "OK",
+						Fields:      responseFieldsIdToken(),
+					}},
+				},
+			},
+		},
+		HelpSynopsis:    pathIdTokenHelpSyn,
+		HelpDescription: pathIdTokenHelpDesc,
+	}
+}
+
 func (b *backend) pathImpersonatedAccountAccessToken(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
 	acctName := d.Get("name").(string)
 
@@ -88,9 +165,9 @@ func (b *backend) pathImpersonatedAccountAccessToken(ctx context.Context, req *l
 		return logical.ErrorResponse("impersonated account %q does not exists", acctName), nil
 	}
 
-	creds, err := b.credentials(req.Storage)
+	subject, err := acct.subjectForRequest(d.Get("subject").(string))
 	if err != nil {
-		return nil, err
+		return logical.ErrorResponse(err.Error()), nil
 	}
 
 	cfg, err := getConfig(ctx, req.Storage)
@@ -114,25 +191,71 @@ func (b *backend) pathImpersonatedAccountAccessToken(ctx context.Context, req *l
 		acctTtl = cfg.TTL
 	}
 
-	tokenSource, err := impersonate.CredentialsTokenSource(ctx, impersonate.CredentialsConfig{
-		TargetPrincipal: acct.EmailOrId,
-		Scopes:          acct.TokenScopes,
-		Lifetime:        acctTtl,
-	}, option.WithCredentials(creds))
+	maxTTL := b.maxTokenTTL(cfg)
+	if acctTtl > 0 && (maxTTL == 0 || acctTtl < maxTTL) {
+		maxTTL = acctTtl
+	}
+	params, err := parseAccessTokenParams(d, acct.TokenScopes, acct.AccessBoundary, maxTTL)
 	if err != nil {
-		return logical.ErrorResponse("unable to generate token source: %v", err), nil
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	if params.ttl > 0 {
+		acctTtl = params.ttl
+	} else if capped, w := capAccessTokenLifetime(acctTtl); w != "" {
+		acctTtl = capped
+		warnings = append(warnings, w)
+	}
+
+	key := tokencache.Key{
+		Account:   acct.EmailOrId,
+		Scopes:    params.scopes,
+		Delegates: acct.Delegates,
+		Subject:   subject,
+		Lifetime:  acctTtl,
 	}
-	token, err := tokenSource.Token()
+	token, err := b.cachedToken(ctx, req.Storage, key, func(ctx context.Context) (*oauth2.Token, error) {
+		tokenSource, err := b.impersonatedTokenSource(ctx, req.Storage, impersonate.CredentialsConfig{
+			TargetPrincipal: acct.EmailOrId,
+			Scopes:          params.scopes,
+			Delegates:       acct.Delegates,
+			Subject:         subject,
+			Lifetime:        acctTtl,
+		})
+		if err != nil {
+			return nil, errwrap.Wrapf("unable to generate token source: {{err}}", err)
+		}
+		return tokenSource.Token()
+	})
 	if err != nil {
 		return logical.ErrorResponse("unable to generate token - make sure your service account and key are still valid: %v", err), nil
 	}
 
+	token, err = downscopeToken(ctx, token, params.boundary)
+	if err != nil {
+		return logical.ErrorResponse("unable to generate token: %v", err), nil
+	}
+
 	return &logical.Response{
-		Data: map[string]interface{}{
-			"token":              token.AccessToken,
-			"token_ttl":          token.Expiry.UTC().Sub(time.Now().UTC()) / (time.Second),
-			"expires_at_seconds": token.Expiry.Unix(),
-		},
-		Warnings: warnings,
+		Data:     accessTokenResponseData(token, params.scopes),
+		Warnings: append(warnings, params.warnings...),
 	}, nil
 }
+
+func (b *backend) pathImpersonatedAccountIdToken(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	acctName := d.Get("name").(string)
+
+	acct, err := b.getImpersonatedAccount(acctName, ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if acct == nil {
+		return logical.ErrorResponse("impersonated account %q does not exists", acctName), nil
+	}
+
+	params, err := parseIdTokenParams(d, acct.AllowedAudiences)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	return b.secretImpersonatedIdTokenResponse(ctx, req.Storage, acct.EmailOrId, acct.Delegates, params)
+}
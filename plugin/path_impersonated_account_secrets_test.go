@@ -6,11 +6,14 @@ package gcpsecrets
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/util"
+	"github.com/hashicorp/vault/sdk/logical"
 	"google.golang.org/api/iam/v1"
+	"google.golang.org/api/idtoken"
 	"google.golang.org/api/oauth2/v2"
 	"google.golang.org/api/option"
 )
@@ -147,3 +150,69 @@ func testGetImpersonatedAccessToken(t *testing.T, td *testData, roleName string,
 
 	return time.Duration(info.ExpiresIn) * time.Second
 }
+
+func TestImpersonatedSecrets_GetIdToken(t *testing.T) {
+	roleName := "test-imp-id-token"
+	audience := "https://service.example.com"
+
+	td := setupTest(t, "0h", "1h")
+	defer cleanupImpersonate(t, td, roleName, util.StringSet{})
+
+	sa := createServiceAccount(t, td, roleName)
+	defer deleteServiceAccount(t, td, sa)
+
+	testImpersonateCreate(t, td, roleName,
+		map[string]interface{}{
+			"service_account_email": sa.Email,
+			"token_scopes":          []string{iam.CloudPlatformScope},
+			"allowed_audiences":     []string{"https://*.example.com"},
+		})
+
+	path := fmt.Sprintf("%s/%s/id-token", impersonatedAccountPathPrefix, roleName)
+
+	resp, err := td.B.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      path,
+		Data: map[string]interface{}{
+			"audience":      audience,
+			"include_email": true,
+		},
+		Storage: td.S,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp == nil || resp.IsError() {
+		t.Fatalf("expected ID token, got response: %v", resp)
+	}
+
+	payload, err := idtoken.Validate(context.Background(), resp.Data["token"].(string), audience)
+	if err != nil {
+		t.Fatalf("unable to validate ID token: %v", err)
+	}
+	if payload.Claims["email"] != sa.Email {
+		t.Fatalf("expected ID token email claim %q, got %v", sa.Email, payload.Claims["email"])
+	}
+
+	// Audiences not matching the allowed patterns should be rejected
+	resp, err = td.B.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      path,
+		Data: map[string]interface{}{
+			"audience": "https://service.example.org",
+		},
+		Storage: td.S,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp == nil || !resp.IsError() {
+		t.Fatalf("expected error for disallowed audience, got response: %v", resp)
+	}
+	if !strings.Contains(resp.Error().Error(), "is not allowed") {
+		t.Fatalf("unexpected error: %v", resp.Error())
+	}
+
+	// Cleanup
+	testImpersonateDelete(t, td, roleName)
+}
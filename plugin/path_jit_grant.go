@@ -0,0 +1,278 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/iamutil"
+	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/util"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	jitGrantStoragePrefix = "jit-grant"
+	jitGrantPathPrefix    = "jit-grant"
+)
+
+func pathJitGrant(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: fmt.Sprintf("%s/%s", jitGrantPathPrefix, framework.GenericNameRegex("name")),
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixGoogleCloud,
+			OperationSuffix: "jit-grant",
+		},
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Required. Name of the JIT grant role. Cannot be updated.",
+			},
+			"bindings": {
+				Type:        framework.TypeString,
+				Description: "Required. Bindings configuration string of the roles granted to principals.",
+			},
+			"allowed_principals": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: `Required. List of principal patterns (globs allowed), e.g. "user:*@example.com", that grants may be issued to.`,
+			},
+			"ttl": {
+				Type:        framework.TypeDurationSecond,
+				Description: "Default lifetime of grants. If not set, the mount's default TTL is used.",
+			},
+			"max_ttl": {
+				Type:        framework.TypeDurationSecond,
+				Description: "Maximum lifetime of grants. If not set, the mount's max TTL is used.",
+			},
+		},
+		ExistenceCheck: b.pathJitGrantExistenceCheck,
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.DeleteOperation: &framework.PathOperation{
+				Callback: b.pathJitGrantDelete,
+				Summary:  "Delete a JIT grant role.",
+				Responses: map[int][]framework.Response{
+					204: {{Description: "No Content"}},
+				},
+			},
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathJitGrantRead,
+				Summary:  "Return a JIT grant role.",
+				Responses: map[int][]framework.Response{
+					200: {{
+						Description: "OK",
+						Fields: map[string]*framework.FieldSchema{
+							"bindings": {
+								Type:        framework.TypeMap,
+								Description: "Map of resource to roles granted.",
+							},
+							"allowed_principals": {
+								Type:        framework.TypeSlice,
+								Description: "Principal patterns grants may be issued to.",
+							},
+							"ttl": {
+								Type:        framework.TypeInt,
+								Description: "Default lifetime of grants in seconds.",
+							},
+							"max_ttl": {
+								Type:        framework.TypeInt,
+								Description: "Maximum lifetime of grants in seconds.",
+							},
+						},
+					}},
+				},
+			},
+			logical.CreateOperation: &framework.PathOperation{
+				Callback: b.pathJitGrantCreateUpdate,
+				Summary:  "Create a JIT grant role.",
+				Responses: map[int][]framework.Response{
+					204: {{Description: "No Content"}},
+				},
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathJitGrantCreateUpdate,
+				Summary:  "Update a JIT grant role.",
+				Responses: map[int][]framework.Response{
+					204: {{Description: "No Content"}},
+				},
+			},
+		},
+		HelpSynopsis:    pathJitGrantHelpSyn,
+		HelpDescription: pathJitGrantHelpDesc,
+	}
+}
+
+func pathJitGrantList(b *backend) *framework.Path {
+	// Paths for listing JIT grant roles
+	return &framework.Path{
+		Pattern: fmt.Sprintf("%ss?/?", jitGrantPathPrefix),
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixGoogleCloud,
+			OperationVerb:   "list",
+			OperationSuffix: "jit-grants|jit-grants2",
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ListOperation: &framework.PathOperation{
+				Callback: b.pathJitGrantList,
+				Summary:  "List all JIT grant roles.",
+				Responses: map[int][]framework.Response{
+					200: {{
+						Description: "OK",
+						Fields: map[string]*framework.FieldSchema{
+							"keys": {
+								Type:        framework.TypeSlice,
+								Description: "List of JIT grant role names.",
+							},
+						},
+					}},
+				},
+			},
+		},
+		HelpSynopsis:    pathListJitGrantHelpSyn,
+		HelpDescription: pathListJitGrantHelpDesc,
+	}
+}
+
+func (b *backend) pathJitGrantExistenceCheck(ctx context.Context, req *logical.Request, d *framework.FieldData) (bool, error) {
+	nameRaw, ok := d.GetOk("name")
+	if !ok {
+		return false, errors.New("JIT grant role name is required")
+	}
+
+	role, err := b.getJitGrantRole(nameRaw.(string), ctx, req.Storage)
+	if err != nil {
+		return false, err
+	}
+
+	return role != nil, nil
+}
+
+func (b *backend) pathJitGrantRead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	nameRaw, ok := d.GetOk("name")
+	if !ok {
+		return logical.ErrorResponse("name is required"), nil
+	}
+
+	role, err := b.getJitGrantRole(nameRaw.(string), ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, nil
+	}
+
+	data := map[string]interface{}{
+		"bindings":           role.Bindings.asOutput(),
+		"allowed_principals": role.AllowedPrincipals,
+		"ttl":                role.Ttl,
+		"max_ttl":            role.MaxTtl,
+	}
+	if aliases := resourceAliases(role.Bindings, nil); len(aliases) > 0 {
+		data["resource_aliases"] = aliases
+	}
+	return &logical.Response{Data: data}, nil
+}
+
+func (b *backend) pathJitGrantDelete(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	nameRaw, ok := d.GetOk("name")
+	if !ok {
+		return logical.ErrorResponse("name is required"), nil
+	}
+	name := nameRaw.(string)
+
+	b.jitGrantLock.Lock()
+	defer b.jitGrantLock.Unlock()
+
+	// Outstanding grants keep their bindings in their lease and are removed
+	// when the leases are revoked.
+	b.Logger().Debug("deleting JIT grant role from storage", "name", name)
+	if err := req.Storage.Delete(ctx, fmt.Sprintf("%s/%s", jitGrantStoragePrefix, name)); err != nil {
+		return nil, err
+	}
+
+	b.Logger().Debug("finished deleting JIT grant role from storage", "name", name)
+	return nil, nil
+}
+
+func (b *backend) pathJitGrantCreateUpdate(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("name").(string)
+
+	b.jitGrantLock.Lock()
+	defer b.jitGrantLock.Unlock()
+
+	role, err := b.getJitGrantRole(name, ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		role = &JitGrantRole{
+			Name: name,
+		}
+	}
+
+	if v, ok := d.GetOk("bindings"); ok {
+		bindings, err := util.ParseBindings(v.(string))
+		if err != nil {
+			return logical.ErrorResponse("unable to parse bindings: %v", err), nil
+		}
+		if err := checkDuplicateAliases(bindings, nil); err != nil {
+			return logical.ErrorResponse(err.Error()), nil
+		}
+		if err := b.checkJitGrantBindings(bindings); err != nil {
+			return logical.ErrorResponse(err.Error()), nil
+		}
+		role.RawBindings = v.(string)
+		role.Bindings = bindings
+	}
+	if v, ok := d.GetOk("allowed_principals"); ok {
+		role.AllowedPrincipals = v.([]string)
+	}
+	if v, ok := d.GetOk("ttl"); ok {
+		role.Ttl = v.(int)
+	}
+	if v, ok := d.GetOk("max_ttl"); ok {
+		role.MaxTtl = v.(int)
+	}
+
+	if err := role.save(ctx, req.Storage); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	return nil, nil
+}
+
+// checkJitGrantBindings returns an error if any bound resource can't be granted conditional bindings.
+func (b *backend) checkJitGrantBindings(bindings ResourceBindings) error {
+	for resourceName := range bindings {
+		resource, err := b.resources.Parse(resourceName)
+		if err != nil {
+			return errwrap.Wrapf(fmt.Sprintf("unable to parse resource %q: {{err}}", resourceName), err)
+		}
+		if !iamutil.SupportsConditions(resource) {
+			return fmt.Errorf("resource %q cannot be used in a JIT grant role: %w", resourceName, iamutil.ErrConditionsNotSupported)
+		}
+	}
+	return nil
+}
+
+func (b *backend) pathJitGrantList(ctx context.Context, req *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
+	roles, err := req.Storage.List(ctx, fmt.Sprintf("%s/", jitGrantStoragePrefix))
+	if err != nil {
+		return nil, err
+	}
+	return logical.ListResponse(roles), nil
+}
+
+const pathJitGrantHelpSyn = `Register and manage a role to grant principals time-bound IAM bindings`
+const pathJitGrantHelpDesc = `
+This path allows you to configure a just-in-time (JIT) grant role. Each grant
+binds a user, group or service account matching one of the allowed principal
+patterns to the role's bindings, with an IAM Condition that expires with the
+lease. Access ends at expiry even if Vault cannot revoke the lease.
+
+BigQuery datasets do not support IAM Conditions and cannot be used in JIT grant roles.`
+
+const pathListJitGrantHelpSyn = `List created JIT grant roles.`
+const pathListJitGrantHelpDesc = `List created JIT grant roles.`
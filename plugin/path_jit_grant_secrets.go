@@ -0,0 +1,125 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func pathJitGrantSecret(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: fmt.Sprintf("%s/%s/grant", jitGrantPathPrefix, framework.GenericNameRegex("name")),
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixGoogleCloud,
+			OperationVerb:   "generate",
+			OperationSuffix: "jit-grant",
+		},
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Required. Name of the JIT grant role.",
+			},
+			"principal": {
+				Type:        framework.TypeString,
+				Description: `Required. IAM member to grant access to, e.g. "user:alice@example.com", "group:oncall@example.com" or "serviceAccount:sa@project.iam.gserviceaccount.com".`,
+			},
+			"ttl": {
+				Type:        framework.TypeDurationSecond,
+				Description: "Lifetime of the grant. Defaults to the role's ttl.",
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathJitGrantSecret,
+				Summary:  "Grant a principal time-bound access to the role's bindings.",
+				Responses: map[int][]framework.Response{
+					200: {{
+						Description: "OK",
+						Fields:      responseFieldsJitGrant(),
+					}},
+				},
+				ForwardPerformanceStandby: true,
+			},
+		},
+		HelpSynopsis:    pathJitGrantSecretSyn,
+		HelpDescription: pathJitGrantSecretDesc,
+	}
+}
+
+func (b *backend) pathJitGrantSecret(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("name").(string)
+	principal := d.Get("principal").(string)
+
+	role, err := b.getJitGrantRole(name, ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return logical.ErrorResponse("JIT grant role %q does not exists", name), nil
+	}
+
+	if principal == "" {
+		return logical.ErrorResponse("principal is required"), nil
+	}
+	if err := role.checkPrincipal(principal); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	ttl, warnings, err := b.jitGrantTTL(ctx, req.Storage, role, d.Get("ttl").(int))
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := b.createJitGrantSecret(ctx, req, role, principal, ttl)
+	if err != nil || resp == nil {
+		return resp, err
+	}
+	for _, w := range warnings {
+		resp.AddWarning(w)
+	}
+	return resp, nil
+}
+
+// jitGrantTTL returns the lifetime of a grant, capped to the role's max_ttl. Unset
+// role TTLs fall back to the config and then the mount's TTLs.
+func (b *backend) jitGrantTTL(ctx context.Context, s logical.Storage, role *JitGrantRole, requested int) (time.Duration, []string, error) {
+	cfg, err := getConfig(ctx, s)
+	if err != nil {
+		return 0, nil, err
+	}
+	if cfg == nil {
+		cfg = &config{}
+	}
+
+	ttl := time.Duration(role.Ttl) * time.Second
+	if ttl == 0 {
+		ttl = cfg.TTL
+	}
+	if ttl == 0 {
+		ttl = b.System().DefaultLeaseTTL()
+	}
+	maxTTL := time.Duration(role.MaxTtl) * time.Second
+	if maxTTL == 0 {
+		maxTTL = cfg.MaxTTL
+	}
+	if maxTTL == 0 {
+		maxTTL = b.System().MaxLeaseTTL()
+	}
+
+	if requested > 0 {
+		ttl = time.Duration(requested) * time.Second
+	}
+
+	var warnings []string
+	if ttl > maxTTL {
+		warnings = append(warnings, fmt.Sprintf("ttl of %s is greater than max_ttl of %s, capping to max_ttl", ttl, maxTTL))
+		ttl = maxTTL
+	}
+	return ttl, warnings, nil
+}
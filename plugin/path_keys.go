@@ -0,0 +1,333 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func pathRoleSetKeys(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: fmt.Sprintf("roleset/%s/keys/?$", framework.GenericNameRegex("name")),
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixGoogleCloud,
+			OperationVerb:   "list",
+			OperationSuffix: "roleset-keys",
+		},
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the role set.",
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ListOperation: &framework.PathOperation{
+				Callback: b.pathRoleSetKeysList,
+				Summary:  "List the user-managed keys of a roleset's service accounts.",
+				Responses: map[int][]framework.Response{
+					200: {{
+						Description: "OK",
+						Fields:      responseFieldsKeyInventory(),
+					}},
+				},
+			},
+		},
+		HelpSynopsis:    pathKeysHelpSyn,
+		HelpDescription: pathKeysHelpDesc,
+	}
+}
+
+func pathRoleSetKeysPrune(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: fmt.Sprintf("roleset/%s/keys/prune", framework.GenericNameRegex("name")),
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixGoogleCloud,
+			OperationVerb:   "prune",
+			OperationSuffix: "roleset-keys",
+		},
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the role set.",
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathRoleSetKeysPrune,
+				Summary:  "Delete keys of a roleset's service accounts that are not owned by Vault.",
+				Responses: map[int][]framework.Response{
+					200: {{
+						Description: "OK",
+						Fields:      responseFieldsKeysPrune(),
+					}},
+				},
+				ForwardPerformanceStandby: true,
+			},
+		},
+		HelpSynopsis:    pathKeysPruneHelpSyn,
+		HelpDescription: pathKeysHelpDesc,
+	}
+}
+
+func pathStaticAccountKeys(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: fmt.Sprintf("%s/%s/keys/?$", staticAccountPathPrefix, framework.GenericNameRegex("name")),
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixGoogleCloud,
+			OperationVerb:   "list",
+			OperationSuffix: "static-account-keys",
+		},
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the static account.",
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ListOperation: &framework.PathOperation{
+				Callback: b.pathStaticAccountKeysList,
+				Summary:  "List the user-managed keys of a static account's service account.",
+				Responses: map[int][]framework.Response{
+					200: {{
+						Description: "OK",
+						Fields:      responseFieldsKeyInventory(),
+					}},
+				},
+			},
+		},
+		HelpSynopsis:    pathKeysHelpSyn,
+		HelpDescription: pathKeysHelpDesc,
+	}
+}
+
+func pathStaticAccountKeysPrune(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: fmt.Sprintf("%s/%s/keys/prune", staticAccountPathPrefix, framework.GenericNameRegex("name")),
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixGoogleCloud,
+			OperationVerb:   "prune",
+			OperationSuffix: "static-account-keys",
+		},
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the static account.",
+			},
+			"keys": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: "IDs or full resource names of the unknown keys to delete. Required, as the service account is not owned by Vault.",
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathStaticAccountKeysPrune,
+				Summary:  "Delete keys of a static account's service account that are not owned by Vault.",
+				Responses: map[int][]framework.Response{
+					200: {{
+						Description: "OK",
+						Fields:      responseFieldsKeysPrune(),
+					}},
+				},
+				ForwardPerformanceStandby: true,
+			},
+		},
+		HelpSynopsis:    pathKeysPruneHelpSyn,
+		HelpDescription: pathKeysHelpDesc,
+	}
+}
+
+func responseFieldsKeyInventory() map[string]*framework.FieldSchema {
+	return map[string]*framework.FieldSchema{
+		"keys": {
+			Type:        framework.TypeStringSlice,
+			Description: "IDs of the user-managed keys of the service account.",
+		},
+		"key_info": {
+			Type:        framework.TypeMap,
+			Description: "Name, status (token_generator, current, previous, leased or unknown), origin, algorithm, validity and lease issue time of each key.",
+		},
+	}
+}
+
+func responseFieldsKeysPrune() map[string]*framework.FieldSchema {
+	return map[string]*framework.FieldSchema{
+		"pruned_keys": {
+			Type:        framework.TypeStringSlice,
+			Description: "Names of the deleted keys.",
+		},
+		"skipped_keys": {
+			Type:        framework.TypeStringSlice,
+			Description: "Names of unknown keys that were not deleted as they may belong to a lease.",
+		},
+	}
+}
+
+func (b *backend) pathRoleSetKeysList(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("name").(string)
+
+	rs, err := getRoleSet(name, ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if rs == nil {
+		return logical.ErrorResponse("role set %q not found", name), nil
+	}
+	if rs.AccountId == nil {
+		return logical.ErrorResponse("role set %q has no service account", name), nil
+	}
+
+	entries, warnings, err := b.roleSetKeyInventory(ctx, req.Storage, rs)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	resp := keyInventoryResponse(nil, entries)
+	for _, w := range warnings {
+		resp.AddWarning(w)
+	}
+	return resp, nil
+}
+
+func (b *backend) pathRoleSetKeysPrune(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("name").(string)
+
+	b.rolesetLock.Lock()
+	defer b.rolesetLock.Unlock()
+
+	rs, err := getRoleSet(name, ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if rs == nil {
+		return logical.ErrorResponse("role set %q not found", name), nil
+	}
+	if rs.AccountId == nil {
+		return logical.ErrorResponse("role set %q has no service account", name), nil
+	}
+
+	entries, _, err := b.roleSetKeyInventory(ctx, req.Storage, rs)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	return b.pruneKeysResponse(ctx, req, entries)
+}
+
+// roleSetKeyInventory returns the key inventory of all service accounts of a role set, including
+// its key pool, and a key limit warning for each account close to the limit.
+func (b *backend) roleSetKeyInventory(ctx context.Context, s logical.Storage, rs *RoleSet) ([]*keyInventoryEntry, []string, error) {
+	indexParent := leasedKeyIndexParent(rolesetStoragePrefix, rs.Name)
+
+	var entries []*keyInventoryEntry
+	var warnings []string
+	for _, id := range rs.accounts() {
+		acctEntries, err := b.keyInventory(ctx, s, id, ownedKeys(rs.TokenGen, nil), indexParent)
+		if err != nil {
+			return nil, nil, err
+		}
+		if w := keyLimitWarning(id, len(acctEntries)); w != "" {
+			warnings = append(warnings, w)
+		}
+		entries = append(entries, acctEntries...)
+	}
+	return entries, warnings, nil
+}
+
+func (b *backend) pathStaticAccountKeysList(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("name").(string)
+
+	acct, err := b.getStaticAccount(name, ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if acct == nil {
+		return logical.ErrorResponse("static account %q not found", name), nil
+	}
+
+	entries, err := b.keyInventory(ctx, req.Storage, &acct.ServiceAccountId, ownedKeys(acct.TokenGen, acct.RotatingKey), leasedKeyIndexParent(staticAccountStoragePrefix, acct.Name))
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	return keyInventoryResponse(&acct.ServiceAccountId, entries), nil
+}
+
+func (b *backend) pathStaticAccountKeysPrune(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("name").(string)
+	keys := d.Get("keys").([]string)
+	if len(keys) == 0 {
+		return logical.ErrorResponse("keys is required, as unknown keys of a static account may have been created outside of Vault"), nil
+	}
+
+	b.staticAccountLock.Lock()
+	defer b.staticAccountLock.Unlock()
+
+	acct, err := b.getStaticAccount(name, ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if acct == nil {
+		return logical.ErrorResponse("static account %q not found", name), nil
+	}
+
+	entries, err := b.keyInventory(ctx, req.Storage, &acct.ServiceAccountId, ownedKeys(acct.TokenGen, acct.RotatingKey), leasedKeyIndexParent(staticAccountStoragePrefix, acct.Name))
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	entries, err = selectPruneKeys(entries, keys)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	return b.pruneKeysResponse(ctx, req, entries)
+}
+
+func (b *backend) pruneKeysResponse(ctx context.Context, req *logical.Request, entries []*keyInventoryEntry) (*logical.Response, error) {
+	pruned, skipped, err := b.pruneKeys(ctx, req.Storage, entries)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	if pruned == nil {
+		pruned = []string{}
+	}
+	if skipped == nil {
+		skipped = []string{}
+	}
+
+	resp := &logical.Response{
+		Data: map[string]interface{}{
+			"pruned_keys":  pruned,
+			"skipped_keys": skipped,
+		},
+	}
+	if len(skipped) > 0 {
+		resp.AddWarning(fmt.Sprintf("%d unknown keys are younger than the mount's max lease TTL and may belong to leases, so they were not deleted", len(skipped)))
+	}
+	return resp, nil
+}
+
+const pathKeysHelpSyn = `List the user-managed keys of a roleset or static account service account.`
+const pathKeysPruneHelpSyn = `Delete the keys of a roleset or static account service account that are not owned by Vault.`
+const pathKeysHelpDesc = `
+The keys endpoint lists the user-managed keys of the service account and what
+owns each of them:
+
+* token_generator: the key Vault uses to generate access tokens.
+* current, previous: the key of a static account in "rotating" credential
+  mode, and the key it replaced that is kept until the overlap period ends.
+* leased: a key generated as a service_account_key secret on this cluster,
+  which is deleted when its lease is revoked.
+* unknown: any other key, for example one created outside of Vault or left
+  behind by a failed rotation.
+
+GCP allows at most 10 user-managed keys per service account, and a warning is
+returned once the account gets close to this limit.
+
+The prune endpoint deletes unknown keys. Keys issued before this index
+existed, or on another cluster, are also reported as unknown, so unknown keys
+younger than the mount's max lease TTL are never deleted. As the service
+account of a static account is not owned by Vault, its unknown keys may have
+been created outside of Vault, so prune only deletes the unknown keys given
+in the "keys" parameter.
+`
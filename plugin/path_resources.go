@@ -0,0 +1,331 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-gcp-common/gcputil"
+	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/iamutil"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func pathResourcesList(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "resources/?$",
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixGoogleCloud,
+			OperationVerb:   "list",
+			OperationSuffix: "resource-types",
+		},
+		Fields: map[string]*framework.FieldSchema{
+			"service": {
+				Type:        framework.TypeString,
+				Description: `Only list resource types of this API service, e.g. "pubsub".`,
+				Query:       true,
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ListOperation: &framework.PathOperation{
+				Callback: b.pathResourcesList,
+				Summary:  "List the resource types that bindings can be set on.",
+				Responses: map[int][]framework.Response{
+					200: {{
+						Description: "OK",
+						Fields: map[string]*framework.FieldSchema{
+							"keys": {
+								Type:        framework.TypeStringSlice,
+								Description: "Supported resource type keys.",
+							},
+							"key_info": {
+								Type:        framework.TypeMap,
+								Description: "API services and versions that support each resource type.",
+							},
+						},
+					}},
+				},
+			},
+		},
+		HelpSynopsis:    pathResourcesHelpSyn,
+		HelpDescription: pathResourcesHelpDesc,
+	}
+}
+
+func pathResourcesParse(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "resources/parse",
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixGoogleCloud,
+			OperationVerb:   "parse",
+			OperationSuffix: "resource",
+		},
+		Fields: map[string]*framework.FieldSchema{
+			"resource": {
+				Type:        framework.TypeString,
+				Description: "Required. Resource name as given in bindings: a self-link, full resource name, relative resource name or short resource URI.",
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathResourcesParse,
+				Summary:  "Show how a resource name is matched to a supported resource type.",
+				Responses: map[int][]framework.Response{
+					200: {{
+						Description: "OK",
+						Fields:      responseFieldsResourceParse(),
+					}},
+				},
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathResourcesParse,
+				Summary:  "Show how a resource name is matched to a supported resource type.",
+				Responses: map[int][]framework.Response{
+					200: {{
+						Description: "OK",
+						Fields:      responseFieldsResourceParse(),
+					}},
+				},
+			},
+		},
+		HelpSynopsis:    pathResourcesParseHelpSyn,
+		HelpDescription: pathResourcesHelpDesc,
+	}
+}
+
+func responseFieldsResourceParse() map[string]*framework.FieldSchema {
+	return map[string]*framework.FieldSchema{
+		"resource": {
+			Type:        framework.TypeString,
+			Description: "The given resource name.",
+		},
+		"resource_alias_of": {
+			Type:        framework.TypeString,
+			Description: "Full resource name of the resource, if it was given as a short resource URI.",
+		},
+		"matched": {
+			Type:        framework.TypeBool,
+			Description: "Whether the resource matched a supported resource type.",
+		},
+		"type_key": {
+			Type:        framework.TypeString,
+			Description: "Resource type key of the resource name.",
+		},
+		"ids": {
+			Type:        framework.TypeMap,
+			Description: "Resource IDs by collection.",
+		},
+		"service": {
+			Type:        framework.TypeString,
+			Description: "API service of the matched resource type.",
+		},
+		"version": {
+			Type:        framework.TypeString,
+			Description: "API version of the matched resource type.",
+		},
+		"preferred_version": {
+			Type:        framework.TypeBool,
+			Description: "Whether the matched API version is the preferred version of the service.",
+		},
+		"parameters": {
+			Type:        framework.TypeStringSlice,
+			Description: "Parameters replaced in the method paths.",
+		},
+		"get_method": {
+			Type:        framework.TypeMap,
+			Description: "HTTP method and URL used to get the IAM policy.",
+		},
+		"set_method": {
+			Type:        framework.TypeMap,
+			Description: "HTTP method and URL used to set the IAM policy.",
+		},
+		"test_permissions_method": {
+			Type:        framework.TypeMap,
+			Description: "HTTP method and URL used to test permissions, if supported.",
+		},
+		"reason": {
+			Type:        framework.TypeString,
+			Description: "Why the resource did not match, if it did not.",
+		},
+		"explanation": {
+			Type:        framework.TypeString,
+			Description: "How to give a resource name that matches, if it did not.",
+		},
+		"candidates": {
+			Type:        framework.TypeSlice,
+			Description: "Each API service and version that supports the resource type.",
+		},
+	}
+}
+
+// resourceRegistry returns the resource parser of the backend as a registry that can list the
+// resource types it supports.
+func (b *backend) resourceRegistry() (iamutil.ResourceRegistry, error) {
+	registry, ok := b.resources.(iamutil.ResourceRegistry)
+	if !ok {
+		return nil, errors.New("resource parser does not support listing resource types")
+	}
+	return registry, nil
+}
+
+func (b *backend) pathResourcesList(_ context.Context, _ *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	registry, err := b.resourceRegistry()
+	if err != nil {
+		return nil, err
+	}
+
+	typeKeys := registry.TypeKeys(d.Get("service").(string))
+	keyInfo := make(map[string]interface{}, len(typeKeys))
+	for _, typeKey := range typeKeys {
+		services := make(map[string][]string)
+		for _, v := range registry.Versions(typeKey) {
+			services[v.Service] = append(services[v.Service], v.Version)
+		}
+		keyInfo[typeKey] = map[string]interface{}{
+			"services": services,
+		}
+	}
+	return logical.ListResponseWithInfo(typeKeys, keyInfo), nil
+}
+
+func (b *backend) pathResourcesParse(_ context.Context, _ *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	rawName := d.Get("resource").(string)
+	if rawName == "" {
+		return logical.ErrorResponse("resource is required"), nil
+	}
+
+	registry, err := b.resourceRegistry()
+	if err != nil {
+		return nil, err
+	}
+
+	data := map[string]interface{}{
+		"resource": rawName,
+		"matched":  false,
+	}
+	if name, isAlias, err := iamutil.ResolveResourceAlias(rawName); err == nil && isAlias {
+		data["resource_alias_of"] = name
+	}
+
+	fullName, prefix, err := iamutil.ParseResourceName(rawName)
+	if err != nil {
+		data["reason"] = err.Error()
+		data["explanation"] = "The resource must be a self-link, full resource name, relative resource name or short resource URI. See the bindings help for the supported formats."
+		return &logical.Response{Data: data}, nil
+	}
+
+	relName := fullName.RelativeResourceName
+	versions := registry.Versions(relName.TypeKey)
+	data["type_key"] = relName.TypeKey
+	data["ids"] = relName.IdTuples
+	data["candidates"] = resourceCandidatesOutput(versions)
+
+	resource, err := registry.Parse(rawName)
+	if err != nil {
+		data["reason"] = err.Error()
+		data["explanation"] = explainResourceMismatch(fullName, prefix, versions)
+		return &logical.Response{Data: data}, nil
+	}
+
+	config := resource.GetConfig()
+	data["matched"] = true
+	data["service"] = config.Service
+	data["preferred_version"] = config.IsPreferredVersion
+	data["parameters"] = config.Parameters
+	data["get_method"] = restMethodOutput(config.GetMethod)
+	data["set_method"] = restMethodOutput(config.SetMethod)
+	if config.TestPermissionsMethod.HttpMethod != "" {
+		data["test_permissions_method"] = restMethodOutput(config.TestPermissionsMethod)
+	}
+	for _, v := range versions {
+		if v.Service == config.Service && v.Config.GetMethod == config.GetMethod {
+			data["version"] = v.Version
+			break
+		}
+	}
+	return &logical.Response{Data: data}, nil
+}
+
+// explainResourceMismatch describes how to give a resource name that matches one of the given
+// versions of its resource type, following the order in which the resource config is looked up.
+func explainResourceMismatch(fullName *gcputil.FullResourceName, prefix string, versions []*iamutil.ResourceVersion) string {
+	typeKey := fullName.RelativeResourceName.TypeKey
+	if len(versions) == 0 {
+		return fmt.Sprintf("Resource type %q is not supported. LIST resources/ to see the supported resource types; if the API is new, the generated resource registry may need to be regenerated.", typeKey)
+	}
+
+	var services []string
+	versionsByService := make(map[string][]string)
+	for _, v := range versions {
+		if _, ok := versionsByService[v.Service]; !ok {
+			services = append(services, v.Service)
+		}
+		versionsByService[v.Service] = append(versionsByService[v.Service], v.Version)
+	}
+
+	switch {
+	case prefix != "":
+		return fmt.Sprintf("The self-link prefix %q does not match the URL of any service version supporting resource type %q. See candidates for the supported URLs.", prefix, typeKey)
+	case fullName.Service != "" && versionsByService[fullName.Service] == nil:
+		return fmt.Sprintf("Service %q does not support resource type %q. It is supported by: %s.", fullName.Service, typeKey, strings.Join(services, ", "))
+	case fullName.Service == "" && len(services) > 1:
+		return fmt.Sprintf("Resource type %q is supported by multiple services (%s). Give a full resource name such as //%s.googleapis.com/%s, or a self-link.", typeKey, strings.Join(services, ", "), services[0], relativeResourceName(fullName.RelativeResourceName))
+	default:
+		service := fullName.Service
+		if service == "" {
+			service = services[0]
+		}
+		return fmt.Sprintf("Service %q has versions %s of resource type %q, none of which is preferred. Give a self-link with the version, using one of the URLs in candidates.", service, strings.Join(versionsByService[service], ", "), typeKey)
+	}
+}
+
+// relativeResourceName returns the path of a parsed relative resource name, e.g.
+// "projects/my-project/topics/my-topic".
+func relativeResourceName(relName *gcputil.RelativeResourceName) string {
+	parts := make([]string, 0, 2*len(relName.OrderedCollectionIds))
+	for _, collection := range relName.OrderedCollectionIds {
+		parts = append(parts, collection, relName.IdTuples[collection])
+	}
+	return strings.Join(parts, "/")
+}
+
+func resourceCandidatesOutput(versions []*iamutil.ResourceVersion) []map[string]interface{} {
+	out := make([]map[string]interface{}, 0, len(versions))
+	for _, v := range versions {
+		out = append(out, map[string]interface{}{
+			"service":           v.Service,
+			"version":           v.Version,
+			"preferred_version": v.Config.IsPreferredVersion,
+			"get_method":        restMethodOutput(v.Config.GetMethod),
+		})
+	}
+	return out
+}
+
+func restMethodOutput(m iamutil.RestMethod) map[string]interface{} {
+	return map[string]interface{}{
+		"http_method": m.HttpMethod,
+		"url":         m.BaseURL + m.Path,
+	}
+}
+
+const pathResourcesHelpSyn = `List the resource types that bindings can be set on.`
+const pathResourcesParseHelpSyn = `Show how a resource name in bindings is matched to a resource type.`
+const pathResourcesHelpDesc = `
+These read-only paths describe the resource registry generated from the Google
+API Discovery Service, which determines the resources bindings can be set on.
+
+LIST resources/ returns the supported resource type keys, e.g.
+"projects/topics", with the API services and versions supporting each. Use the
+"service" parameter to only list the types of one service.
+
+resources/parse takes a resource name as given in bindings and returns the
+matched resource type, service, API version, IDs and the URLs used to get and
+set its IAM policy. If the resource does not match, for example because its
+type is not supported or it is supported by multiple services or versions, the
+response explains why and lists the candidate services and versions.
+`
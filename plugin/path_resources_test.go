@@ -0,0 +1,85 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-secure-stdlib/strutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func TestPathResourcesList(t *testing.T) {
+	b, reqStorage := getTestBackend(t)
+
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.ListOperation,
+		Path:      "resources/",
+		Data:      map[string]interface{}{"service": "pubsub"},
+		Storage:   reqStorage,
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err: %v, resp: %#v", err, resp)
+	}
+
+	keys := resp.Data["keys"].([]string)
+	if !strutil.StrListContains(keys, "projects/topics") || strutil.StrListContains(keys, "b") {
+		t.Fatalf("expected pubsub resource types only, got %v", keys)
+	}
+	info := resp.Data["key_info"].(map[string]interface{})["projects/topics"].(map[string]interface{})
+	if versions := info["services"].(map[string][]string)["pubsub"]; len(versions) == 0 {
+		t.Fatalf("expected pubsub versions for projects/topics, got %v", info)
+	}
+}
+
+func TestPathResourcesParse(t *testing.T) {
+	b, reqStorage := getTestBackend(t)
+
+	parse := func(resource string) map[string]interface{} {
+		t.Helper()
+		resp, err := b.HandleRequest(context.Background(), &logical.Request{
+			Operation: logical.ReadOperation,
+			Path:      "resources/parse",
+			Data:      map[string]interface{}{"resource": resource},
+			Storage:   reqStorage,
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("err: %v, resp: %#v", err, resp)
+		}
+		return resp.Data
+	}
+
+	data := parse("pubsub://my-project/topics/my-topic")
+	if !data["matched"].(bool) {
+		t.Fatalf("expected resource to match, got %v", data)
+	}
+	if data["resource_alias_of"] != "//pubsub.googleapis.com/projects/my-project/topics/my-topic" {
+		t.Fatalf("expected full resource name of alias, got %v", data["resource_alias_of"])
+	}
+	if data["type_key"] != "projects/topics" || data["service"] != "pubsub" || data["version"] == "" {
+		t.Fatalf("unexpected resource match: %v", data)
+	}
+	getMethod := data["get_method"].(map[string]interface{})
+	if !strings.HasPrefix(getMethod["url"].(string), "https://pubsub.googleapis.com/") {
+		t.Fatalf("unexpected get method: %v", getMethod)
+	}
+
+	data = parse("projects/my-project/snapshots/my-snapshot")
+	if data["matched"].(bool) {
+		t.Fatalf("expected resource supported by multiple services not to match, got %v", data)
+	}
+	if !strings.Contains(data["explanation"].(string), "multiple services") {
+		t.Fatalf("expected explanation of multiple services, got %q", data["explanation"])
+	}
+	if candidates := data["candidates"].([]map[string]interface{}); len(candidates) < 2 {
+		t.Fatalf("expected candidates for each service, got %v", candidates)
+	}
+
+	data = parse("projects/my-project/notatypes/foo")
+	if data["matched"].(bool) || !strings.Contains(data["explanation"].(string), "is not supported") {
+		t.Fatalf("expected unsupported resource type explanation, got %v", data)
+	}
+}
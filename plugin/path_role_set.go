@@ -10,6 +10,7 @@ import (
 	"github.com/hashicorp/errwrap"
 	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/util"
 	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/automatedrotationutil"
 	"github.com/hashicorp/vault/sdk/logical"
 )
 
@@ -18,7 +19,7 @@ const (
 )
 
 func pathRoleSet(b *backend) *framework.Path {
-	return &framework.Path{
+	p := &framework.Path{
 		Pattern: fmt.Sprintf("roleset/%s", framework.GenericNameRegex("name")),
 		DisplayAttrs: &framework.DisplayAttributes{
 			OperationPrefix: operationPrefixGoogleCloud,
@@ -40,12 +41,36 @@ func pathRoleSet(b *backend) *framework.Path {
 			},
 			"bindings": {
 				Type:        framework.TypeString,
-				Description: "Bindings configuration string.",
+				Description: "Bindings configuration string, as HCL or JSON. May be base64-encoded.",
 			},
 			"token_scopes": {
 				Type:        framework.TypeCommaStringSlice,
 				Description: `List of OAuth scopes to assign to credentials generated under this role set`,
 			},
+			"allowed_audiences": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: "List of audience patterns (globs allowed) that ID tokens generated under this role set may be issued for. If empty, any audience is allowed.",
+			},
+			"access_boundary": {
+				Type:        framework.TypeString,
+				Description: "Credential Access Boundary JSON (raw or base64-encoded) used to downscope access tokens generated under this role set. Set to an empty string to remove.",
+			},
+			"update_mode": {
+				Type:        framework.TypeString,
+				Description: fmt.Sprintf("How bindings changes are applied. '%s' (default) creates a new service account with the new bindings; '%s' adds and removes only the changed bindings on the existing service account.", updateModeRotateAccount, updateModeInPlace),
+			},
+			"max_accounts": {
+				Type:        framework.TypeInt,
+				Description: fmt.Sprintf("Maximum number of identically bound service accounts in the pool of a '%s' role set. Accounts are added when all others have keys_per_account leased keys, and removed once drained. Defaults to 1.", SecretTypeKey),
+			},
+			"keys_per_account": {
+				Type:        framework.TypeInt,
+				Description: fmt.Sprintf("Maximum number of leased keys per pool account. Defaults to %d, GCP's limit of user-managed keys per service account.", maxUserManagedKeys),
+			},
+			"dry_run": {
+				Type:        framework.TypeBool,
+				Description: dryRunFieldDescription,
+			},
 		},
 		ExistenceCheck: b.pathRoleSetExistenceCheck("name"),
 		Operations: map[logical.Operation]framework.OperationHandler{
@@ -62,7 +87,7 @@ func pathRoleSet(b *backend) *framework.Path {
 				Responses: map[int][]framework.Response{
 					200: {{
 						Description: "OK",
-						Fields: map[string]*framework.FieldSchema{
+						Fields: withTokenKeyRotationResponseFields(map[string]*framework.FieldSchema{
 							"secret_type": {
 								Type:        framework.TypeString,
 								Description: "Type of secret generated for this roleset.",
@@ -71,6 +96,30 @@ func pathRoleSet(b *backend) *framework.Path {
 								Type:        framework.TypeString,
 								Description: "Bindings configuration for the roleset.",
 							},
+							"update_mode": {
+								Type:        framework.TypeString,
+								Description: "How bindings changes are applied to the roleset's service account.",
+							},
+							"max_accounts": {
+								Type:        framework.TypeInt,
+								Description: "Maximum number of service accounts in the roleset's key pool.",
+							},
+							"keys_per_account": {
+								Type:        framework.TypeInt,
+								Description: "Maximum number of leased keys per pool account.",
+							},
+							"pool_service_account_emails": {
+								Type:        framework.TypeStringSlice,
+								Description: "Emails of the service accounts in the roleset's key pool, in addition to service_account_email.",
+							},
+							"bindings_structured": {
+								Type:        framework.TypeSlice,
+								Description: "Normalized bindings as a sorted list of resources with their roles and condition.",
+							},
+							"conditional_bindings": {
+								Type:        framework.TypeSlice,
+								Description: "Bindings granted under IAM Conditions, with their condition.",
+							},
 							"service_account_email": {
 								Type:        framework.TypeString,
 								Description: "Email of the GCP service account for this roleset.",
@@ -83,7 +132,15 @@ func pathRoleSet(b *backend) *framework.Path {
 								Type:        framework.TypeSlice,
 								Description: "OAuth scopes for access tokens generated under this roleset.",
 							},
-						},
+							"allowed_audiences": {
+								Type:        framework.TypeSlice,
+								Description: "Audience patterns allowed for ID tokens generated under this roleset.",
+							},
+							"access_boundary": {
+								Type:        framework.TypeMap,
+								Description: "Credential Access Boundary used to downscope access tokens.",
+							},
+						}),
 					}},
 				},
 			},
@@ -91,6 +148,10 @@ func pathRoleSet(b *backend) *framework.Path {
 				Callback: b.pathRoleSetCreateUpdate,
 				Summary:  "Create a roleset.",
 				Responses: map[int][]framework.Response{
+					200: {{
+						Description: "OK",
+						Fields:      responseFieldsDryRun(),
+					}},
 					204: {{Description: "No Content"}},
 				},
 				ForwardPerformanceStandby:   true,
@@ -100,6 +161,10 @@ func pathRoleSet(b *backend) *framework.Path {
 				Callback: b.pathRoleSetCreateUpdate,
 				Summary:  "Update a roleset.",
 				Responses: map[int][]framework.Response{
+					200: {{
+						Description: "OK",
+						Fields:      responseFieldsDryRun(),
+					}},
 					204: {{Description: "No Content"}},
 				},
 				ForwardPerformanceStandby:   true,
@@ -109,6 +174,10 @@ func pathRoleSet(b *backend) *framework.Path {
 		HelpSynopsis:    pathRoleSetHelpSyn,
 		HelpDescription: pathRoleSetHelpDesc,
 	}
+
+	automatedrotationutil.AddAutomatedRotationFields(p.Fields)
+
+	return p
 }
 
 func pathRoleSetList(b *backend) *framework.Path {
@@ -232,13 +301,52 @@ func (b *backend) pathRoleSetRead(ctx context.Context, req *logical.Request, d *
 		"bindings":    rs.Bindings.asOutput(),
 	}
 
+	data["bindings_structured"] = structuredBindings(rs.Bindings, rs.ConditionalBindings)
+
+	if len(rs.ConditionalBindings) > 0 {
+		data["conditional_bindings"] = rs.ConditionalBindings.asOutput()
+	}
+	if aliases := resourceAliases(rs.Bindings, rs.ConditionalBindings); len(aliases) > 0 {
+		data["resource_aliases"] = aliases
+	}
+
 	if rs.AccountId != nil {
 		data["service_account_email"] = rs.AccountId.EmailOrId
 		data["project"] = rs.AccountId.Project
 	}
 
-	if rs.TokenGen != nil && rs.SecretType == SecretTypeAccessToken {
-		data["token_scopes"] = rs.TokenGen.Scopes
+	if isAccessTokenSecretType(rs.SecretType) {
+		data["token_scopes"] = rs.tokenScopes()
+	}
+
+	if len(rs.AllowedAudiences) > 0 {
+		data["allowed_audiences"] = rs.AllowedAudiences
+	}
+
+	if rs.AccessBoundary != nil {
+		data["access_boundary"] = rs.AccessBoundary.asOutput()
+	}
+
+	data["update_mode"] = updateModeRotateAccount
+	if rs.UpdateMode != "" {
+		data["update_mode"] = rs.UpdateMode
+	}
+
+	if rs.SecretType == SecretTypeKey {
+		data["max_accounts"] = 1
+		if rs.MaxAccounts > 1 {
+			data["max_accounts"] = rs.MaxAccounts
+		}
+		data["keys_per_account"] = rs.keysPerAccount()
+		poolEmails := make([]string, 0, len(rs.PoolAccounts))
+		for _, id := range rs.PoolAccounts {
+			poolEmails = append(poolEmails, id.EmailOrId)
+		}
+		data["pool_service_account_emails"] = poolEmails
+	}
+
+	if rs.SecretType == SecretTypeAccessToken {
+		populateTokenKeyRotationData(data, &rs.AutomatedRotationParams, rs.TokenGen)
 	}
 
 	return &logical.Response{
@@ -260,13 +368,22 @@ func (b *backend) pathRoleSetDelete(ctx context.Context, req *logical.Request, d
 		return nil, nil
 	}
 
+	if err := b.deregisterRotationJob(ctx, req, &rs.AutomatedRotationParams); err != nil {
+		return nil, errwrap.Wrapf(fmt.Sprintf("unable to deregister key rotation job for role set %s: {{err}}", rsName), err)
+	}
+
 	resources := rs.boundResources()
+	poolResources := rs.poolResources()
 
 	// Add WALs
 	walIds, err := b.addWalsForRoleSetResources(ctx, req, rs.Name, resources)
 	if err != nil {
 		return nil, errwrap.Wrapf(fmt.Sprintf("unable to create WALs for role set GCP resources %s: {{err}}", rsName), err)
 	}
+	poolWalIds, err := b.addWalsForRoleSetPool(ctx, req, rs.Name, poolResources)
+	if err != nil {
+		return nil, errwrap.Wrapf(fmt.Sprintf("unable to create WALs for role set GCP resources %s: {{err}}", rsName), err)
+	}
 
 	// Delete roleset
 	b.Logger().Debug("deleting roleset from storage", "name", rsName)
@@ -275,7 +392,11 @@ func (b *backend) pathRoleSetDelete(ctx context.Context, req *logical.Request, d
 	}
 
 	// Try to clean up resources.
-	if warnings := b.tryDeleteRoleSetResources(ctx, req, resources, walIds); len(warnings) > 0 {
+	warnings := b.tryDeleteRoleSetResources(ctx, req, resources, walIds)
+	for i, poolAccount := range poolResources {
+		warnings = append(warnings, b.tryDeleteRoleSetResources(ctx, req, poolAccount, poolWalIds[i])...)
+	}
+	if len(warnings) > 0 {
 		b.Logger().Debug(
 			"unable to delete GCP resources for deleted roleset but WALs exist to clean up, ignoring errors",
 			"roleset", rsName, "errors", warnings)
@@ -289,6 +410,7 @@ func (b *backend) pathRoleSetDelete(ctx context.Context, req *logical.Request, d
 func (b *backend) pathRoleSetCreateUpdate(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
 	var warnings []string
 	name := d.Get("name").(string)
+	ctx, conflicts := withPolicyConflicts(ctx)
 
 	b.rolesetLock.Lock()
 	defer b.rolesetLock.Unlock()
@@ -305,12 +427,13 @@ func (b *backend) pathRoleSetCreateUpdate(ctx context.Context, req *logical.Requ
 	}
 
 	isCreate := req.Operation == logical.CreateOperation
+	dryRun := d.Get("dry_run").(bool)
 
 	// Secret type
 	if isCreate {
 		secretType := d.Get("secret_type").(string)
 		switch secretType {
-		case SecretTypeKey, SecretTypeAccessToken:
+		case SecretTypeKey, SecretTypeAccessToken, SecretTypeImpersonatedAccessToken, SecretTypeHmacKey:
 			rs.SecretType = secretType
 		default:
 			return logical.ErrorResponse(`invalid "secret_type" value: "%s"`, secretType), nil
@@ -344,22 +467,71 @@ func (b *backend) pathRoleSetCreateUpdate(ctx context.Context, req *logical.Requ
 	var scopes []string
 	scopesRaw, ok := d.GetOk("token_scopes")
 	if ok {
-		if rs.SecretType != SecretTypeAccessToken {
+		if !isAccessTokenSecretType(rs.SecretType) {
 			warnings = []string{
-				fmt.Sprintf("ignoring token_scopes, only valid for '%s' secret type role set", SecretTypeAccessToken),
+				fmt.Sprintf("ignoring token_scopes, only valid for '%s' or '%s' secret type role set", SecretTypeAccessToken, SecretTypeImpersonatedAccessToken),
 			}
 		}
 		scopes = scopesRaw.([]string)
 		if len(scopes) == 0 {
 			return logical.ErrorResponse("cannot provide empty token_scopes"), nil
 		}
-	} else if rs.SecretType == SecretTypeAccessToken {
+	} else if isAccessTokenSecretType(rs.SecretType) {
 		if isCreate {
 			return logical.ErrorResponse("token_scopes must be provided for creating access token role set"), nil
 		}
-		if rs.TokenGen != nil {
-			scopes = rs.TokenGen.Scopes
+		scopes = rs.tokenScopes()
+	}
+
+	// Allowed ID token audiences
+	if audiences, ok := d.GetOk("allowed_audiences"); ok {
+		rs.AllowedAudiences = audiences.([]string)
+	}
+
+	// Access boundary
+	if boundaryRaw, ok := d.GetOk("access_boundary"); ok {
+		boundary, err := parseAccessBoundary(boundaryRaw.(string))
+		if err != nil {
+			return logical.ErrorResponse(err.Error()), nil
+		}
+		if boundary != nil && !isAccessTokenSecretType(rs.SecretType) {
+			warnings = append(warnings, fmt.Sprintf("access_boundary only applies to '%s' or '%s' secret type role set", SecretTypeAccessToken, SecretTypeImpersonatedAccessToken))
 		}
+		rs.AccessBoundary = boundary
+	}
+
+	// Automated key rotation
+	if automatedRotationFieldsSet(d) && rs.SecretType != SecretTypeAccessToken {
+		return logical.ErrorResponse("automated key rotation is only supported for %q role sets", SecretTypeAccessToken), nil
+	}
+
+	// Update mode
+	if updateModeRaw, ok := d.GetOk("update_mode"); ok {
+		switch updateMode := updateModeRaw.(string); updateMode {
+		case updateModeRotateAccount, updateModeInPlace:
+			rs.UpdateMode = updateMode
+		default:
+			return logical.ErrorResponse("invalid update_mode %q, must be one of %q or %q", updateMode, updateModeRotateAccount, updateModeInPlace), nil
+		}
+	}
+
+	// Key pool
+	if maxAccountsRaw, ok := d.GetOk("max_accounts"); ok {
+		maxAccounts := maxAccountsRaw.(int)
+		if maxAccounts < 1 {
+			return logical.ErrorResponse("max_accounts must be at least 1"), nil
+		}
+		if maxAccounts > 1 && rs.SecretType != SecretTypeKey {
+			return logical.ErrorResponse("max_accounts is only supported for %q role sets", SecretTypeKey), nil
+		}
+		rs.MaxAccounts = maxAccounts
+	}
+	if keysPerAccountRaw, ok := d.GetOk("keys_per_account"); ok {
+		keysPerAccount := keysPerAccountRaw.(int)
+		if keysPerAccount < 1 || keysPerAccount > maxUserManagedKeys {
+			return logical.ErrorResponse("keys_per_account must be between 1 and %d", maxUserManagedKeys), nil
+		}
+		rs.KeysPerAccount = keysPerAccount
 	}
 
 	// Bindings
@@ -382,34 +554,94 @@ func (b *backend) pathRoleSetCreateUpdate(ctx context.Context, req *logical.Requ
 	// If no new bindings or new bindings are exactly same as old bindings,
 	// just update the role set without rotating service account.
 	if !newBindings || rs.bindingHash() == getStringHash(bRaw.(string)) {
-		if rs.TokenGen != nil {
-			rs.TokenGen.Scopes = scopes
+		if dryRun {
+			return &logical.Response{
+				Data: map[string]interface{}{
+					"dry_run":                  true,
+					"new_service_account":      "",
+					"deleted_service_accounts": []string{},
+					"new_key":                  false,
+					"iam_changes":              []map[string]interface{}{},
+				},
+				Warnings: warnings,
+			}, nil
 		}
+		rs.setTokenScopes(scopes)
 		// Just save role with updated metadata:
-		if err := rs.save(ctx, req.Storage); err != nil {
+		err := b.saveWithRotationJob(ctx, req, d, &rs.AutomatedRotationParams, &rs.RotationInfoResponseParams, func() error {
+			return rs.save(ctx, req.Storage)
+		})
+		if err != nil {
 			return logical.ErrorResponse(err.Error()), nil
 		}
+		warnings = append(warnings, tokenKeyRotationWarnings(&rs.AutomatedRotationParams)...)
+		if len(warnings) > 0 {
+			return &logical.Response{Warnings: warnings}, nil
+		}
 		return nil, nil
 	}
 
 	// If new bindings, update service account.
-	var bindings ResourceBindings
-	bindings, err = util.ParseBindings(bRaw.(string))
+	bindings, conditional, err := util.ParseConditionalBindings(bRaw.(string))
 	if err != nil {
 		return logical.ErrorResponse("unable to parse bindings: %v", err), nil
 	}
-	if len(bindings) == 0 {
+	if len(bindings) == 0 && len(conditional) == 0 {
 		return logical.ErrorResponse("unable to parse any bindings from given bindings HCL"), nil
 	}
+	if err := checkDuplicateAliases(bindings, toConditionalBindings(conditional)); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	if dryRun {
+		resp, err := b.planRoleSetUpdate(ctx, req, rs, project, bindings, toConditionalBindings(conditional))
+		if resp != nil {
+			resp.Warnings = append(resp.Warnings, warnings...)
+		}
+		return resp, err
+	}
+
+	// Check permissions before creating anything, so a missing one doesn't leave resources
+	// behind for WALs to clean up.
+	check := newPermissionCheck()
+	check.addPolicyResources(bindings, toConditionalBindings(conditional))
+	check.addPolicyResources(rs.Bindings, rs.ConditionalBindings)
+	if !rs.updatesInPlace() {
+		projectResource := fmt.Sprintf(projectResourceTmpl, project)
+		check.add(projectResource, permissionCreateServiceAccount)
+		if rs.SecretType == SecretTypeKey || rs.SecretType == SecretTypeAccessToken {
+			check.add(projectResource, permissionCreateServiceAccountKey)
+		}
+	}
+	permWarns, err := b.checkPermissions(ctx, req, check)
+	if err != nil {
+		return permissionCheckErrorResponse(err), nil
+	}
+	warnings = append(warnings, permWarns...)
+
 	rs.RawBindings = bRaw.(string)
 
-	updateWarns, err := b.saveRoleSetWithNewAccount(ctx, req, rs, project, bindings, scopes)
+	var updateWarns []string
+	err = b.saveWithRotationJob(ctx, req, d, &rs.AutomatedRotationParams, &rs.RotationInfoResponseParams, func() (err error) {
+		if rs.updatesInPlace() {
+			rs.setTokenScopes(scopes)
+			updateWarns, err = b.saveRoleSetWithUpdatedBindings(ctx, req, rs, bindings, toConditionalBindings(conditional))
+		} else {
+			updateWarns, err = b.saveRoleSetWithNewAccount(ctx, req, rs, project, bindings, toConditionalBindings(conditional), scopes)
+		}
+		return err
+	})
 	if updateWarns != nil {
 		warnings = append(warnings, updateWarns...)
 	}
 	if err != nil {
 		return logical.ErrorResponse(err.Error()), nil
-	} else if warnings != nil && len(warnings) > 0 {
+	}
+	if w := conflicts.warning(); w != "" {
+		warnings = append(warnings, w)
+	}
+	warnings = append(warnings, tokenKeyRotationWarnings(&rs.AutomatedRotationParams)...)
+	if warnings != nil && len(warnings) > 0 {
 		return &logical.Response{Warnings: warnings}, nil
 	}
 	return nil, nil
@@ -437,12 +669,7 @@ func (b *backend) pathRoleSetRotateAccount(ctx context.Context, req *logical.Req
 		return logical.ErrorResponse("roleset '%s' not found", name), nil
 	}
 
-	var scopes []string
-	if rs.TokenGen != nil {
-		scopes = rs.TokenGen.Scopes
-	}
-
-	warnings, err := b.saveRoleSetWithNewAccount(ctx, req, rs, rs.AccountId.Project, rs.Bindings, scopes)
+	warnings, err := b.saveRoleSetWithNewAccount(ctx, req, rs, rs.AccountId.Project, rs.Bindings, rs.ConditionalBindings, rs.tokenScopes())
 	if err != nil {
 		return logical.ErrorResponse(err.Error()), nil
 	} else if warnings != nil && len(warnings) > 0 {
@@ -505,6 +732,28 @@ to specific GCP resources. Secrets (either service account keys or
 access tokens) are generated under a role set and will have the
 given set of roles on resources.
 
+Changing the bindings of a role set creates a new service account and
+deletes the old one, which revokes keys generated under the old account.
+Set update_mode=in_place to instead add and remove only the changed
+bindings on the existing service account. The account can still be
+rotated through the rotate endpoint. Set dry_run=true to see the IAM
+policy changes an update would make on each bound resource without
+changing anything.
+
+Before creating a service account or changing bindings, Vault checks that
+its credentials can get and set the IAM policy of every bound resource and,
+for a new service account, create service accounts and keys in the project.
+Missing permissions are returned together in missing_permissions, and
+nothing is changed.
+
+A service account can only have 10 user-managed keys, which limits a
+service_account_key role set to 10 concurrent leases. Set max_accounts to
+shard keys over a pool of up to that many identically bound service
+accounts. New keys are created on the account with the fewest leased keys,
+accounts are added once all others have keys_per_account leased keys, and
+added accounts are deleted once their keys are revoked. Rotating the
+role set deletes the whole pool.
+
 The specified binding file accepts an HCL (or JSON) string
 with the following format:
 
@@ -517,6 +766,29 @@ resource "some/gcp/resource/uri" {
 	]
 }
 
+A resource block can include a condition block to only grant its roles under an
+IAM Condition. The title and expression are required:
+
+resource "some/gcp/resource/uri" {
+	roles = ["roles/role1"]
+	condition {
+		title       = "expires-2030"
+		description = "Access until 2030"
+		expression  = "request.time < timestamp(\"2030-01-01T00:00:00Z\")"
+	}
+}
+
+BigQuery datasets do not support IAM Conditions.
+
+Bindings can also be given as a JSON document, either a map of resource to roles:
+
+{"some/gcp/resource/uri": ["roles/role1", "roles/role2"]}
+
+or a list of resources with their roles and an optional condition, in the same
+format as the bindings_structured field returned on read:
+
+[{"resource": "some/gcp/resource/uri", "roles": ["roles/role1"], "condition": {...}}]
+
 The given resource can have the following
 
 * Project-level self link
@@ -552,6 +824,20 @@ The given resource can have the following
 
 	Example (Pubsub subscription):
 		projects/myproject/subscriptions/mysub
+
+* Short Resource URI:
+	A short form for common resource types, which is
+	rewritten to the full resource name. Read responses
+	include the full resource name of each alias in
+	resource_aliases. Supported forms:
+
+		gs://$BUCKET
+		gs://$BUCKET/$MANAGED_FOLDER/
+		bq://$PROJECT.$DATASET[.$TABLE]
+		pubsub://$PROJECT/topics/$TOPIC
+		ar://$LOCATION/$PROJECT/$REPOSITORY
+		secret://$PROJECT/$SECRET
+		kms://$PROJECT/$LOCATION/$KEY_RING[/$CRYPTO_KEY]
 `
 
 const pathListRoleSetHelpSyn = `List existing rolesets.`
@@ -570,4 +856,8 @@ const pathRoleSetRotateKeyHelpDesc = `
 This path allows you to rotate (i.e. recreate) the service account key
 used to generate access tokens under a given role set. This path only
 applies to role sets that generate access tokens and will not delete
-the associated service account.`
+the associated service account.
+
+To rotate the key automatically, set rotation_period or rotation_schedule on
+the role set. The role set read response includes the last and next rotation
+times.`
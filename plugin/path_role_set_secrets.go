@@ -42,6 +42,10 @@ func responseFieldsRoleSetAccessToken() map[string]*framework.FieldSchema {
 			Type:        framework.TypeInt,
 			Description: "Unix timestamp at which the token expires.",
 		},
+		"scopes": {
+			Type:        framework.TypeCommaStringSlice,
+			Description: "OAuth scopes granted to the token.",
+		},
 	}
 }
 
@@ -68,6 +72,12 @@ func fieldSchemaRoleSetServiceAccountKey() map[string]*framework.FieldSchema {
 			Description: "Lifetime of the service account key",
 			Query:       true,
 		},
+		"key_source": {
+			Type:        framework.TypeString,
+			Description: fmt.Sprintf(`Where the key pair is generated. If %q, Vault generates the key pair and uploads only a self-signed certificate, valid until the lease max TTL, to GCP. Defaults to %q.`, keySourceVault, keySourceGoogle),
+			Default:     keySourceGoogle,
+			Query:       true,
+		},
 	}
 }
 
@@ -77,6 +87,21 @@ func fieldSchemaRoleSetAccessToken() map[string]*framework.FieldSchema {
 			Type:        framework.TypeString,
 			Description: "Required. Name of the role set.",
 		},
+		"scopes": {
+			Type:        framework.TypeCommaStringSlice,
+			Description: "Subset of the configured token_scopes to assign to the token. Defaults to all configured scopes.",
+			Query:       true,
+		},
+		"ttl": {
+			Type:        framework.TypeDurationSecond,
+			Description: "Lifetime of the token, at most one hour. Cannot exceed the configured ttl or the mount max_ttl. Tokens generated with a service account key are always valid for one hour.",
+			Query:       true,
+		},
+		"access_boundary": {
+			Type:        framework.TypeString,
+			Description: "Credential Access Boundary JSON (raw or base64-encoded) to downscope the token with. Must be within the configured access_boundary, if any.",
+			Query:       true,
+		},
 	}
 
 }
@@ -103,6 +128,7 @@ func pathRoleSetSecretServiceAccountKey(b *backend) *framework.Path {
 						Fields:      responseFieldsRoleSetKey(),
 					}},
 				},
+				ForwardPerformanceStandby: true,
 			},
 			logical.UpdateOperation: &framework.PathOperation{
 				Callback: b.pathRoleSetSecretKey,
@@ -116,6 +142,7 @@ func pathRoleSetSecretServiceAccountKey(b *backend) *framework.Path {
 						Fields:      responseFieldsRoleSetKey(),
 					}},
 				},
+				ForwardPerformanceStandby: true,
 			},
 		},
 		HelpSynopsis:    pathServiceAccountKeySyn,
@@ -146,6 +173,7 @@ func deprecatedPathRoleSetSecretServiceAccountKey(b *backend) *framework.Path {
 						Fields:      responseFieldsRoleSetKey(),
 					}},
 				},
+				ForwardPerformanceStandby: true,
 			},
 			logical.UpdateOperation: &framework.PathOperation{
 				Callback: b.pathRoleSetSecretKey,
@@ -159,6 +187,7 @@ func deprecatedPathRoleSetSecretServiceAccountKey(b *backend) *framework.Path {
 						Fields:      responseFieldsRoleSetKey(),
 					}},
 				},
+				ForwardPerformanceStandby: true,
 			},
 		},
 		HelpSynopsis:    pathServiceAccountKeySyn,
@@ -208,6 +237,110 @@ func pathRoleSetSecretAccessToken(b *backend) *framework.Path {
 	}
 }
 
+func fieldSchemaRoleSetIdToken() map[string]*framework.FieldSchema {
+	fields := fieldSchemaIdToken()
+	fields["roleset"] = &framework.FieldSchema{
+		Type:        framework.TypeString,
+		Description: "Required. Name of the role set.",
+	}
+	return fields
+}
+
+func pathRoleSetSecretIdToken(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: fmt.Sprintf("roleset/%s/id-token", framework.GenericNameRegex("roleset")),
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixGoogleCloud,
+			OperationVerb:   "generate",
+		},
+		Fields:         fieldSchemaRoleSetIdToken(),
+		ExistenceCheck: b.pathRoleSetExistenceCheck("roleset"),
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathRoleSetSecretIdToken,
+				Summary:  "Generate an OIDC ID token for a roleset.",
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationSuffix: "roleset-id-token2",
+				},
+				Responses: map[int][]framework.Response{
+					200: {{
+						Description: "OK",
+						Fields:      responseFieldsIdToken(),
+					}},
+				},
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathRoleSetSecretIdToken,
+				Summary:  "Generate an OIDC ID token for a roleset.",
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationSuffix: "roleset-id-token",
+				},
+				Responses: map[int][]framework.Response{
+					200: {{
+						Description: "OK",
+						Fields:      responseFieldsIdToken(),
+					}},
+				},
+			},
+		},
+		HelpSynopsis:    pathIdTokenHelpSyn,
+		HelpDescription: pathIdTokenHelpDesc,
+	}
+}
+
+func fieldSchemaRoleSetHmacKey() map[string]*framework.FieldSchema {
+	fields := fieldSchemaHmacKey()
+	fields["roleset"] = &framework.FieldSchema{
+		Type:        framework.TypeString,
+		Description: "Required. Name of the role set.",
+	}
+	return fields
+}
+
+func pathRoleSetSecretHmacKey(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: fmt.Sprintf("roleset/%s/hmac-key", framework.GenericNameRegex("roleset")),
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixGoogleCloud,
+			OperationVerb:   "generate",
+		},
+		Fields:         fieldSchemaRoleSetHmacKey(),
+		ExistenceCheck: b.pathRoleSetExistenceCheck("roleset"),
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathRoleSetSecretHmacKey,
+				Summary:  "Generate a Cloud Storage HMAC key for a roleset.",
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationSuffix: "roleset-hmac-key2",
+				},
+				Responses: map[int][]framework.Response{
+					200: {{
+						Description: "OK",
+						Fields:      responseFieldsHmacKey(),
+					}},
+				},
+				ForwardPerformanceStandby: true,
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathRoleSetSecretHmacKey,
+				Summary:  "Generate a Cloud Storage HMAC key for a roleset.",
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationSuffix: "roleset-hmac-key",
+				},
+				Responses: map[int][]framework.Response{
+					200: {{
+						Description: "OK",
+						Fields:      responseFieldsHmacKey(),
+					}},
+				},
+				ForwardPerformanceStandby: true,
+			},
+		},
+		HelpSynopsis:    pathHmacKeySyn,
+		HelpDescription: pathHmacKeyDesc,
+	}
+}
+
 func deprecatedPathRoleSetSecretAccessToken(b *backend) *framework.Path {
 	return &framework.Path{
 		Pattern: fmt.Sprintf("token/%s", framework.GenericNameRegex("roleset")),
@@ -255,6 +388,7 @@ func (b *backend) pathRoleSetSecretKey(ctx context.Context, req *logical.Request
 	rsName := d.Get("roleset").(string)
 	keyType := d.Get("key_type").(string)
 	keyAlg := d.Get("key_algorithm").(string)
+	keySource := d.Get("key_source").(string)
 	ttl := d.Get("ttl").(int)
 
 	rs, err := getRoleSet(rsName, ctx, req.Storage)
@@ -272,6 +406,7 @@ func (b *backend) pathRoleSetSecretKey(ctx context.Context, req *logical.Request
 	params := secretKeyParams{
 		keyType:      keyType,
 		keyAlgorithm: keyAlg,
+		keySource:    keySource,
 		ttl:          ttl,
 		extraInternalData: map[string]interface{}{
 			"role_set":          rs.Name,
@@ -279,7 +414,33 @@ func (b *backend) pathRoleSetSecretKey(ctx context.Context, req *logical.Request
 		},
 	}
 
-	return b.createServiceAccountKeySecret(ctx, req.Storage, rs.AccountId, params)
+	if !rs.usesKeyPool() {
+		return b.createServiceAccountKeySecret(ctx, req.Storage, rs.AccountId, params)
+	}
+
+	// Hold the role set's key pool lock until the key is indexed, so concurrent requests see its
+	// account's load.
+	poolLock := b.keyPoolLock(rsName)
+	poolLock.Lock()
+	defer poolLock.Unlock()
+
+	// Re-read the role set, as the pool may have changed while waiting for the lock.
+	rs, err = getRoleSet(rsName, ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if rs == nil {
+		return logical.ErrorResponse("role set %q does not exists", rsName), nil
+	}
+
+	accountId, err := b.poolAccountForKey(ctx, req, rs)
+	if err == logical.ErrReadOnly {
+		return nil, err
+	}
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	return b.createServiceAccountKeySecret(ctx, req.Storage, accountId, params)
 }
 
 func (b *backend) pathRoleSetSecretAccessToken(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
@@ -293,9 +454,77 @@ func (b *backend) pathRoleSetSecretAccessToken(ctx context.Context, req *logical
 		return logical.ErrorResponse("role set '%s' does not exists", rsName), nil
 	}
 
-	if rs.SecretType != SecretTypeAccessToken {
+	if !isAccessTokenSecretType(rs.SecretType) {
 		return logical.ErrorResponse("role set '%s' cannot generate access tokens (has secret type %s)", rsName, rs.SecretType), nil
 	}
 
-	return b.secretAccessTokenResponse(ctx, req.Storage, rs.TokenGen)
+	cfg, err := getConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	params, err := parseAccessTokenParams(d, rs.tokenScopes(), rs.AccessBoundary, b.maxTokenTTL(cfg))
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	if rs.SecretType == SecretTypeImpersonatedAccessToken {
+		return b.secretImpersonatedAccessTokenResponse(ctx, req.Storage, rs.AccountId, rs.TokenImpersonator, params)
+	}
+	return b.secretAccessTokenResponse(ctx, req.Storage, rs.AccountId, rs.TokenGen, params)
+}
+
+func (b *backend) pathRoleSetSecretIdToken(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	rsName := d.Get("roleset").(string)
+
+	rs, err := getRoleSet(rsName, ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if rs == nil {
+		return logical.ErrorResponse("role set '%s' does not exists", rsName), nil
+	}
+
+	params, err := parseIdTokenParams(d, rs.AllowedAudiences)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	switch rs.SecretType {
+	case SecretTypeAccessToken:
+		return b.secretIdTokenResponse(ctx, rs.TokenGen, params)
+	case SecretTypeImpersonatedAccessToken:
+		if rs.AccountId == nil {
+			return logical.ErrorResponse("role set '%s' has no service account", rsName), nil
+		}
+		return b.secretImpersonatedIdTokenResponse(ctx, req.Storage, rs.AccountId.EmailOrId, nil, params)
+	default:
+		return logical.ErrorResponse("role set '%s' cannot generate ID tokens (has secret type %s)", rsName, rs.SecretType), nil
+	}
+}
+
+func (b *backend) pathRoleSetSecretHmacKey(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	rsName := d.Get("roleset").(string)
+	ttl := d.Get("ttl").(int)
+
+	rs, err := getRoleSet(rsName, ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if rs == nil {
+		return logical.ErrorResponse("role set %q does not exists", rsName), nil
+	}
+
+	if rs.SecretType != SecretTypeHmacKey {
+		return logical.ErrorResponse("role set %q cannot generate HMAC keys (has secret type %s)", rsName, rs.SecretType), nil
+	}
+
+	params := secretHmacKeyParams{
+		ttl: ttl,
+		extraInternalData: map[string]interface{}{
+			"role_set":          rs.Name,
+			"role_set_bindings": rs.bindingHash(),
+		},
+	}
+
+	return b.createHmacKeySecret(ctx, req.Storage, rs.AccountId, params)
 }
@@ -15,7 +15,12 @@ import (
 
 func TestSecrets_getRoleSetAccessToken(t *testing.T) {
 	rsName := "test-gentoken"
-	testGetRoleSetAccessToken(t, rsName, fmt.Sprintf("roleset/%s/token", rsName))
+	testGetRoleSetAccessToken(t, rsName, fmt.Sprintf("roleset/%s/token", rsName), SecretTypeAccessToken)
+}
+
+func TestSecrets_getRoleSetImpersonatedAccessToken(t *testing.T) {
+	rsName := "test-genimptoken"
+	testGetRoleSetAccessToken(t, rsName, fmt.Sprintf("roleset/%s/token", rsName), SecretTypeImpersonatedAccessToken)
 }
 
 func TestSecrets_getRoleSetKey(t *testing.T) {
@@ -31,7 +36,7 @@ func TestSecrets_roleSetBadResource(t *testing.T) {
 // Test deprecated path still works
 func TestSecretsDeprecated_getRoleSetAccessToken(t *testing.T) {
 	rsName := "test-gentoken"
-	testGetRoleSetAccessToken(t, rsName, fmt.Sprintf("token/%s", rsName))
+	testGetRoleSetAccessToken(t, rsName, fmt.Sprintf("token/%s", rsName), SecretTypeAccessToken)
 }
 
 // Test deprecated path still works
@@ -40,9 +45,7 @@ func TestSecretsDeprecated_getRoleSetKey(t *testing.T) {
 	testGetRoleSetKey(t, rsName, fmt.Sprintf("key/%s", rsName))
 }
 
-func testGetRoleSetAccessToken(t *testing.T, rsName, path string) {
-	secretType := SecretTypeAccessToken
-
+func testGetRoleSetAccessToken(t *testing.T, rsName, path, secretType string) {
 	td := setupTest(t, "0s", "2h")
 	defer cleanupRoleset(t, td, rsName, testRoles)
 
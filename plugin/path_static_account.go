@@ -7,9 +7,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/hashicorp/errwrap"
 	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/automatedrotationutil"
 	"github.com/hashicorp/vault/sdk/logical"
 )
 
@@ -20,7 +22,7 @@ const (
 )
 
 func pathStaticAccount(b *backend) *framework.Path {
-	return &framework.Path{
+	p := &framework.Path{
 		Pattern: fmt.Sprintf("%s/%s", staticAccountPathPrefix, framework.GenericNameRegex("name")),
 		DisplayAttrs: &framework.DisplayAttributes{
 			OperationPrefix: operationPrefixGoogleCloud,
@@ -42,11 +44,31 @@ func pathStaticAccount(b *backend) *framework.Path {
 			},
 			"bindings": {
 				Type:        framework.TypeString,
-				Description: "Bindings configuration string.",
+				Description: "Bindings configuration string, as HCL or JSON. May be base64-encoded.",
 			},
 			"token_scopes": {
 				Type:        framework.TypeCommaStringSlice,
-				Description: fmt.Sprintf(`List of OAuth scopes to assign to access tokens generated under this account. Ignored if "secret_type" is not "%q"`, SecretTypeAccessToken),
+				Description: fmt.Sprintf(`List of OAuth scopes to assign to access tokens generated under this account. Ignored if "secret_type" is not %q or %q`, SecretTypeAccessToken, SecretTypeImpersonatedAccessToken),
+			},
+			"allowed_audiences": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: "List of audience patterns (globs allowed) that ID tokens generated under this account may be issued for. If empty, any audience is allowed.",
+			},
+			"access_boundary": {
+				Type:        framework.TypeString,
+				Description: "Credential Access Boundary JSON (raw or base64-encoded) used to downscope access tokens generated under this account. Set to an empty string to remove.",
+			},
+			"credential_mode": {
+				Type:        framework.TypeString,
+				Description: fmt.Sprintf("How %q secrets are issued. %q (default) creates a leased key per request; %q keeps a single Vault-owned key, returned by the creds endpoint, that is rotated every rotation_period. Cannot be updated.", SecretTypeKey, credentialModeLeased, credentialModeRotating),
+			},
+			"overlap_period": {
+				Type:        framework.TypeDurationSecond,
+				Description: fmt.Sprintf("In %q credential mode, how long the previous key is kept after a rotation. Must be less than rotation_period. Defaults to 0, which deletes the previous key on rotation.", credentialModeRotating),
+			},
+			"dry_run": {
+				Type:        framework.TypeBool,
+				Description: dryRunFieldDescription,
 			},
 		},
 		ExistenceCheck: b.pathStaticAccountExistenceCheck,
@@ -64,7 +86,7 @@ func pathStaticAccount(b *backend) *framework.Path {
 				Responses: map[int][]framework.Response{
 					200: {{
 						Description: "OK",
-						Fields: map[string]*framework.FieldSchema{
+						Fields: withTokenKeyRotationResponseFields(map[string]*framework.FieldSchema{
 							"service_account_project": {
 								Type:        framework.TypeString,
 								Description: "GCP project of the static service account.",
@@ -81,11 +103,43 @@ func pathStaticAccount(b *backend) *framework.Path {
 								Type:        framework.TypeString,
 								Description: "Bindings configuration for the static account.",
 							},
+							"bindings_structured": {
+								Type:        framework.TypeSlice,
+								Description: "Normalized bindings as a sorted list of resources with their roles and condition. Empty if the static account has no bindings.",
+							},
+							"conditional_bindings": {
+								Type:        framework.TypeSlice,
+								Description: "Bindings granted under IAM Conditions, with their condition.",
+							},
 							"token_scopes": {
 								Type:        framework.TypeSlice,
 								Description: "OAuth scopes for access tokens generated under this static account.",
 							},
-						},
+							"allowed_audiences": {
+								Type:        framework.TypeSlice,
+								Description: "Audience patterns allowed for ID tokens generated under this static account.",
+							},
+							"access_boundary": {
+								Type:        framework.TypeMap,
+								Description: "Credential Access Boundary used to downscope access tokens.",
+							},
+							"credential_mode": {
+								Type:        framework.TypeString,
+								Description: "How service account keys are issued for this static account.",
+							},
+							"overlap_period": {
+								Type:        framework.TypeInt,
+								Description: "Time in seconds the previous key is kept after a rotation.",
+							},
+							"last_rotated": {
+								Type:        framework.TypeString,
+								Description: "Time the current key was created.",
+							},
+							"ttl_until_rotation": {
+								Type:        framework.TypeInt,
+								Description: "Time in seconds until the current key is rotated.",
+							},
+						}),
 					}},
 				},
 			},
@@ -93,6 +147,10 @@ func pathStaticAccount(b *backend) *framework.Path {
 				Callback: b.pathStaticAccountCreate,
 				Summary:  "Create a static account.",
 				Responses: map[int][]framework.Response{
+					200: {{
+						Description: "OK",
+						Fields:      responseFieldsDryRun(),
+					}},
 					204: {{Description: "No Content"}},
 				},
 				ForwardPerformanceStandby:   true,
@@ -102,6 +160,10 @@ func pathStaticAccount(b *backend) *framework.Path {
 				Callback: b.pathStaticAccountUpdate,
 				Summary:  "Update a static account.",
 				Responses: map[int][]framework.Response{
+					200: {{
+						Description: "OK",
+						Fields:      responseFieldsDryRun(),
+					}},
 					204: {{Description: "No Content"}},
 				},
 				ForwardPerformanceStandby:   true,
@@ -111,6 +173,10 @@ func pathStaticAccount(b *backend) *framework.Path {
 		HelpSynopsis:    pathStaticAccountHelpSyn,
 		HelpDescription: pathStaticAccountHelpDesc,
 	}
+
+	automatedrotationutil.AddAutomatedRotationFields(p.Fields)
+
+	return p
 }
 
 func pathStaticAccountList(b *backend) *framework.Path {
@@ -181,8 +247,34 @@ func (b *backend) pathStaticAccountRead(ctx context.Context, req *logical.Reques
 	if len(acct.Bindings) > 0 {
 		data["bindings"] = acct.Bindings.asOutput()
 	}
-	if acct.TokenGen != nil && acct.SecretType == SecretTypeAccessToken {
-		data["token_scopes"] = acct.TokenGen.Scopes
+	if len(acct.ConditionalBindings) > 0 {
+		data["conditional_bindings"] = acct.ConditionalBindings.asOutput()
+	}
+	data["bindings_structured"] = structuredBindings(acct.Bindings, acct.ConditionalBindings)
+	if aliases := resourceAliases(acct.Bindings, acct.ConditionalBindings); len(aliases) > 0 {
+		data["resource_aliases"] = aliases
+	}
+	if isAccessTokenSecretType(acct.SecretType) {
+		data["token_scopes"] = acct.tokenScopes()
+	}
+	if len(acct.AllowedAudiences) > 0 {
+		data["allowed_audiences"] = acct.AllowedAudiences
+	}
+	if acct.AccessBoundary != nil {
+		data["access_boundary"] = acct.AccessBoundary.asOutput()
+	}
+	if acct.SecretType == SecretTypeAccessToken {
+		populateTokenKeyRotationData(data, &acct.AutomatedRotationParams, acct.TokenGen)
+	}
+	if acct.SecretType == SecretTypeKey {
+		data["credential_mode"] = acct.credentialMode()
+	}
+	if rk := acct.RotatingKey; rk != nil {
+		data["rotation_period"] = int64(rk.RotationPeriod / time.Second)
+		data["overlap_period"] = int64(rk.OverlapPeriod / time.Second)
+		for k, v := range rotatingKeyTimes(rk) {
+			data[k] = v
+		}
 	}
 
 	return &logical.Response{
@@ -208,6 +300,10 @@ func (b *backend) pathStaticAccountDelete(ctx context.Context, req *logical.Requ
 		return nil, nil
 	}
 
+	if err := b.deregisterRotationJob(ctx, req, &acct.AutomatedRotationParams); err != nil {
+		return nil, errwrap.Wrapf(fmt.Sprintf("unable to deregister key rotation job for static account %q: {{err}}", name), err)
+	}
+
 	resources := acct.boundResources()
 
 	// Add WALs
@@ -215,6 +311,14 @@ func (b *backend) pathStaticAccountDelete(ctx context.Context, req *logical.Requ
 	if err != nil {
 		return nil, errwrap.Wrapf(fmt.Sprintf("unable to create WALs for static account GCP resources %s: {{err}}", name), err)
 	}
+	rotatingKeyWalIds := make(map[string]string)
+	for _, keyName := range acct.rotatingKeyNames() {
+		walId, err := b.addWalStaticAccountServiceAccountKey(ctx, req, name, &acct.ServiceAccountId, keyName)
+		if err != nil {
+			return nil, errwrap.Wrapf(fmt.Sprintf("unable to create WALs for static account GCP resources %s: {{err}}", name), err)
+		}
+		rotatingKeyWalIds[keyName] = walId
+	}
 
 	// Delete static account
 	b.Logger().Debug("deleting static account from storage", "name", name)
@@ -223,7 +327,9 @@ func (b *backend) pathStaticAccountDelete(ctx context.Context, req *logical.Requ
 	}
 
 	// Try to clean up resources.
-	if warnings := b.tryDeleteStaticAccountResources(ctx, req, resources, walIds); len(warnings) > 0 {
+	warnings := b.tryDeleteStaticAccountResources(ctx, req, resources, walIds)
+	warnings = append(warnings, b.tryDeleteRotatingKeys(ctx, req, rotatingKeyWalIds)...)
+	if len(warnings) > 0 {
 		b.Logger().Debug(
 			"unable to delete GCP resources for deleted static account but WALs exist to clean up, ignoring errors",
 			"static account", name, "errors", warnings)
@@ -246,10 +352,41 @@ func (b *backend) pathStaticAccountCreate(ctx context.Context, req *logical.Requ
 	b.staticAccountLock.Lock()
 	defer b.staticAccountLock.Unlock()
 
+	ctx, conflicts := withPolicyConflicts(ctx)
+	if d.Get("dry_run").(bool) {
+		resp, err := b.planStaticAccountUpdate(ctx, req, nil, input)
+		if resp != nil {
+			resp.Warnings = append(resp.Warnings, warnings...)
+		}
+		return resp, err
+	}
+
+	check := newPermissionCheck()
+	check.addPolicyResources(input.bindings, input.conditionalBindings)
+	if input.secretType == SecretTypeAccessToken || input.credentialMode == credentialModeRotating {
+		check.add(fmt.Sprintf(serviceAccountResourceTmpl, gcpServiceAccountInferredProject, input.serviceAccountEmail), permissionCreateServiceAccountKey)
+	}
+	permWarns, err := b.checkPermissions(ctx, req, check)
+	if err != nil {
+		return permissionCheckErrorResponse(err), nil
+	}
+	warnings = append(warnings, permWarns...)
+
 	// Create and save static account with new resources.
-	if err := b.createStaticAccount(ctx, req, input); err != nil {
+	if input.credentialMode == credentialModeRotating {
+		err = b.createStaticAccount(ctx, req, input)
+	} else {
+		err = b.saveWithRotationJob(ctx, req, d, &input.rotation, &input.rotationInfo, func() error {
+			return b.createStaticAccount(ctx, req, input)
+		})
+	}
+	if err != nil {
 		return logical.ErrorResponse(err.Error()), nil
 	}
+	if w := conflicts.warning(); w != "" {
+		warnings = append(warnings, w)
+	}
+	warnings = append(warnings, tokenKeyRotationWarnings(&input.rotation)...)
 	if len(warnings) > 0 {
 		return &logical.Response{Warnings: warnings}, nil
 	}
@@ -266,6 +403,7 @@ func (b *backend) pathStaticAccountUpdate(ctx context.Context, req *logical.Requ
 	b.staticAccountLock.Lock()
 	defer b.staticAccountLock.Unlock()
 
+	ctx, conflicts := withPolicyConflicts(ctx)
 	acct, err := b.getStaticAccount(name, ctx, req.Storage)
 	if err != nil {
 		return nil, err
@@ -279,11 +417,19 @@ func (b *backend) pathStaticAccountUpdate(ctx context.Context, req *logical.Requ
 		secretType:          acct.SecretType,
 		rawBindings:         acct.RawBindings,
 		bindings:            acct.Bindings,
+		conditionalBindings: acct.ConditionalBindings,
 		project:             acct.Project,
 		serviceAccountEmail: acct.EmailOrId,
+		allowedAudiences:    acct.AllowedAudiences,
+		accessBoundary:      acct.AccessBoundary,
+		rotation:            acct.AutomatedRotationParams,
+		rotationInfo:        acct.RotationInfoResponseParams,
+		credentialMode:      acct.credentialMode(),
 	}
-	if acct.TokenGen != nil {
-		initialInput.scopes = acct.TokenGen.Scopes
+	initialInput.scopes = acct.tokenScopes()
+	if acct.RotatingKey != nil {
+		initialInput.keyRotationPeriod = acct.RotatingKey.RotationPeriod
+		initialInput.keyOverlapPeriod = acct.RotatingKey.OverlapPeriod
 	}
 
 	updateInput, warnings, err := b.parseStaticAccountInformation(initialInput, d)
@@ -294,11 +440,42 @@ func (b *backend) pathStaticAccountUpdate(ctx context.Context, req *logical.Requ
 		return nil, fmt.Errorf("plugin error - parse returned unexpected nil input")
 	}
 
-	updateWarns, err := b.updateStaticAccount(ctx, req, acct, updateInput)
+	if d.Get("dry_run").(bool) {
+		resp, err := b.planStaticAccountUpdate(ctx, req, acct, updateInput)
+		if resp != nil {
+			resp.Warnings = append(resp.Warnings, warnings...)
+		}
+		return resp, err
+	}
+
+	if updateInput.hasBindings && updateInput.rawBindings != acct.RawBindings {
+		check := newPermissionCheck()
+		check.addPolicyResources(updateInput.bindings, updateInput.conditionalBindings)
+		check.addPolicyResources(acct.Bindings, acct.ConditionalBindings)
+		permWarns, err := b.checkPermissions(ctx, req, check)
+		if err != nil {
+			return permissionCheckErrorResponse(err), nil
+		}
+		warnings = append(warnings, permWarns...)
+	}
+
+	var updateWarns []string
+	if updateInput.credentialMode == credentialModeRotating {
+		updateWarns, err = b.updateStaticAccount(ctx, req, acct, updateInput)
+	} else {
+		err = b.saveWithRotationJob(ctx, req, d, &updateInput.rotation, &updateInput.rotationInfo, func() (err error) {
+			updateWarns, err = b.updateStaticAccount(ctx, req, acct, updateInput)
+			return err
+		})
+	}
 	if err != nil {
 		return logical.ErrorResponse("unable to update: %s", err), nil
 	}
 	warnings = append(warnings, updateWarns...)
+	if w := conflicts.warning(); w != "" {
+		warnings = append(warnings, w)
+	}
+	warnings = append(warnings, tokenKeyRotationWarnings(&updateInput.rotation)...)
 	if len(warnings) > 0 {
 		return &logical.Response{Warnings: warnings}, nil
 	}
@@ -355,6 +532,33 @@ func (b *backend) parseStaticAccountInformation(prevValues *inputParams, d *fram
 		warnings = append(warnings, ws...)
 	}
 
+	if audiences, ok := d.GetOk("allowed_audiences"); ok {
+		input.allowedAudiences = audiences.([]string)
+	}
+
+	ws, err = input.parseOkInputAccessBoundary(d)
+	if err != nil {
+		return nil, nil, err
+	} else if len(ws) > 0 {
+		warnings = append(warnings, ws...)
+	}
+
+	ws, err = input.parseOkInputCredentialMode(d)
+	if err != nil {
+		return nil, nil, err
+	} else if len(ws) > 0 {
+		warnings = append(warnings, ws...)
+	}
+
+	// In rotating credential mode, the plugin rotates the key every rotation_period itself.
+	if input.credentialMode == credentialModeRotating {
+		if automatedRotationFieldsSet(d, "rotation_period") {
+			return nil, nil, fmt.Errorf("only rotation_period is supported for %q credential mode", credentialModeRotating)
+		}
+	} else if automatedRotationFieldsSet(d) && input.secretType != SecretTypeAccessToken {
+		return nil, nil, fmt.Errorf("automated key rotation is only supported for %q static accounts", SecretTypeAccessToken)
+	}
+
 	return input, warnings, nil
 }
 
@@ -364,6 +568,18 @@ This path allows you to register a static GCP service account that you want to g
 This creates sets of IAM roles to specific GCP resources. Secrets (either service account keys or
 access tokens) are generated under this account. The account must exist at creation of static account creation.
 
+Set dry_run=true to see the IAM policy changes a create or update would make on
+each bound resource without changing anything.
+
+Before changing bindings, Vault checks that its credentials can get and set the
+IAM policy of every bound resource, and create keys for the service account if
+it needs one. Missing permissions are returned together in missing_permissions.
+
+For service_account_key static accounts, credential_mode=rotating makes Vault
+own a single key instead of creating a leased key per request, like database
+static roles. The key is returned by static-account/<name>/creds, rotated every
+rotation_period, and the replaced key is deleted once overlap_period ends.
+
 If bindings are specified, Vault will assign IAM permissions to the given service account. Bindings
 can be given as a HCL (or JSON) string with the following format:
 
@@ -376,6 +592,29 @@ resource "some/gcp/resource/uri" {
 	]
 }
 
+A resource block can include a condition block to only grant its roles under an
+IAM Condition. The title and expression are required:
+
+resource "some/gcp/resource/uri" {
+	roles = ["roles/role1"]
+	condition {
+		title       = "expires-2030"
+		description = "Access until 2030"
+		expression  = "request.time < timestamp(\"2030-01-01T00:00:00Z\")"
+	}
+}
+
+BigQuery datasets do not support IAM Conditions.
+
+Bindings can also be given as a JSON document, either a map of resource to roles:
+
+{"some/gcp/resource/uri": ["roles/role1", "roles/role2"]}
+
+or a list of resources with their roles and an optional condition, in the same
+format as the bindings_structured field returned on read:
+
+[{"resource": "some/gcp/resource/uri", "roles": ["roles/role1"], "condition": {...}}]
+
 The given resource can have the following
 
 * Project-level self link
@@ -411,6 +650,20 @@ The given resource can have the following
 
 	Example (Pubsub subscription):
 		projects/myproject/subscriptions/mysub
+
+* Short Resource URI:
+	A short form for common resource types, which is
+	rewritten to the full resource name. Read responses
+	include the full resource name of each alias in
+	resource_aliases. Supported forms:
+
+		gs://$BUCKET
+		gs://$BUCKET/$MANAGED_FOLDER/
+		bq://$PROJECT.$DATASET[.$TABLE]
+		pubsub://$PROJECT/topics/$TOPIC
+		ar://$LOCATION/$PROJECT/$REPOSITORY
+		secret://$PROJECT/$SECRET
+		kms://$PROJECT/$LOCATION/$KEY_RING[/$CRYPTO_KEY]
 `
 
 const pathListStaticAccountHelpSyn = `List created static accounts.`
@@ -60,57 +60,27 @@ func (b *backend) pathStaticAccountRotateKey(ctx context.Context, req *logical.R
 		return logical.ErrorResponse("account '%s' not found", name), nil
 	}
 
-	if acct.SecretType != SecretTypeAccessToken {
-		return logical.ErrorResponse("cannot rotate key for non-access-token static account"), nil
-	}
-
-	if acct.TokenGen == nil {
-		return nil, fmt.Errorf("unexpected invalid account has no TokenGen")
-	}
-
-	scopes := acct.TokenGen.Scopes
-	oldTokenGen := acct.TokenGen
-	oldWalId, err := b.addWalRoleSetServiceAccountKey(ctx, req, acct.Name, &acct.ServiceAccountId, oldTokenGen.KeyName)
-	if err != nil {
-		return nil, err
-	}
-
-	// Add WALs for new TokenGen - since we don't have a key ID yet, give an empty key name so WAL
-	// will know to just clear keys that aren't being used. This also covers up cleaning up
-	// the old token generator, so we don't add a separate WAL for that.
-	newWalId, err := b.addWalRoleSetServiceAccountKey(ctx, req, acct.Name, &acct.ServiceAccountId, "")
-	if err != nil {
-		return nil, err
-	}
-
-	newTokenGen, err := b.createNewTokenGen(ctx, req, acct.ResourceName(), scopes)
-	if err != nil {
-		return nil, err
+	if acct.credentialMode() == credentialModeRotating {
+		warnings, err := b.rotateStaticAccountKey(ctx, req, acct)
+		if err != nil {
+			return nil, err
+		}
+		if len(warnings) > 0 {
+			return &logical.Response{Warnings: warnings}, nil
+		}
+		return nil, nil
 	}
 
-	// Edit roleset with new key and save to storage.
-	acct.TokenGen = newTokenGen
-	if err := acct.save(ctx, req.Storage); err != nil {
-		return nil, err
+	if acct.SecretType != SecretTypeAccessToken {
+		return logical.ErrorResponse("cannot rotate key for non-access-token static account"), nil
 	}
 
-	// Try deleting the old key.
-	iamAdmin, err := b.IAMAdminClient(req.Storage)
+	warn, err := b.saveStaticAccountWithNewTokenKey(ctx, req, acct)
 	if err != nil {
 		return nil, err
 	}
-
-	b.tryDeleteWALs(ctx, req.Storage, newWalId)
-
-	if oldTokenGen != nil {
-		if err := b.deleteTokenGenKey(ctx, iamAdmin, oldTokenGen); err != nil {
-			return &logical.Response{
-				Warnings: []string{
-					fmt.Sprintf("saved static account with new token generator service account key but failed to delete old key (covered by WAL): %v", err),
-				},
-			}, nil
-		}
-		b.tryDeleteWALs(ctx, req.Storage, oldWalId)
+	if warn != "" {
+		return &logical.Response{Warnings: []string{warn}}, nil
 	}
 	return nil, nil
 }
@@ -124,4 +94,12 @@ It will not delete the associated service account or change bindings.
 
 Note that this will not invalidate access tokens created with the old key.
 The only way to do so is to delete the service account.
+
+To rotate the key automatically, set rotation_period or rotation_schedule on
+the static account. The static account read response includes the last and
+next rotation times.
+
+For a static account in "rotating" credential mode, this path instead rotates
+the Vault-owned service account key returned by the creds endpoint. The
+replaced key is kept until the overlap_period ends.
 `
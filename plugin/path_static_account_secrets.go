@@ -42,6 +42,10 @@ func responseFieldsStaticAccountAccessToken() map[string]*framework.FieldSchema
 			Type:        framework.TypeInt,
 			Description: "Unix timestamp at which the token expires.",
 		},
+		"scopes": {
+			Type:        framework.TypeCommaStringSlice,
+			Description: "OAuth scopes granted to the token.",
+		},
 	}
 }
 
@@ -74,6 +78,12 @@ func pathStaticAccountSecretServiceAccountKey(b *backend) *framework.Path {
 				Description: "Lifetime of the service account key",
 				Query:       true,
 			},
+			"key_source": {
+				Type:        framework.TypeString,
+				Description: fmt.Sprintf(`Where the key pair is generated. If %q, Vault generates the key pair and uploads only a self-signed certificate, valid until the lease max TTL, to GCP. Defaults to %q.`, keySourceVault, keySourceGoogle),
+				Default:     keySourceGoogle,
+				Query:       true,
+			},
 		},
 		Operations: map[logical.Operation]framework.OperationHandler{
 			logical.ReadOperation: &framework.PathOperation{
@@ -88,6 +98,7 @@ func pathStaticAccountSecretServiceAccountKey(b *backend) *framework.Path {
 						Fields:      responseFieldsStaticAccountKey(),
 					}},
 				},
+				ForwardPerformanceStandby: true,
 			},
 			logical.UpdateOperation: &framework.PathOperation{
 				Callback: b.pathStaticAccountSecretKey,
@@ -101,6 +112,7 @@ func pathStaticAccountSecretServiceAccountKey(b *backend) *framework.Path {
 						Fields:      responseFieldsStaticAccountKey(),
 					}},
 				},
+				ForwardPerformanceStandby: true,
 			},
 		},
 		HelpSynopsis:    pathServiceAccountKeySyn,
@@ -120,6 +132,21 @@ func pathStaticAccountSecretAccessToken(b *backend) *framework.Path {
 				Type:        framework.TypeString,
 				Description: "Required. Name of the static account.",
 			},
+			"scopes": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: "Subset of the configured token_scopes to assign to the token. Defaults to all configured scopes.",
+				Query:       true,
+			},
+			"ttl": {
+				Type:        framework.TypeDurationSecond,
+				Description: "Lifetime of the token, at most one hour. Cannot exceed the configured ttl or the mount max_ttl. Tokens generated with a service account key are always valid for one hour.",
+				Query:       true,
+			},
+			"access_boundary": {
+				Type:        framework.TypeString,
+				Description: "Credential Access Boundary JSON (raw or base64-encoded) to downscope the token with. Must be within the configured access_boundary, if any.",
+				Query:       true,
+			},
 		},
 		Operations: map[logical.Operation]framework.OperationHandler{
 			logical.ReadOperation: &framework.PathOperation{
@@ -154,10 +181,157 @@ func pathStaticAccountSecretAccessToken(b *backend) *framework.Path {
 	}
 }
 
+func responseFieldsStaticAccountCreds() map[string]*framework.FieldSchema {
+	fields := responseFieldsStaticAccountKey()
+	fields["last_rotated"] = &framework.FieldSchema{
+		Type:        framework.TypeString,
+		Description: "Time the current key was created.",
+	}
+	fields["ttl_until_rotation"] = &framework.FieldSchema{
+		Type:        framework.TypeInt,
+		Description: "Time in seconds until the current key is rotated.",
+	}
+	return fields
+}
+
+func pathStaticAccountCreds(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: fmt.Sprintf("%s/%s/creds", staticAccountPathPrefix, framework.GenericNameRegex("name")),
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixGoogleCloud,
+			OperationVerb:   "read",
+			OperationSuffix: "static-account-credentials",
+		},
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Required. Name of the static account.",
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathStaticAccountCredsRead,
+				Summary:  "Read the current service account key of a static account in rotating credential mode.",
+				Responses: map[int][]framework.Response{
+					200: {{
+						Description: "OK",
+						Fields:      responseFieldsStaticAccountCreds(),
+					}},
+				},
+			},
+		},
+		HelpSynopsis:    pathStaticAccountCredsHelpSyn,
+		HelpDescription: pathStaticAccountCredsHelpDesc,
+	}
+}
+
+func fieldSchemaStaticAccountIdToken() map[string]*framework.FieldSchema {
+	fields := fieldSchemaIdToken()
+	fields["name"] = &framework.FieldSchema{
+		Type:        framework.TypeString,
+		Description: "Required. Name of the static account.",
+	}
+	return fields
+}
+
+func pathStaticAccountSecretIdToken(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: fmt.Sprintf("%s/%s/id-token", staticAccountPathPrefix, framework.GenericNameRegex("name")),
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixGoogleCloud,
+			OperationVerb:   "generate",
+		},
+		Fields: fieldSchemaStaticAccountIdToken(),
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathStaticAccountIdToken,
+				Summary:  "Generate an OIDC ID token for a static account.",
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationSuffix: "static-account-id-token2",
+				},
+				Responses: map[int][]framework.Response{
+					200: {{
+						Description: "OK",
+						Fields:      responseFieldsIdToken(),
+					}},
+				},
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathStaticAccountIdToken,
+				Summary:  "Generate an OIDC ID token for a static account.",
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationSuffix: "static-account-id-token",
+				},
+				Responses: map[int][]framework.Response{
+					200: {{
+						Description: "OK",
+						Fields:      responseFieldsIdToken(),
+					}},
+				},
+			},
+		},
+		HelpSynopsis:    pathIdTokenHelpSyn,
+		HelpDescription: pathIdTokenHelpDesc,
+	}
+}
+
+func fieldSchemaStaticAccountHmacKey() map[string]*framework.FieldSchema {
+	fields := fieldSchemaHmacKey()
+	fields["name"] = &framework.FieldSchema{
+		Type:        framework.TypeString,
+		Description: "Required. Name of the static account.",
+	}
+	return fields
+}
+
+func pathStaticAccountSecretHmacKey(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: fmt.Sprintf("%s/%s/hmac-key", staticAccountPathPrefix, framework.GenericNameRegex("name")),
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixGoogleCloud,
+			OperationVerb:   "generate",
+		},
+		Fields: fieldSchemaStaticAccountHmacKey(),
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathStaticAccountSecretHmacKey,
+				Summary:  "Generate a Cloud Storage HMAC key for a static account.",
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationSuffix: "static-account-hmac-key2",
+				},
+				Responses: map[int][]framework.Response{
+					200: {{
+						Description: "OK",
+						Fields:      responseFieldsHmacKey(),
+					}},
+				},
+				ForwardPerformanceStandby: true,
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathStaticAccountSecretHmacKey,
+				Summary:  "Generate a Cloud Storage HMAC key for a static account.",
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationSuffix: "static-account-hmac-key",
+				},
+				Responses: map[int][]framework.Response{
+					200: {{
+						Description: "OK",
+						Fields:      responseFieldsHmacKey(),
+					}},
+				},
+				ForwardPerformanceStandby: true,
+			},
+		},
+		HelpSynopsis:    pathHmacKeySyn,
+		HelpDescription: pathHmacKeyDesc,
+	}
+}
+
 func (b *backend) pathStaticAccountSecretKey(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
 	acctName := d.Get("name").(string)
 	keyType := d.Get("key_type").(string)
 	keyAlg := d.Get("key_algorithm").(string)
+	keySource := d.Get("key_source").(string)
 	ttl := d.Get("ttl").(int)
 
 	acct, err := b.getStaticAccount(acctName, ctx, req.Storage)
@@ -170,10 +344,14 @@ func (b *backend) pathStaticAccountSecretKey(ctx context.Context, req *logical.R
 	if acct.SecretType != SecretTypeKey {
 		return logical.ErrorResponse("static account %q cannot generate service account keys (has secret type %s)", acctName, acct.SecretType), nil
 	}
+	if acct.credentialMode() == credentialModeRotating {
+		return logical.ErrorResponse("static account %q uses %q credential mode, read its key from %s/%s/creds", acctName, credentialModeRotating, staticAccountPathPrefix, acctName), nil
+	}
 
 	params := secretKeyParams{
 		keyType:      keyType,
 		keyAlgorithm: keyAlg,
+		keySource:    keySource,
 		ttl:          ttl,
 		extraInternalData: map[string]interface{}{
 			"static_account":          acct.Name,
@@ -194,9 +372,112 @@ func (b *backend) pathStaticAccountAccessToken(ctx context.Context, req *logical
 	if acct == nil {
 		return logical.ErrorResponse("static account %q does not exists", acctName), nil
 	}
-	if acct.SecretType != SecretTypeAccessToken {
+	if !isAccessTokenSecretType(acct.SecretType) {
 		return logical.ErrorResponse("static account %q cannot generate access tokens (has secret type %s)", acctName, acct.SecretType), nil
 	}
 
-	return b.secretAccessTokenResponse(ctx, req.Storage, acct.TokenGen)
+	cfg, err := getConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	params, err := parseAccessTokenParams(d, acct.tokenScopes(), acct.AccessBoundary, b.maxTokenTTL(cfg))
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	if acct.SecretType == SecretTypeImpersonatedAccessToken {
+		return b.secretImpersonatedAccessTokenResponse(ctx, req.Storage, &acct.ServiceAccountId, acct.TokenImpersonator, params)
+	}
+	return b.secretAccessTokenResponse(ctx, req.Storage, &acct.ServiceAccountId, acct.TokenGen, params)
 }
+
+func (b *backend) pathStaticAccountIdToken(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	acctName := d.Get("name").(string)
+
+	acct, err := b.getStaticAccount(acctName, ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if acct == nil {
+		return logical.ErrorResponse("static account %q does not exists", acctName), nil
+	}
+
+	params, err := parseIdTokenParams(d, acct.AllowedAudiences)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	switch acct.SecretType {
+	case SecretTypeAccessToken:
+		return b.secretIdTokenResponse(ctx, acct.TokenGen, params)
+	case SecretTypeImpersonatedAccessToken:
+		return b.secretImpersonatedIdTokenResponse(ctx, req.Storage, acct.EmailOrId, nil, params)
+	default:
+		return logical.ErrorResponse("static account %q cannot generate ID tokens (has secret type %s)", acctName, acct.SecretType), nil
+	}
+}
+
+func (b *backend) pathStaticAccountSecretHmacKey(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	acctName := d.Get("name").(string)
+	ttl := d.Get("ttl").(int)
+
+	acct, err := b.getStaticAccount(acctName, ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if acct == nil {
+		return logical.ErrorResponse("static account %q does not exists", acctName), nil
+	}
+	if acct.SecretType != SecretTypeHmacKey {
+		return logical.ErrorResponse("static account %q cannot generate HMAC keys (has secret type %s)", acctName, acct.SecretType), nil
+	}
+
+	params := secretHmacKeyParams{
+		ttl: ttl,
+		extraInternalData: map[string]interface{}{
+			"static_account":          acct.Name,
+			"static_account_bindings": acct.bindingHash(),
+		},
+	}
+
+	return b.createHmacKeySecret(ctx, req.Storage, &acct.ServiceAccountId, params)
+}
+
+func (b *backend) pathStaticAccountCredsRead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	acctName := d.Get("name").(string)
+
+	acct, err := b.getStaticAccount(acctName, ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if acct == nil {
+		return logical.ErrorResponse("static account %q does not exists", acctName), nil
+	}
+	if acct.credentialMode() != credentialModeRotating || acct.RotatingKey == nil || acct.RotatingKey.Current == nil {
+		return logical.ErrorResponse("static account %q does not use %q credential mode", acctName, credentialModeRotating), nil
+	}
+
+	current := acct.RotatingKey.Current
+	data := map[string]interface{}{
+		"private_key_data": current.PrivateKeyData,
+		"key_algorithm":    current.KeyAlgorithm,
+		"key_type":         current.KeyType,
+	}
+	for k, v := range rotatingKeyTimes(acct.RotatingKey) {
+		data[k] = v
+	}
+	return &logical.Response{Data: data}, nil
+}
+
+const pathStaticAccountCredsHelpSyn = `Read the current service account key of a static account`
+const pathStaticAccountCredsHelpDesc = `
+This path returns the service account key Vault owns for a static account in
+"rotating" credential mode. Unlike service account keys generated from the key
+path, the key is not leased: Vault rotates it every rotation_period and every
+reader receives the same key until then.
+
+The response includes last_rotated, the time the key was created, and
+ttl_until_rotation, the number of seconds until it is replaced. After a
+rotation, the replaced key remains valid for the static account's
+overlap_period before it is deleted.
+`
@@ -13,11 +13,18 @@ import (
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/iam/v1"
+	"google.golang.org/api/option"
+	"google.golang.org/api/storage/v1"
 )
 
 func TestStaticSecrets_GetAccessToken(t *testing.T) {
 	staticName := "test-static-token"
-	testGetStaticAccessToken(t, staticName)
+	testGetStaticAccessToken(t, staticName, SecretTypeAccessToken)
+}
+
+func TestStaticSecrets_GetImpersonatedAccessToken(t *testing.T) {
+	staticName := "test-static-imptoken"
+	testGetStaticAccessToken(t, staticName, SecretTypeImpersonatedAccessToken)
 }
 
 func TestStaticSecrets_GetKey(t *testing.T) {
@@ -30,9 +37,72 @@ func TestStaticSecrets_GetKeyTTLOverride(t *testing.T) {
 	testGetStaticKey(t, staticName, 1200)
 }
 
-func testGetStaticAccessToken(t *testing.T, staticName string) {
-	secretType := SecretTypeAccessToken
+func TestStaticSecrets_GetHmacKey(t *testing.T) {
+	staticName := "test-static-hmac"
+
+	td := setupTest(t, "60s", "2h")
+	defer cleanupStatic(t, td, staticName, testRoles)
+
+	sa := createStaticAccount(t, td, staticName)
+	defer deleteStaticAccount(t, td, sa)
+
+	projRes := fmt.Sprintf(testProjectResourceTemplate, td.Project)
+
+	expectedBinds := ResourceBindings{projRes: testRoles}
+	bindsRaw, err := util.BindingsHCL(expectedBinds)
+	if err != nil {
+		t.Fatalf("unable to convert resource bindings to HCL string: %v", err)
+	}
+	testStaticCreate(t, td, staticName,
+		map[string]interface{}{
+			"service_account_email": sa.Email,
+			"secret_type":           SecretTypeHmacKey,
+			"bindings":              bindsRaw,
+		})
+
+	// expect error for trying to read key or token
+	testGetKeyFail(t, td, fmt.Sprintf("%s/%s/key", staticAccountPathPrefix, staticName))
+	testGetTokenFail(t, td, fmt.Sprintf("%s/%s/token", staticAccountPathPrefix, staticName))
+
+	resp, err := td.B.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      fmt.Sprintf("%s/%s/hmac-key", staticAccountPathPrefix, staticName),
+		Storage:   td.S,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp == nil || resp.IsError() {
+		t.Fatalf("expected HMAC key response, got %v", resp)
+	}
+	if resp.Data["access_id"] == "" || resp.Data["secret"] == "" {
+		t.Fatalf("expected access_id and secret in response, got %v", resp.Data)
+	}
+
+	storageC, err := storage.NewService(context.Background(), option.WithHTTPClient(td.HttpClient))
+	if err != nil {
+		t.Fatal(err)
+	}
+	accessId := resp.Secret.InternalData["access_id"].(string)
+	if k, err := storageC.Projects.HmacKeys.Get(td.Project, accessId).Do(); err != nil || k.State != hmacKeyStateActive {
+		t.Fatalf("expected active HMAC key %q, got %v (err: %v)", accessId, k, err)
+	}
+
+	testRenewSecretKey(t, td, resp.Secret)
+	testRevokeSecretKey(t, td, resp.Secret)
+
+	// Deleted keys may still be returned for a while with state DELETED.
+	k, err := storageC.Projects.HmacKeys.Get(td.Project, accessId).Do()
+	if !isGoogleAccountNotFoundErr(err) && (err != nil || k.State != "DELETED") {
+		t.Fatalf("expected HMAC key %q to be deleted, got %v (err: %v)", accessId, k, err)
+	}
+
+	// Cleanup
+	testStaticDelete(t, td, staticName)
+	verifyProjectBindingsRemoved(t, td, sa.Email, testRoles)
+}
 
+func testGetStaticAccessToken(t *testing.T, staticName, secretType string) {
 	td := setupTest(t, "0s", "2h")
 	defer cleanupStatic(t, td, staticName, testRoles)
 
@@ -0,0 +1,224 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func pathTidy(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "tidy$",
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixGoogleCloud,
+			OperationVerb:   "tidy",
+		},
+		Fields: map[string]*framework.FieldSchema{
+			"safety_buffer": {
+				Type:        framework.TypeDurationSecond,
+				Description: fmt.Sprintf("Minimum age of a service account or key before it is deleted. Defaults to %s.", defaultTidySafetyBuffer),
+				Default:     int(defaultTidySafetyBuffer / time.Second),
+			},
+			"dry_run": {
+				Type:        framework.TypeBool,
+				Description: "If true, report the orphaned resources in tidy-status without deleting them.",
+			},
+			"tidy_service_accounts": {
+				Type:        framework.TypeBool,
+				Description: "Whether to delete role set service accounts created by this mount that are no longer used by any role set. Defaults to false.",
+				Default:     false,
+			},
+			"tidy_unlabeled_service_accounts": {
+				Type:        framework.TypeBool,
+				Description: "Whether tidy_service_accounts also deletes role set service accounts without a mount ID in their description, such as those created before mount IDs were recorded. These may belong to another mount. Defaults to false.",
+				Default:     false,
+			},
+			"tidy_keys": {
+				Type:        framework.TypeBool,
+				Description: "Whether to delete keys on role set service accounts that are neither the role set's token key nor may belong to a lease.",
+				Default:     true,
+			},
+			"tidy_bindings": {
+				Type:        framework.TypeBool,
+				Description: "Whether to remove bindings of deleted role set service accounts from bound resources.",
+				Default:     true,
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathTidyWrite,
+				Summary:  "Start a tidy operation to delete orphaned service accounts, keys and bindings.",
+				Responses: map[int][]framework.Response{
+					http.StatusAccepted: {{
+						Description: "Accepted",
+					}},
+				},
+				ForwardPerformanceStandby:   true,
+				ForwardPerformanceSecondary: true,
+			},
+		},
+		HelpSynopsis:    pathTidyHelpSyn,
+		HelpDescription: pathTidyHelpDesc,
+	}
+}
+
+func pathTidyStatus(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "tidy-status$",
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixGoogleCloud,
+			OperationVerb:   "tidy",
+			OperationSuffix: "status",
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathTidyStatusRead,
+				Summary:  "Return the status of the last tidy operation.",
+				Responses: map[int][]framework.Response{
+					200: {{
+						Description: "OK",
+						Fields:      responseFieldsTidyStatus(),
+					}},
+				},
+			},
+		},
+		HelpSynopsis:    pathTidyStatusHelpSyn,
+		HelpDescription: pathTidyHelpDesc,
+	}
+}
+
+func responseFieldsTidyStatus() map[string]*framework.FieldSchema {
+	return map[string]*framework.FieldSchema{
+		"state": {
+			Type:        framework.TypeString,
+			Description: "One of Inactive, Running, Finished or Error.",
+		},
+		"error": {
+			Type:        framework.TypeString,
+			Description: "Error that stopped the tidy operation, if any.",
+		},
+		"dry_run": {
+			Type:        framework.TypeBool,
+			Description: "Whether the tidy operation was a dry run.",
+		},
+		"safety_buffer": {
+			Type:        framework.TypeInt,
+			Description: "Safety buffer of the tidy operation, in seconds.",
+		},
+		"tidy_service_accounts": {
+			Type:        framework.TypeBool,
+			Description: "Whether orphaned service accounts were tidied.",
+		},
+		"tidy_unlabeled_service_accounts": {
+			Type:        framework.TypeBool,
+			Description: "Whether orphaned service accounts without a mount ID were tidied.",
+		},
+		"tidy_keys": {
+			Type:        framework.TypeBool,
+			Description: "Whether orphaned keys were tidied.",
+		},
+		"tidy_bindings": {
+			Type:        framework.TypeBool,
+			Description: "Whether stale bindings were tidied.",
+		},
+		"time_started": {
+			Type:        framework.TypeString,
+			Description: "Time the tidy operation started.",
+		},
+		"time_finished": {
+			Type:        framework.TypeString,
+			Description: "Time the tidy operation finished.",
+		},
+		"orphaned_service_accounts": {
+			Type:        framework.TypeStringSlice,
+			Description: "Emails of the service accounts deleted, or that would be deleted in a dry run.",
+		},
+		"orphaned_keys": {
+			Type:        framework.TypeStringSlice,
+			Description: "Names of the keys deleted, or that would be deleted in a dry run.",
+		},
+		"stale_bindings": {
+			Type:        framework.TypeSlice,
+			Description: "Members removed from roles, or that would be removed in a dry run.",
+		},
+		"errors": {
+			Type:        framework.TypeStringSlice,
+			Description: "Errors for resources that could not be checked or deleted.",
+		},
+	}
+}
+
+func (b *backend) pathTidyWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	params := tidyParams{
+		SafetyBuffer:                 time.Duration(d.Get("safety_buffer").(int)) * time.Second,
+		DryRun:                       d.Get("dry_run").(bool),
+		TidyServiceAccounts:          d.Get("tidy_service_accounts").(bool),
+		TidyUnlabeledServiceAccounts: d.Get("tidy_unlabeled_service_accounts").(bool),
+		TidyKeys:                     d.Get("tidy_keys").(bool),
+		TidyBindings:                 d.Get("tidy_bindings").(bool),
+	}
+	if params.SafetyBuffer < 0 {
+		return logical.ErrorResponse("safety_buffer must not be negative"), nil
+	}
+	if params.TidyUnlabeledServiceAccounts && !params.TidyServiceAccounts {
+		return logical.ErrorResponse("tidy_unlabeled_service_accounts requires tidy_service_accounts"), nil
+	}
+	if !params.TidyServiceAccounts && !params.TidyKeys && !params.TidyBindings {
+		return logical.ErrorResponse("at least one of tidy_service_accounts, tidy_keys or tidy_bindings must be set"), nil
+	}
+
+	if err := b.startTidy(req, params); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	resp := &logical.Response{}
+	resp.AddWarning("Tidy operation successfully started. Check tidy-status for its progress and results.")
+	return logical.RespondWithStatusCode(resp, req, http.StatusAccepted)
+}
+
+func (b *backend) pathTidyStatusRead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	b.tidyStatusLock.RLock()
+	defer b.tidyStatusLock.RUnlock()
+
+	return &logical.Response{
+		Data: b.tidyStatus.asOutput(),
+	}, nil
+}
+
+const pathTidyHelpSyn = `Delete orphaned service accounts, keys and bindings left behind by role sets.`
+const pathTidyStatusHelpSyn = `Return the status of the last tidy operation.`
+const pathTidyHelpDesc = `
+Failed rotations and older versions of this plugin can leave behind role set
+service accounts, keys and IAM bindings. A tidy operation runs in the
+background and deletes:
+
+* If tidy_service_accounts is set, service accounts in the projects of
+  existing role sets that this mount created but no role set uses. Accounts
+  must follow the role set naming convention ("vault<roleset>-<timestamp>"
+  with a display name starting with "Service account for Vault secrets backend
+  role set") and have the ID of this mount in their description. Accounts
+  created by other mounts are never deleted. Accounts created before mount
+  IDs were recorded are only deleted if tidy_unlabeled_service_accounts is
+  also set, and only if no role set of this mount uses them. Only set it if
+  no other mount creates role sets in the same projects.
+* User-managed keys on role set service accounts that are not the role set's
+  access token key. Keys of service_account_key role sets are only deleted
+  once they are older than the mount's max lease TTL plus the safety buffer,
+  as they may belong to a lease.
+* Bindings of deleted role set service accounts on the resources that role
+  sets and static accounts are bound to.
+
+Service accounts and keys younger than safety_buffer are never deleted. Keys
+of static accounts are never deleted, as Vault does not own those accounts.
+Use dry_run to list what would be deleted in tidy-status first.
+
+Tidy can also run periodically by setting tidy_interval in the config.
+Periodic tidy does not delete service accounts.
+`
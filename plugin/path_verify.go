@@ -0,0 +1,258 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func verifyFields() map[string]*framework.FieldSchema {
+	return map[string]*framework.FieldSchema{
+		"repair": {
+			Type:        framework.TypeBool,
+			Description: "If true, re-apply missing IAM bindings. Only allowed on update requests.",
+		},
+	}
+}
+
+func responseFieldsVerify() map[string]*framework.FieldSchema {
+	return map[string]*framework.FieldSchema{
+		"type": {
+			Type:        framework.TypeString,
+			Description: "Type of the verified parent, roleset or static_account.",
+		},
+		"name": {
+			Type:        framework.TypeString,
+			Description: "Name of the verified parent.",
+		},
+		"service_account_email": {
+			Type:        framework.TypeString,
+			Description: "Email of the service account.",
+		},
+		"drift": {
+			Type:        framework.TypeBool,
+			Description: "Whether any drift was found.",
+		},
+		"service_account_missing": {
+			Type:        framework.TypeBool,
+			Description: "Whether the service account no longer exists.",
+		},
+		"key_missing": {
+			Type:        framework.TypeBool,
+			Description: "Whether the service account key used to generate access tokens no longer exists.",
+		},
+		"missing_bindings": {
+			Type:        framework.TypeSlice,
+			Description: "Managed bindings that are not set on their resource.",
+		},
+		"unexpected_bindings": {
+			Type:        framework.TypeSlice,
+			Description: "Roles the service account has on bound resources that are not managed by Vault.",
+		},
+		"repaired_bindings": {
+			Type:        framework.TypeSlice,
+			Description: "Missing bindings that were re-applied.",
+		},
+		"errors": {
+			Type:        framework.TypeSlice,
+			Description: "Errors that prevented a complete check.",
+		},
+		"pool_accounts": {
+			Type:        framework.TypeSlice,
+			Description: "Reports of the role set's pool accounts, with the same fields.",
+		},
+	}
+}
+
+func verifyOperations(callback framework.OperationFunc, summary string, fields map[string]*framework.FieldSchema) map[logical.Operation]framework.OperationHandler {
+	return map[logical.Operation]framework.OperationHandler{
+		logical.ReadOperation: &framework.PathOperation{
+			Callback: callback,
+			Summary:  summary,
+			Responses: map[int][]framework.Response{
+				200: {{
+					Description: "OK",
+					Fields:      fields,
+				}},
+			},
+		},
+		logical.UpdateOperation: &framework.PathOperation{
+			Callback: callback,
+			Summary:  summary + " Missing bindings are re-applied if repair is set.",
+			Responses: map[int][]framework.Response{
+				200: {{
+					Description: "OK",
+					Fields:      fields,
+				}},
+			},
+			ForwardPerformanceStandby:   true,
+			ForwardPerformanceSecondary: true,
+		},
+	}
+}
+
+func pathRoleSetVerify(b *backend) *framework.Path {
+	fields := verifyFields()
+	fields["name"] = &framework.FieldSchema{
+		Type:        framework.TypeString,
+		Description: "Name of the role set.",
+	}
+	return &framework.Path{
+		Pattern: fmt.Sprintf("roleset/%s/verify", framework.GenericNameRegex("name")),
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixGoogleCloud,
+			OperationVerb:   "verify",
+			OperationSuffix: "roleset",
+		},
+		Fields:          fields,
+		Operations:      verifyOperations(b.pathRoleSetVerify, "Check a roleset's service account, key and IAM bindings for drift.", responseFieldsVerify()),
+		HelpSynopsis:    pathVerifyHelpSyn,
+		HelpDescription: pathVerifyHelpDesc,
+	}
+}
+
+func pathStaticAccountVerify(b *backend) *framework.Path {
+	fields := verifyFields()
+	fields["name"] = &framework.FieldSchema{
+		Type:        framework.TypeString,
+		Description: "Name of the static account.",
+	}
+	return &framework.Path{
+		Pattern: fmt.Sprintf("%s/%s/verify", staticAccountPathPrefix, framework.GenericNameRegex("name")),
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixGoogleCloud,
+			OperationVerb:   "verify",
+			OperationSuffix: "static-account",
+		},
+		Fields:          fields,
+		Operations:      verifyOperations(b.pathStaticAccountVerify, "Check a static account's service account, key and IAM bindings for drift.", responseFieldsVerify()),
+		HelpSynopsis:    pathVerifyHelpSyn,
+		HelpDescription: pathVerifyHelpDesc,
+	}
+}
+
+func pathVerify(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "verify",
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixGoogleCloud,
+			OperationVerb:   "verify",
+			OperationSuffix: "all",
+		},
+		Fields: verifyFields(),
+		Operations: verifyOperations(b.pathVerifyAll, "Check all rolesets and static accounts for drift.", map[string]*framework.FieldSchema{
+			"drift_found": {
+				Type:        framework.TypeBool,
+				Description: "Whether drift was found for any roleset or static account.",
+			},
+			"reports": {
+				Type:        framework.TypeSlice,
+				Description: "Drift report of each roleset and static account.",
+			},
+		}),
+		HelpSynopsis:    pathVerifyAllHelpSyn,
+		HelpDescription: pathVerifyHelpDesc,
+	}
+}
+
+// verifyRepair returns whether to repair drift, or an error response if repair was requested on a read.
+func verifyRepair(req *logical.Request, d *framework.FieldData) (bool, *logical.Response) {
+	repair := d.Get("repair").(bool)
+	if repair && req.Operation != logical.UpdateOperation {
+		return false, logical.ErrorResponse("repair can only be requested with an update (POST or PUT) request")
+	}
+	return repair, nil
+}
+
+func (b *backend) pathRoleSetVerify(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	repair, errResp := verifyRepair(req, d)
+	if errResp != nil {
+		return errResp, nil
+	}
+	name := d.Get("name").(string)
+
+	b.rolesetLock.Lock()
+	defer b.rolesetLock.Unlock()
+
+	rs, err := getRoleSet(name, ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if rs == nil {
+		return logical.ErrorResponse("role set %q not found", name), nil
+	}
+
+	return &logical.Response{
+		Data: b.verifyRoleSet(ctx, req, rs, repair).asOutput(),
+	}, nil
+}
+
+func (b *backend) pathStaticAccountVerify(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	repair, errResp := verifyRepair(req, d)
+	if errResp != nil {
+		return errResp, nil
+	}
+	name := d.Get("name").(string)
+
+	b.staticAccountLock.Lock()
+	defer b.staticAccountLock.Unlock()
+
+	acct, err := b.getStaticAccount(name, ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if acct == nil {
+		return logical.ErrorResponse("static account %q not found", name), nil
+	}
+
+	return &logical.Response{
+		Data: b.verifyStaticAccount(ctx, req, acct, repair).asOutput(),
+	}, nil
+}
+
+func (b *backend) pathVerifyAll(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	repair, errResp := verifyRepair(req, d)
+	if errResp != nil {
+		return errResp, nil
+	}
+
+	reports, err := b.verifyAll(ctx, req, repair)
+	if err != nil {
+		return nil, err
+	}
+
+	driftFound := false
+	out := make([]map[string]interface{}, 0, len(reports))
+	for _, report := range reports {
+		driftFound = driftFound || report.hasDrift()
+		out = append(out, report.asOutput())
+	}
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"drift_found": driftFound,
+			"reports":     out,
+		},
+	}, nil
+}
+
+const pathVerifyHelpSyn = `Check a roleset or static account for drift from its managed IAM bindings.`
+const pathVerifyAllHelpSyn = `Check all rolesets and static accounts for drift from their managed IAM bindings.`
+const pathVerifyHelpDesc = `
+These paths check that the service account and access token key of a roleset
+or static account still exist, and compare its bindings with the IAM policy of
+each bound resource. Bindings that were removed outside of Vault are reported
+as missing, and roles the service account has on bound resources that Vault
+does not manage are reported as unexpected.
+
+Set repair=true on an update request to re-apply missing bindings. Unexpected
+bindings are never removed. A missing service account or key must be fixed by
+rotating the roleset or key.
+
+Drift can also be checked periodically by setting drift_check_interval in the
+config. Findings are logged and sent as "gcp/bindings-drift" events.
+`
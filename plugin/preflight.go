@@ -0,0 +1,208 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/iamutil"
+	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/util"
+	"github.com/hashicorp/vault/sdk/helper/useragent"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	projectResourceTmpl = "//cloudresourcemanager.googleapis.com/projects/%s"
+
+	permissionCreateServiceAccount    = "iam.serviceAccounts.create"
+	permissionCreateServiceAccountKey = "iam.serviceAccountKeys.create"
+)
+
+// missingPermissionsError lists the permissions the root credential lacks, by resource.
+type missingPermissionsError struct {
+	missing map[string][]string
+}
+
+func (e *missingPermissionsError) Error() string {
+	resources := make([]string, 0, len(e.missing))
+	for r := range e.missing {
+		resources = append(resources, r)
+	}
+	sort.Strings(resources)
+
+	msgs := make([]string, 0, len(resources))
+	for _, r := range resources {
+		msgs = append(msgs, fmt.Sprintf("%s (%s)", r, strings.Join(e.missing[r], ", ")))
+	}
+	return fmt.Sprintf("configured credentials are missing permissions: %s", strings.Join(msgs, "; "))
+}
+
+// permissionCheck lists the permissions the root credential needs before a role set or static
+// account changes anything: those to get and set the IAM policies of policyResources, and any
+// other permissions by resource name.
+type permissionCheck struct {
+	policyResources util.StringSet
+	permissions     map[string]util.StringSet
+}
+
+func newPermissionCheck() *permissionCheck {
+	return &permissionCheck{
+		policyResources: make(util.StringSet),
+		permissions:     make(map[string]util.StringSet),
+	}
+}
+
+func (pc *permissionCheck) add(resourceName string, permissions ...string) {
+	if _, ok := pc.permissions[resourceName]; !ok {
+		pc.permissions[resourceName] = make(util.StringSet)
+	}
+	pc.permissions[resourceName].Update(permissions...)
+}
+
+// addPolicyResources adds the resources whose IAM policies are changed to bind or unbind the
+// given bindings. The permissions to get and set their policies depend on the resource type, so
+// they are added when checked.
+func (pc *permissionCheck) addPolicyResources(bindings ResourceBindings, conditional ConditionalBindings) {
+	for resourceName := range bindings {
+		pc.policyResources.Add(resourceName)
+	}
+	for _, cb := range conditional {
+		for resourceName := range cb.Bindings {
+			pc.policyResources.Add(resourceName)
+		}
+	}
+}
+
+// checkPermissions tests that the root credential has the permissions in the check, including
+// those to get and set the IAM policy of each policy resource, before anything is changed. It
+// returns a warning for each resource whose permissions could not be checked. If any permission
+// is missing, a *missingPermissionsError listing all of them is returned.
+func (b *backend) checkPermissions(ctx context.Context, req *logical.Request, check *permissionCheck) ([]string, error) {
+	names := check.policyResources.ToSlice()
+	for resourceName := range check.permissions {
+		if !check.policyResources.Includes(resourceName) {
+			names = append(names, resourceName)
+		}
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+	sort.Strings(names)
+
+	httpC, err := b.HTTPClient(req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	apiHandle := iamutil.GetApiHandle(httpC, useragent.PluginString(b.pluginEnv, userAgentPluginName))
+
+	resources := make(map[string]iamutil.Resource, len(names))
+	for _, resourceName := range names {
+		resource, err := b.resources.Parse(resourceName)
+		if err != nil {
+			return nil, err
+		}
+		resources[resourceName] = resource
+	}
+
+	concurrency, err := b.iamPolicyUpdateConcurrency(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	var mu sync.Mutex
+	missing := make(map[string][]string)
+	skipped := make(map[string]string)
+	errs := iamutil.ForEachConcurrently(ctx, concurrency, names, func(ctx context.Context, resourceName string) error {
+		resource := resources[resourceName]
+		permissions := check.permissions[resourceName].ToSlice()
+		sort.Strings(permissions)
+		tested := permissions
+		if check.policyResources.Includes(resourceName) {
+			tested = append(iamutil.PolicyPermissions(resource), permissions...)
+			sort.Strings(tested)
+		}
+		if len(tested) == 0 {
+			return nil
+		}
+
+		m, err := iamutil.MissingPermissions(ctx, apiHandle, resource, tested)
+		if isGoogleApiErrorWithCodes(err, 400) && len(permissions) > 0 && len(permissions) < len(tested) {
+			// The IAM policy permission names for some resource types can't be derived from the
+			// API, so only check the permissions that were given by name.
+			b.Logger().Warn("unable to check IAM policy permissions for resource, checking other permissions only", "resource", resourceName, "error", err)
+			mu.Lock()
+			skipped[resourceName] = "the permissions to get and set its IAM policy could not be checked"
+			mu.Unlock()
+			tested = permissions
+			m, err = iamutil.MissingPermissions(ctx, apiHandle, resource, tested)
+		}
+		switch {
+		case errors.Is(err, iamutil.ErrTestPermissionsNotSupported):
+			b.Logger().Debug("skipping permission check for resource", "resource", resourceName)
+			mu.Lock()
+			skipped[resourceName] = "the resource type does not support testing permissions"
+			mu.Unlock()
+			return nil
+		case isGoogleApiErrorWithCodes(err, 400):
+			b.Logger().Warn("unable to check permissions for resource", "resource", resourceName, "error", err)
+			mu.Lock()
+			skipped[resourceName] = "the permissions could not be checked"
+			mu.Unlock()
+			return nil
+		case isGoogleAccountUnauthorizedErr(err):
+			m = tested
+		case err != nil:
+			return err
+		}
+
+		if len(m) > 0 {
+			mu.Lock()
+			missing[resourceName] = m
+			mu.Unlock()
+		}
+		return nil
+	})
+	for _, resourceName := range names {
+		if err, ok := errs[resourceName]; ok {
+			return nil, fmt.Errorf("unable to check permissions for resource %q: %w", resourceName, err)
+		}
+	}
+
+	if len(missing) > 0 {
+		return nil, &missingPermissionsError{missing: missing}
+	}
+	return skippedPermissionsWarnings(skipped), nil
+}
+
+// skippedPermissionsWarnings returns a warning for each resource whose permissions were not
+// fully checked, with the reason, sorted by resource name.
+func skippedPermissionsWarnings(skipped map[string]string) []string {
+	resources := make([]string, 0, len(skipped))
+	for r := range skipped {
+		resources = append(resources, r)
+	}
+	sort.Strings(resources)
+
+	warnings := make([]string, 0, len(resources))
+	for _, r := range resources {
+		warnings = append(warnings, fmt.Sprintf("permissions of the configured credentials on resource %q were not fully checked before changes were made: %s", r, skipped[r]))
+	}
+	return warnings
+}
+
+// permissionCheckErrorResponse returns the error response for a failed permission check, with
+// the missing permissions by resource if that is why it failed.
+func permissionCheckErrorResponse(err error) *logical.Response {
+	resp := logical.ErrorResponse(err.Error())
+	var mpErr *missingPermissionsError
+	if errors.As(err, &mpErr) {
+		resp.Data["missing_permissions"] = mpErr.missing
+	}
+	return resp
+}
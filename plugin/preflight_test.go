@@ -0,0 +1,69 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/util"
+)
+
+func Test_PermissionCheckErrorResponse(t *testing.T) {
+	missing := map[string][]string{
+		"//storage.googleapis.com/b/bucket":                  {"storage.buckets.setIamPolicy"},
+		"//cloudresourcemanager.googleapis.com/projects/foo": {permissionCreateServiceAccount, permissionCreateServiceAccountKey},
+	}
+	err := &missingPermissionsError{missing: missing}
+
+	expectedMsg := "configured credentials are missing permissions: " +
+		"//cloudresourcemanager.googleapis.com/projects/foo (iam.serviceAccounts.create, iam.serviceAccountKeys.create); " +
+		"//storage.googleapis.com/b/bucket (storage.buckets.setIamPolicy)"
+	if err.Error() != expectedMsg {
+		t.Fatalf("expected error %q, got %q", expectedMsg, err.Error())
+	}
+
+	resp := permissionCheckErrorResponse(err)
+	if !resp.IsError() || !reflect.DeepEqual(resp.Data["missing_permissions"], missing) {
+		t.Fatalf("expected error response with missing permissions, got %#v", resp.Data)
+	}
+
+	resp = permissionCheckErrorResponse(errors.New("some error"))
+	if _, ok := resp.Data["missing_permissions"]; ok {
+		t.Fatalf("expected no missing permissions for other errors, got %#v", resp.Data)
+	}
+}
+
+func Test_PermissionCheckAddPolicyResources(t *testing.T) {
+	check := newPermissionCheck()
+	check.addPolicyResources(ResourceBindings{
+		"//storage.googleapis.com/b/bucket": util.ToSet([]string{"roles/storage.objectViewer"}),
+	}, ConditionalBindings{{
+		Bindings: ResourceBindings{
+			"//pubsub.googleapis.com/projects/foo/topics/bar": util.ToSet([]string{"roles/pubsub.viewer"}),
+		},
+	}})
+	check.add(fmt.Sprintf(serviceAccountResourceTmpl, "-", "sa@foo.iam.gserviceaccount.com"), permissionCreateServiceAccountKey)
+
+	if len(check.policyResources) != 2 || len(check.permissions) != 1 {
+		t.Fatalf("unexpected check %#v", check)
+	}
+}
+
+func Test_SkippedPermissionsWarnings(t *testing.T) {
+	if w := skippedPermissionsWarnings(nil); len(w) != 0 {
+		t.Fatalf("expected no warnings, got %v", w)
+	}
+
+	w := skippedPermissionsWarnings(map[string]string{
+		"//storage.googleapis.com/b/bucket":                          "the permissions could not be checked",
+		"//bigquery.googleapis.com/projects/foo/datasets/my_dataset": "the resource type does not support testing permissions",
+	})
+	if len(w) != 2 || !strings.Contains(w[0], "//bigquery.googleapis.com/projects/foo/datasets/my_dataset") || !strings.Contains(w[1], "the permissions could not be checked") {
+		t.Fatalf("expected a warning per resource sorted by name, got %v", w)
+	}
+}
@@ -14,7 +14,9 @@ import (
 	"github.com/hashicorp/errwrap"
 	"github.com/hashicorp/go-gcp-common/gcputil"
 	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/iamutil"
 	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/automatedrotationutil"
 	"github.com/hashicorp/vault/sdk/logical"
 	"google.golang.org/api/iam/v1"
 )
@@ -28,6 +30,14 @@ const (
 	serviceAccountDisplayNameMaxLen  = 100
 	serviceAccountDisplayNameTmpl    = "Service account for Vault secrets backend role set %s"
 
+	// serviceAccountDescriptionTmpl records the mount ID of the backend that created a role set
+	// service account, so tidy only deletes accounts of its own mount.
+	serviceAccountDescriptionTmpl = "Managed by Vault GCP secrets engine mount %s"
+
+	// Role set update modes for bindings changes.
+	updateModeRotateAccount = "rotate_account"
+	updateModeInPlace       = "in_place"
+
 	errDoesNotExist = "does not exist"
 	errCode404      = "Code: 404"
 	errCode500      = "Code: 500"
@@ -40,11 +50,33 @@ type RoleSet struct {
 	Name       string
 	SecretType string
 
-	RawBindings string
-	Bindings    ResourceBindings
+	RawBindings         string
+	Bindings            ResourceBindings
+	ConditionalBindings ConditionalBindings `json:",omitempty"`
+
+	AccountId         *gcputil.ServiceAccountId
+	TokenGen          *TokenGenerator
+	TokenImpersonator *TokenImpersonator
+
+	AllowedAudiences []string
+
+	// AccessBoundary, if set, downscopes access tokens generated under this role set.
+	AccessBoundary *AccessBoundary
 
-	AccountId *gcputil.ServiceAccountId
-	TokenGen  *TokenGenerator
+	// UpdateMode is how bindings changes are applied. Role sets created before this was
+	// added have an empty mode, which rotates the account.
+	UpdateMode string `json:",omitempty"`
+
+	// MaxAccounts and KeysPerAccount shard service_account_key secrets over a pool of up to
+	// MaxAccounts identically bound service accounts. PoolAccounts are the accounts created
+	// on demand in addition to AccountId.
+	MaxAccounts    int                         `json:",omitempty"`
+	KeysPerAccount int                         `json:",omitempty"`
+	PoolAccounts   []*gcputil.ServiceAccountId `json:",omitempty"`
+
+	// Automated rotation of the access_token key.
+	automatedrotationutil.AutomatedRotationParams
+	automatedrotationutil.RotationInfoResponseParams
 }
 
 // boundResources is a helper method to get the bound gcpAccountResources
@@ -53,10 +85,51 @@ func (rs *RoleSet) boundResources() *gcpAccountResources {
 		return nil
 	}
 	return &gcpAccountResources{
-		accountId: *rs.AccountId,
-		bindings:  rs.Bindings,
-		tokenGen:  rs.TokenGen,
+		accountId:           *rs.AccountId,
+		bindings:            rs.Bindings,
+		conditionalBindings: rs.ConditionalBindings,
+		tokenGen:            rs.TokenGen,
+		tokenImpersonator:   rs.TokenImpersonator,
+	}
+}
+
+// poolResources returns the bound gcpAccountResources of each pool account.
+func (rs *RoleSet) poolResources() []*gcpAccountResources {
+	resources := make([]*gcpAccountResources, 0, len(rs.PoolAccounts))
+	for _, id := range rs.PoolAccounts {
+		resources = append(resources, &gcpAccountResources{
+			accountId:           *id,
+			bindings:            rs.Bindings,
+			conditionalBindings: rs.ConditionalBindings,
+		})
 	}
+	return resources
+}
+
+// accounts returns the role set's service account followed by its pool accounts.
+func (rs *RoleSet) accounts() []*gcputil.ServiceAccountId {
+	if rs.AccountId == nil {
+		return nil
+	}
+	return append([]*gcputil.ServiceAccountId{rs.AccountId}, rs.PoolAccounts...)
+}
+
+// usesAccount returns whether the account is the role set's service account or in its pool.
+func (rs *RoleSet) usesAccount(accountId gcputil.ServiceAccountId) bool {
+	for _, id := range rs.accounts() {
+		if id.ResourceName() == accountId.ResourceName() {
+			return true
+		}
+	}
+	return false
+}
+
+// keysPerAccount returns the maximum number of leased keys per pool account.
+func (rs *RoleSet) keysPerAccount() int {
+	if rs.KeysPerAccount <= 0 {
+		return maxUserManagedKeys
+	}
+	return rs.KeysPerAccount
 }
 
 // validate checks whether a RoleSet has been populated properly before saving
@@ -74,7 +147,7 @@ func (rs *RoleSet) validate() error {
 		err = multierror.Append(err, fmt.Errorf("role set should have account associated"))
 	}
 
-	if len(rs.Bindings) == 0 {
+	if len(rs.Bindings) == 0 && len(rs.ConditionalBindings) == 0 {
 		err = multierror.Append(err, fmt.Errorf("role set bindings cannot be empty"))
 	}
 
@@ -82,6 +155,22 @@ func (rs *RoleSet) validate() error {
 		err = multierror.Append(err, fmt.Errorf("role set raw bindings cannot be empty string"))
 	}
 
+	switch rs.UpdateMode {
+	case "", updateModeRotateAccount, updateModeInPlace:
+	default:
+		err = multierror.Append(err, fmt.Errorf("invalid update_mode %q, must be one of %q or %q", rs.UpdateMode, updateModeRotateAccount, updateModeInPlace))
+	}
+
+	if rs.MaxAccounts < 0 {
+		err = multierror.Append(err, fmt.Errorf("max_accounts cannot be negative"))
+	}
+	if rs.MaxAccounts > 1 && rs.SecretType != SecretTypeKey {
+		err = multierror.Append(err, fmt.Errorf("max_accounts is only supported for %q role sets", SecretTypeKey))
+	}
+	if rs.KeysPerAccount < 0 || rs.KeysPerAccount > maxUserManagedKeys {
+		err = multierror.Append(err, fmt.Errorf("keys_per_account must be between 1 and %d", maxUserManagedKeys))
+	}
+
 	switch rs.SecretType {
 	case SecretTypeAccessToken:
 		if rs.TokenGen == nil {
@@ -89,7 +178,13 @@ func (rs *RoleSet) validate() error {
 		} else if len(rs.TokenGen.Scopes) == 0 {
 			err = multierror.Append(err, fmt.Errorf("access token role set should have defined scopes"))
 		}
-	case SecretTypeKey:
+	case SecretTypeImpersonatedAccessToken:
+		if rs.TokenImpersonator == nil {
+			err = multierror.Append(err, fmt.Errorf("impersonated access token role set should have initialized token impersonator"))
+		} else if len(rs.TokenImpersonator.Scopes) == 0 {
+			err = multierror.Append(err, fmt.Errorf("impersonated access token role set should have defined scopes"))
+		}
+	case SecretTypeKey, SecretTypeHmacKey:
 		break
 	default:
 		err = multierror.Append(err, fmt.Errorf("unknown secret type: %s", rs.SecretType))
@@ -111,6 +206,34 @@ func (rs *RoleSet) save(ctx context.Context, s logical.Storage) error {
 	return s.Put(ctx, entry)
 }
 
+// tokenScopes returns the OAuth scopes configured for access tokens generated by this role set.
+func (rs *RoleSet) tokenScopes() []string {
+	switch {
+	case rs.TokenGen != nil:
+		return rs.TokenGen.Scopes
+	case rs.TokenImpersonator != nil:
+		return rs.TokenImpersonator.Scopes
+	default:
+		return nil
+	}
+}
+
+// setTokenScopes updates the OAuth scopes of access tokens generated by this role set, if any.
+func (rs *RoleSet) setTokenScopes(scopes []string) {
+	if rs.TokenGen != nil {
+		rs.TokenGen.Scopes = scopes
+	}
+	if rs.TokenImpersonator != nil {
+		rs.TokenImpersonator.Scopes = scopes
+	}
+}
+
+// updatesInPlace returns whether bindings changes are applied to the existing service account
+// instead of rotating it.
+func (rs *RoleSet) updatesInPlace() bool {
+	return rs.UpdateMode == updateModeInPlace && rs.AccountId != nil
+}
+
 func (rs *RoleSet) bindingHash() string {
 	return getStringHash(rs.RawBindings)
 }
@@ -130,7 +253,7 @@ func (b *backend) getServiceAccount(iamAdmin *iam.Service, accountId *gcputil.Se
 
 // saveRoleSetWithNewAccount rotates the role set service account. This includes creating a new service account with
 // a new name and deleting the old service account, updating keys or bindings as required.
-func (b *backend) saveRoleSetWithNewAccount(ctx context.Context, req *logical.Request, rs *RoleSet, project string, newBinds ResourceBindings, scopes []string) (warnings []string, err error) {
+func (b *backend) saveRoleSetWithNewAccount(ctx context.Context, req *logical.Request, rs *RoleSet, project string, newBinds ResourceBindings, newConditionalBinds ConditionalBindings, scopes []string) (warnings []string, err error) {
 	b.Logger().Debug("updating roleset with new account")
 
 	oldResources := rs.boundResources()
@@ -145,10 +268,20 @@ func (b *backend) saveRoleSetWithNewAccount(ctx context.Context, req *logical.Re
 			Project:   project,
 			EmailOrId: emailForServiceAccountName(project, newSaName),
 		},
-		bindings: newBinds,
+		bindings:            newBinds,
+		conditionalBindings: newConditionalBinds,
 	}
 	if len(scopes) > 0 {
-		newResources.tokenGen = &TokenGenerator{Scopes: scopes}
+		switch rs.SecretType {
+		case SecretTypeAccessToken:
+			newResources.tokenGen = &TokenGenerator{Scopes: scopes}
+		case SecretTypeImpersonatedAccessToken:
+			principal, err := b.rootServiceAccountEmail(ctx, req.Storage)
+			if err != nil {
+				return nil, fmt.Errorf("unable to determine principal to impersonate role set service account: %w", err)
+			}
+			newResources.tokenImpersonator = &TokenImpersonator{Principal: principal, Scopes: scopes}
+		}
 	}
 
 	// Add WALs for both old and new resources.
@@ -161,6 +294,13 @@ func (b *backend) saveRoleSetWithNewAccount(ctx context.Context, req *logical.Re
 		return nil, err
 	}
 
+	// The pool is drained along with the old account, and refilled on demand.
+	oldPoolResources := rs.poolResources()
+	oldPoolWalIds, err := b.addWalsForRoleSetPool(ctx, req, rs.Name, oldPoolResources)
+	if err != nil {
+		return nil, err
+	}
+
 	b.Logger().Debug("adding WALs for new roleset resources")
 	newWalIds, err := b.addWalsForRoleSetResources(ctx, req, rs.Name, newResources)
 	if err != nil {
@@ -168,8 +308,46 @@ func (b *backend) saveRoleSetWithNewAccount(ctx context.Context, req *logical.Re
 	}
 
 	// Created new RoleSet resources
-	// Create new service account
-	sa, err := b.createServiceAccount(ctx, req, newResources.accountId.Project, newSaName, fmt.Sprintf("role set %s", rs.Name))
+	sa, err := b.createRoleSetAccount(ctx, req, rs.Name, newSaName, newResources)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create new token gen if a stubbed tokenGenerator (with scopes) is given.
+	if newResources.tokenGen != nil && len(newResources.tokenGen.Scopes) > 0 {
+		tokenGen, err := b.createNewTokenGen(ctx, req, sa.Name, newResources.tokenGen.Scopes)
+		if err != nil {
+			return nil, err
+		}
+		newResources.tokenGen = tokenGen
+	}
+
+	// Edit roleset with new resources and save to storage.
+	rs.AccountId = &newResources.accountId
+	rs.Bindings = newResources.bindings
+	rs.ConditionalBindings = newResources.conditionalBindings
+	rs.TokenGen = newResources.tokenGen
+	rs.TokenImpersonator = newResources.tokenImpersonator
+	rs.PoolAccounts = nil
+	if err := rs.save(ctx, req.Storage); err != nil {
+		return nil, err
+	}
+
+	// We successfully saved the new roleset with new resources, so try cleaning up WALs
+	// that would rollback the roleset resources (will no-op if still in use by roleset)
+	b.tryDeleteWALs(ctx, req.Storage, newWalIds...)
+
+	warnings = b.tryDeleteRoleSetResources(ctx, req, oldResources, oldWalIds)
+	for i, resources := range oldPoolResources {
+		warnings = append(warnings, b.tryDeleteRoleSetResources(ctx, req, resources, oldPoolWalIds[i])...)
+	}
+	return warnings, nil
+}
+
+// createRoleSetAccount creates a role set service account with the given name and binds it to the
+// resources' bindings, and the token creator binding of its impersonator, if any.
+func (b *backend) createRoleSetAccount(ctx context.Context, req *logical.Request, rsName, saName string, resources *gcpAccountResources) (*iam.ServiceAccount, error) {
+	sa, err := b.createServiceAccount(ctx, req, resources.accountId.Project, saName, fmt.Sprintf("role set %s", rsName))
 	if err != nil {
 		return nil, err
 	}
@@ -204,38 +382,84 @@ func (b *backend) saveRoleSetWithNewAccount(ctx context.Context, req *logical.Re
 		// Create new IAM bindings. This is included in the retry loop because
 		// even if the service account comes back from getServiceAccount(), it
 		// is sometimes not available to the IAM API yet.
-		if err := b.createIamBindings(ctx, req, sa.Email, newResources.bindings); err != nil {
+		if err := b.createAccountIamBindings(ctx, req, sa.Email, resources.bindings, resources.conditionalBindings); err != nil {
 			return nil, false, err
 		}
 
+		if resources.tokenImpersonator != nil {
+			if err := b.createNewTokenImpersonator(ctx, req, resources.accountId, resources.tokenImpersonator); err != nil {
+				return nil, false, err
+			}
+		}
+
 		return gcpAcct, true, err
 	})
 	if err != nil {
 		return nil, fmt.Errorf("error getting service account and creating IAM bindings after creation: %w", err)
 	}
+	return sa, nil
+}
 
-	// Create new token gen if a stubbed tokenGenerator (with scopes) is given.
-	if newResources.tokenGen != nil && len(newResources.tokenGen.Scopes) > 0 {
-		tokenGen, err := b.createNewTokenGen(ctx, req, sa.Name, newResources.tokenGen.Scopes)
-		if err != nil {
-			return nil, err
+// saveRoleSetWithUpdatedBindings applies only the added and removed bindings to the role set's existing
+// service account and saves it to storage. WALs remove added bindings if the role set is not saved,
+// and removed bindings if they cannot be removed after the role set is saved.
+func (b *backend) saveRoleSetWithUpdatedBindings(ctx context.Context, req *logical.Request, rs *RoleSet, newBinds ResourceBindings, newConditionalBinds ConditionalBindings) (warnings []string, err error) {
+	if rs.AccountId == nil {
+		return nil, fmt.Errorf("unable to update roleset bindings in place - account ID was nil")
+	}
+
+	b.Logger().Debug("updating roleset bindings in place")
+
+	changes := bindingGroupChanges(rs.Bindings, rs.ConditionalBindings, newBinds, newConditionalBinds)
+	accounts := rs.accounts()
+
+	var walIds []string
+	for _, accountId := range accounts {
+		for _, change := range changes {
+			for _, bindings := range []ResourceBindings{
+				change.newBindings.sub(change.oldBindings),
+				change.oldBindings.sub(change.newBindings),
+			} {
+				ids, err := b.addWalsForRoleSetBindings(ctx, req, rs.Name, *accountId, change.condition, bindings)
+				walIds = append(walIds, ids...)
+				if err != nil {
+					return nil, err
+				}
+			}
 		}
-		newResources.tokenGen = tokenGen
 	}
 
-	// Edit roleset with new resources and save to storage.
-	rs.AccountId = &newResources.accountId
-	rs.Bindings = newResources.bindings
-	rs.TokenGen = newResources.tokenGen
+	for _, accountId := range accounts {
+		member := fmt.Sprintf(iamutil.ServiceAccountMemberTmpl, accountId.EmailOrId)
+		for _, change := range changes {
+			if err := b.createMemberIamBindings(ctx, req, member, change.condition, change.newBindings.sub(change.oldBindings)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	rs.Bindings = newBinds
+	rs.ConditionalBindings = newConditionalBinds
 	if err := rs.save(ctx, req.Storage); err != nil {
 		return nil, err
 	}
 
-	// We successfully saved the new roleset with new resources, so try cleaning up WALs
-	// that would rollback the roleset resources (will no-op if still in use by roleset)
-	b.tryDeleteWALs(ctx, req.Storage, newWalIds...)
-
-	return b.tryDeleteRoleSetResources(ctx, req, oldResources, oldWalIds), nil
+	// Remove old bindings only after the role set stops using them.
+	for _, accountId := range accounts {
+		b.tokenCache.ExpireAccount(accountId.EmailOrId)
+		member := fmt.Sprintf(iamutil.ServiceAccountMemberTmpl, accountId.EmailOrId)
+		for _, change := range changes {
+			if merr := b.removeMemberBindings(ctx, req, member, change.condition, change.oldBindings.sub(change.newBindings)); merr != nil {
+				for _, err := range merr.Errors {
+					warnings = append(warnings, fmt.Sprintf("unable to delete IAM policy bindings for service account %q (WAL entry to clean-up later has been added): %v", accountId.EmailOrId, err))
+				}
+			}
+		}
+	}
+	if len(warnings) == 0 {
+		b.tryDeleteWALs(ctx, req.Storage, walIds...)
+	}
+	return warnings, nil
 }
 
 // saveRoleSetWithNewTokenKey rotates the role set access_token key and saves it to storage.
@@ -278,6 +502,7 @@ func (b *backend) saveRoleSetWithNewTokenKey(ctx context.Context, req *logical.R
 	if err := rs.save(ctx, req.Storage); err != nil {
 		return "", err
 	}
+	b.tokenCache.ExpireAccount(rs.AccountId.EmailOrId)
 
 	// Try deleting the old key.
 	iamAdmin, err := b.IAMAdminClient(req.Storage)
@@ -312,24 +537,69 @@ func (b *backend) addWalsForRoleSetResources(ctx context.Context, req *logical.R
 	}
 	walIds = append(walIds, walId)
 
-	for resource, roles := range boundResources.bindings {
-		walId, err := framework.PutWAL(ctx, req.Storage, walTypeIamPolicy, &walIamPolicy{
+	bindingWalIds, err := b.addWalsForRoleSetBindings(ctx, req, rolesetName, boundResources.accountId, nil, boundResources.bindings)
+	walIds = append(walIds, bindingWalIds...)
+	if err != nil {
+		return walIds, err
+	}
+	for _, cb := range boundResources.conditionalBindings {
+		bindingWalIds, err := b.addWalsForRoleSetBindings(ctx, req, rolesetName, boundResources.accountId, &cb.Condition, cb.Bindings)
+		walIds = append(walIds, bindingWalIds...)
+		if err != nil {
+			return walIds, err
+		}
+	}
+
+	if boundResources.tokenGen != nil {
+		walId, err := b.addWalRoleSetServiceAccountKey(ctx, req, rolesetName, &boundResources.accountId, boundResources.tokenGen.KeyName)
+		if err != nil {
+			return nil, err
+		}
+		walIds = append(walIds, walId)
+	}
+
+	if boundResources.tokenImpersonator != nil {
+		walId, err := framework.PutWAL(ctx, req.Storage, walTypeTokenCreator, &walTokenCreator{
 			RoleSet:   rolesetName,
 			AccountId: boundResources.accountId,
-			Resource:  resource,
-			Roles:     roles.ToSlice(),
+			Principal: boundResources.tokenImpersonator.Principal,
 		})
 		if err != nil {
-			return walIds, errwrap.Wrapf("unable to create WAL entry to clean up service account bindings: {{err}}", err)
+			return nil, errwrap.Wrapf("unable to create WAL entry to clean up token creator binding: {{err}}", err)
 		}
 		walIds = append(walIds, walId)
 	}
+	return walIds, nil
+}
 
-	if boundResources.tokenGen != nil {
-		walId, err := b.addWalRoleSetServiceAccountKey(ctx, req, rolesetName, &boundResources.accountId, boundResources.tokenGen.KeyName)
+// addWalsForRoleSetPool creates WALs to clean up each of the pool accounts' resources, returning the
+// WAL IDs of each account.
+func (b *backend) addWalsForRoleSetPool(ctx context.Context, req *logical.Request, rolesetName string, poolResources []*gcpAccountResources) ([][]string, error) {
+	walIds := make([][]string, 0, len(poolResources))
+	for _, resources := range poolResources {
+		ids, err := b.addWalsForRoleSetResources(ctx, req, rolesetName, resources)
 		if err != nil {
 			return nil, err
 		}
+		walIds = append(walIds, ids)
+	}
+	return walIds, nil
+}
+
+// addWalsForRoleSetBindings creates WALs to clean up a roleset's bindings under the given condition.
+func (b *backend) addWalsForRoleSetBindings(ctx context.Context, req *logical.Request, rolesetName string, accountId gcputil.ServiceAccountId, condition *iamutil.Condition, bindings ResourceBindings) (walIds []string, err error) {
+	walIds = make([]string, 0, len(bindings))
+	for resource, roles := range bindings {
+		walId, err := framework.PutWAL(ctx, req.Storage, walTypeIamPolicy, &walIamPolicy{
+			RoleSet:   rolesetName,
+			AccountId: accountId,
+			Resource:  resource,
+			Roles:     roles.ToSlice(),
+			Condition: condition,
+		})
+		if err != nil {
+			return walIds, errwrap.Wrapf("unable to create WAL entry to clean up service account bindings: {{err}}", err)
+		}
 		walIds = append(walIds, walId)
 	}
 	return walIds, nil
@@ -343,7 +613,7 @@ func (b *backend) addWalRoleSetServiceAccountKey(ctx context.Context, req *logic
 
 	b.Logger().Debug("add WAL for service account key", "account", accountId.ResourceName(), "keyName", keyName)
 
-	walId, err := framework.PutWAL(ctx, req.Storage, walTypeAccount, &walAccountKey{
+	walId, err := framework.PutWAL(ctx, req.Storage, walTypeAccountKey, &walAccountKey{
 		RoleSet:            roleset,
 		ServiceAccountName: accountId.ResourceName(),
 		KeyName:            keyName,
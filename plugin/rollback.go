@@ -21,11 +21,21 @@ const (
 	walTypeAccountKey    = "account_key"
 	walTypeIamPolicy     = "iam_policy"
 	walTypeIamPolicyDiff = "iam_policy_diff"
+	walTypeTokenCreator  = "token_creator"
+	walTypeHmacKey       = "hmac_key"
+	walTypeApiKey        = "api_key"
+	walTypeJitGrant      = "jit_grant"
 )
 
 func (b *backend) walRollback(ctx context.Context, req *logical.Request, kind string, data interface{}) error {
 	switch kind {
 	case walTypeAccount:
+		// Role set key WALs used to be written with the account type, so roll them back as keys.
+		if m, ok := data.(map[string]interface{}); ok {
+			if _, ok := m["KeyName"]; ok {
+				return b.serviceAccountKeyRollback(ctx, req, data)
+			}
+		}
 		return b.serviceAccountRollback(ctx, req, data)
 	case walTypeAccountKey:
 		return b.serviceAccountKeyRollback(ctx, req, data)
@@ -33,6 +43,14 @@ func (b *backend) walRollback(ctx context.Context, req *logical.Request, kind st
 		return b.serviceAccountPolicyRollback(ctx, req, data)
 	case walTypeIamPolicyDiff:
 		return b.serviceAccountPolicyDiffRollback(ctx, req, data)
+	case walTypeTokenCreator:
+		return b.tokenCreatorRollback(ctx, req, data)
+	case walTypeHmacKey:
+		return b.hmacKeyRollback(ctx, req, data)
+	case walTypeApiKey:
+		return b.apiKeyRollback(ctx, req, data)
+	case walTypeJitGrant:
+		return b.jitGrantRollback(ctx, req, data)
 	default:
 		return fmt.Errorf("unknown type to rollback")
 	}
@@ -55,6 +73,7 @@ type walIamPolicy struct {
 	AccountId gcputil.ServiceAccountId
 	Resource  string
 	Roles     []string
+	Condition *iamutil.Condition
 }
 
 type walIamPolicyStaticAccount struct {
@@ -63,6 +82,31 @@ type walIamPolicyStaticAccount struct {
 	Resource      string
 	RolesAdded    []string
 	RolesRemoved  []string
+	Condition     *iamutil.Condition
+}
+
+type walTokenCreator struct {
+	RoleSet       string
+	StaticAccount string
+	AccountId     gcputil.ServiceAccountId
+	Principal     string
+}
+
+type walHmacKey struct {
+	RoleSet       string
+	StaticAccount string
+	Project       string
+	AccessId      string
+}
+
+type walApiKey struct {
+	ApiKeyRole string
+	KeyName    string
+}
+
+type walJitGrant struct {
+	JitGrantRole string
+	Grant        jitGrant
 }
 
 func (b *backend) serviceAccountRollback(ctx context.Context, req *logical.Request, data interface{}) error {
@@ -81,7 +125,7 @@ func (b *backend) serviceAccountRollback(ctx context.Context, req *logical.Reque
 
 	// If account is still being used, WAL entry was not deleted properly after a successful operation.
 	// Remove WAL entry.
-	if rs != nil && entry.Id.ResourceName() == rs.AccountId.ResourceName() {
+	if rs != nil && rs.usesAccount(entry.Id) {
 		// Still being used - don't delete this service account.
 		return nil
 	}
@@ -105,7 +149,15 @@ func (b *backend) serviceAccountKeyRollback(ctx context.Context, req *logical.Re
 	}
 
 	b.Logger().Debug("checking parent listed in WAL generates access_token secret")
-	var keyInUse string
+	// keysInUse are the keys the parent still uses (empty means no key is in use) and
+	// leasedKeysParent, if set, is the leased key index of keys that must also be kept.
+	keysInUse := make(util.StringSet)
+	var leasedKeysParent string
+
+	// sweepUnusedKeys is whether an empty key name may clean up every key not in use. This is
+	// only safe on service accounts Vault created for a role set; static accounts belong to the
+	// user, so only keys recorded by name are deleted.
+	var sweepUnusedKeys bool
 
 	switch {
 	case entry.RoleSet != "":
@@ -122,7 +174,12 @@ func (b *backend) serviceAccountKeyRollback(ctx context.Context, req *logical.Re
 			}
 
 			if rs.TokenGen != nil {
-				keyInUse = rs.TokenGen.KeyName
+				keysInUse.Add(rs.TokenGen.KeyName)
+			}
+			for _, id := range rs.accounts() {
+				if id.ResourceName() == entry.ServiceAccountName {
+					sweepUnusedKeys = true
+				}
 			}
 		}
 	case entry.StaticAccount != "":
@@ -133,12 +190,15 @@ func (b *backend) serviceAccountKeyRollback(ctx context.Context, req *logical.Re
 
 		// If roleset is not nil, get key in use.
 		if sa != nil {
-			if sa.SecretType != SecretTypeAccessToken {
+			switch {
+			case sa.credentialMode() == credentialModeRotating:
+				keysInUse.Update(sa.rotatingKeyNames()...)
+				leasedKeysParent = leasedKeyIndexParent(staticAccountStoragePrefix, sa.Name)
+			case sa.SecretType != SecretTypeAccessToken:
 				// Remove WAL entry - we don't clean keys if roleset generates key secrets.
 				return nil
-			}
-			if sa.TokenGen != nil {
-				keyInUse = sa.TokenGen.KeyName
+			case sa.TokenGen != nil:
+				keysInUse.Add(sa.TokenGen.KeyName)
 			}
 		}
 	default:
@@ -146,12 +206,17 @@ func (b *backend) serviceAccountKeyRollback(ctx context.Context, req *logical.Re
 		return nil
 	}
 
-	iamC, err := b.IAMAdminClient(req.Storage)
-	if err != nil {
-		return err
+	if entry.KeyName == "" && !sweepUnusedKeys {
+		b.Logger().Warn("removing service account key WAL without a key name for an account not owned by a role set, may need manual cleanup", "account", entry.ServiceAccountName)
+		return nil
 	}
 
 	if entry.KeyName == "" {
+		iamC, err := b.IAMAdminClient(req.Storage)
+		if err != nil {
+			return err
+		}
+
 		// If given an empty key name, this means the WAL entry was created before the key was created.
 		// We list all keys and then delete any not in use by the current roleset.
 		keys, err := iamC.Projects.ServiceAccounts.Keys.List(entry.ServiceAccountName).KeyTypes("USER_MANAGED").Do()
@@ -164,12 +229,12 @@ func (b *backend) serviceAccountKeyRollback(ctx context.Context, req *logical.Re
 		}
 
 		for _, k := range keys.Keys {
-			// Skip deleting keys still in use (empty keyInUse means no key is in use)
-			if k.Name == keyInUse {
+			// Skip deleting keys still in use
+			if keysInUse.Includes(k.Name) {
 				continue
 			}
 
-			_, err = iamC.Projects.ServiceAccounts.Keys.Delete(entry.KeyName).Do()
+			_, err = iamC.Projects.ServiceAccounts.Keys.Delete(k.Name).Do()
 			if err != nil && !isGoogleAccountKeyNotFoundErr(err) {
 				return err
 			}
@@ -177,11 +242,25 @@ func (b *backend) serviceAccountKeyRollback(ctx context.Context, req *logical.Re
 		return nil
 	}
 
-	// If key is still in use, don't delete (empty keyInUse means no key is in use)
-	if entry.KeyName == keyInUse {
+	if leasedKeysParent != "" {
+		leased, err := leasedKeys(ctx, req.Storage, leasedKeysParent)
+		if err != nil {
+			return err
+		}
+		if _, ok := leased[entry.KeyName]; ok {
+			return nil
+		}
+	}
+
+	// If key is still in use, don't delete
+	if keysInUse.Includes(entry.KeyName) {
 		return nil
 	}
 
+	iamC, err := b.IAMAdminClient(req.Storage)
+	if err != nil {
+		return err
+	}
 	_, err = iamC.Projects.ServiceAccounts.Keys.Delete(entry.KeyName).Do()
 	if err != nil && !isGoogleAccountKeyNotFoundErr(err) {
 		return err
@@ -205,8 +284,8 @@ func (b *backend) serviceAccountPolicyRollback(ctx context.Context, req *logical
 	if err != nil {
 		return err
 	}
-	if rs != nil && rs.AccountId != nil && rs.AccountId.ResourceName() == entry.AccountId.ResourceName() {
-		rolesInUse = rs.Bindings[entry.Resource]
+	if rs != nil && rs.usesAccount(entry.AccountId) {
+		rolesInUse = boundRoles(rs.Bindings, rs.ConditionalBindings, entry.Resource, entry.Condition)
 	}
 
 	// Take out any bindings still being used by this role set from roles being removed.
@@ -227,24 +306,17 @@ func (b *backend) serviceAccountPolicyRollback(ctx context.Context, req *logical
 
 	apiHandle := iamutil.GetApiHandle(httpC, useragent.PluginString(b.pluginEnv,
 		userAgentPluginName))
-	p, err := r.GetIamPolicy(ctx, apiHandle)
-	if err != nil {
-		if isGoogleAccountNotFoundErr(err) || isGoogleAccountUnauthorizedErr(err) {
-			return nil
-		}
-		return err
-	}
-
-	changed, newP := p.RemoveBindings(
-		&iamutil.PolicyDelta{
-			Email: entry.AccountId.EmailOrId,
-			Roles: rolesToRemove,
-		})
-	if !changed {
+	_, err = iamutil.UpdatePolicy(ctx, apiHandle, r, func(p *iamutil.Policy) (bool, *iamutil.Policy) {
+		return p.RemoveBindings(
+			&iamutil.PolicyDelta{
+				Email:     entry.AccountId.EmailOrId,
+				Roles:     rolesToRemove,
+				Condition: entry.Condition,
+			})
+	}, b.iamPolicyUpdateOptions(ctx, entry.Resource))
+	if err != nil && (isGoogleAccountNotFoundErr(err) || isGoogleAccountUnauthorizedErr(err)) {
 		return nil
 	}
-
-	_, err = r.SetIamPolicy(ctx, apiHandle, newP)
 	return err
 }
 
@@ -269,7 +341,7 @@ func (b *backend) serviceAccountPolicyDiffRollback(ctx context.Context, req *log
 		return nil
 	}
 	if sa.ResourceName() == entry.AccountId.ResourceName() {
-		rolesInUse = sa.Bindings[entry.Resource]
+		rolesInUse = boundRoles(sa.Bindings, sa.ConditionalBindings, entry.Resource, entry.Condition)
 	}
 
 	// We added roles that are not actually in use
@@ -290,30 +362,123 @@ func (b *backend) serviceAccountPolicyDiffRollback(ctx context.Context, req *log
 
 	apiHandle := iamutil.GetApiHandle(httpC, useragent.PluginString(b.pluginEnv,
 		userAgentPluginName))
-	p, err := r.GetIamPolicy(ctx, apiHandle)
-	if err != nil {
+	_, err = iamutil.UpdatePolicy(ctx, apiHandle, r, func(p *iamutil.Policy) (bool, *iamutil.Policy) {
+		return p.ChangeBindings(
+			// toAdd
+			&iamutil.PolicyDelta{
+				Email:     entry.AccountId.EmailOrId,
+				Roles:     removedRolesToAdd,
+				Condition: entry.Condition,
+			},
+			// toRemove
+			&iamutil.PolicyDelta{
+				Email:     entry.AccountId.EmailOrId,
+				Roles:     addedRolesToRemove,
+				Condition: entry.Condition,
+			})
+	}, b.iamPolicyUpdateOptions(ctx, entry.Resource))
+	return err
+}
+
+func (b *backend) tokenCreatorRollback(ctx context.Context, req *logical.Request, data interface{}) error {
+	var entry walTokenCreator
+	if err := mapstructure.Decode(data, &entry); err != nil {
 		return err
 	}
 
-	changed, newP := p.ChangeBindings(
-		// toAdd
-		&iamutil.PolicyDelta{
-			Email: entry.AccountId.EmailOrId,
-			Roles: removedRolesToAdd,
-		},
-		// toRemove
-		&iamutil.PolicyDelta{
-			Email: entry.AccountId.EmailOrId,
-			Roles: addedRolesToRemove,
-		})
-	if !changed {
+	var inUse *TokenImpersonator
+	var inUseAccount string
+
+	switch {
+	case entry.RoleSet != "":
+		b.rolesetLock.Lock()
+		defer b.rolesetLock.Unlock()
+
+		rs, err := getRoleSet(entry.RoleSet, ctx, req.Storage)
+		if err != nil {
+			return err
+		}
+		if rs != nil && rs.AccountId != nil {
+			inUse = rs.TokenImpersonator
+			inUseAccount = rs.AccountId.ResourceName()
+		}
+	case entry.StaticAccount != "":
+		b.staticAccountLock.Lock()
+		defer b.staticAccountLock.Unlock()
+
+		sa, err := b.getStaticAccount(entry.StaticAccount, ctx, req.Storage)
+		if err != nil {
+			return err
+		}
+		if sa != nil {
+			inUse = sa.TokenImpersonator
+			inUseAccount = sa.ResourceName()
+		}
+	default:
+		b.Logger().Error("removing invalid walTokenCreator with empty RoleSet and empty StaticAccount, may need manual cleanup", "entry", entry)
+		return nil
+	}
+
+	// If the binding is still being used, WAL entry was not deleted properly after a successful operation.
+	if inUse != nil && inUse.Principal == entry.Principal && inUseAccount == entry.AccountId.ResourceName() {
 		return nil
 	}
 
-	_, err = r.SetIamPolicy(ctx, apiHandle, newP)
+	err := b.removeBindings(ctx, req, entry.Principal, tokenCreatorBindings(entry.AccountId))
+	if err != nil && (isGoogleAccountNotFoundErr(err) || isGoogleAccountUnauthorizedErr(err)) {
+		return nil
+	}
 	return err
 }
 
+// hmacKeyRollback deletes an HMAC key that was created but not returned in a lease.
+func (b *backend) hmacKeyRollback(ctx context.Context, req *logical.Request, data interface{}) error {
+	var entry walHmacKey
+	if err := mapstructure.Decode(data, &entry); err != nil {
+		return err
+	}
+
+	storageC, err := b.StorageClient(req.Storage)
+	if err != nil {
+		return err
+	}
+
+	return b.deleteHmacKey(ctx, storageC, entry.Project, entry.AccessId)
+}
+
+// apiKeyRollback deletes an API key that was created but not returned in a lease.
+func (b *backend) apiKeyRollback(ctx context.Context, req *logical.Request, data interface{}) error {
+	var entry walApiKey
+	if err := mapstructure.Decode(data, &entry); err != nil {
+		return err
+	}
+
+	apiKeysC, err := b.ApiKeysClient(req.Storage)
+	if err != nil {
+		return err
+	}
+
+	return b.deleteApiKey(ctx, apiKeysC, entry.KeyName)
+}
+
+// jitGrantRollback removes the bindings of a JIT grant that was not returned in a lease.
+func (b *backend) jitGrantRollback(ctx context.Context, req *logical.Request, data interface{}) error {
+	var entry walJitGrant
+	if err := mapstructure.Decode(data, &entry); err != nil {
+		return err
+	}
+
+	return b.removeMemberBindings(ctx, req, entry.Grant.Member, &entry.Grant.Condition, entry.Grant.resourceBindings()).ErrorOrNil()
+}
+
+// deleteLeasedSecretWAL deletes the WAL of a secret once the secret is owned by its lease and
+// will be cleaned up on revocation. Unlike tryDeleteWALs, errors must be returned: the rollbacks
+// of HMAC keys, API keys and JIT grants can't tell whether the secret is still in use by a
+// lease, so a secret whose WAL is left behind will be deleted and must not be returned.
+func deleteLeasedSecretWAL(ctx context.Context, s logical.Storage, walId string) error {
+	return framework.DeleteWAL(ctx, s, walId)
+}
+
 // This tries to clean up WALs that are no longer needed.
 // We can ignore errors if deletion fails as WAL rollback will no-op if the object is still in use or no longer exists.
 // This simply attempts to reduce the number of GCP calls we will trigger in rollbacks.
@@ -0,0 +1,167 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/go-gcp-common/gcputil"
+	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/util"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/mitchellh/mapstructure"
+)
+
+// Test_ServiceAccountKeyRollback_NoSweep checks that a key WAL without a key name never lists and
+// deletes keys of an account that is not owned by an existing role set. The backend has no
+// credentials, so reaching the IAM API would fail the rollback.
+func Test_ServiceAccountKeyRollback_NoSweep(t *testing.T) {
+	b, reqStorage := getTestBackend(t)
+
+	acct := &StaticAccount{Name: "my-static", SecretType: SecretTypeAccessToken}
+	if err := acct.save(context.Background(), reqStorage); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, entry := range map[string]map[string]interface{}{
+		"static account": {
+			"StaticAccount":      "my-static",
+			"ServiceAccountName": "projects/p/serviceAccounts/customer@p.iam.gserviceaccount.com",
+			"KeyName":            "",
+		},
+		"missing role set": {
+			"RoleSet":            "my-static",
+			"ServiceAccountName": "projects/p/serviceAccounts/customer@p.iam.gserviceaccount.com",
+			"KeyName":            "",
+		},
+	} {
+		req := &logical.Request{Storage: reqStorage}
+		if err := b.serviceAccountKeyRollback(context.Background(), req, entry); err != nil {
+			t.Fatalf("%s: expected WAL to be removed without deleting keys, got %v", name, err)
+		}
+	}
+}
+
+// Test_AddWalStaticAccountServiceAccountKey checks that static account key WALs are rolled back
+// as keys, not as service accounts, which would delete the user's service account.
+func Test_AddWalStaticAccountServiceAccountKey(t *testing.T) {
+	b, reqStorage := getTestBackend(t)
+	req := &logical.Request{Storage: reqStorage}
+
+	accountId := &gcputil.ServiceAccountId{Project: "p", EmailOrId: "customer@p.iam.gserviceaccount.com"}
+	walId, err := b.addWalStaticAccountServiceAccountKey(context.Background(), req, "my-static", accountId, "projects/p/serviceAccounts/customer@p.iam.gserviceaccount.com/keys/k1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wal, err := framework.GetWAL(context.Background(), reqStorage, walId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wal == nil || wal.Kind != walTypeAccountKey {
+		t.Fatalf("expected WAL of kind %q, got %+v", walTypeAccountKey, wal)
+	}
+}
+
+// Test_AddWalRoleSetServiceAccountKey checks that role set key WALs are rolled back as keys, and
+// that a key still used by the role set is kept. The backend has no credentials, so reaching the
+// IAM API would fail the rollback.
+func Test_AddWalRoleSetServiceAccountKey(t *testing.T) {
+	b, reqStorage := getTestBackend(t)
+	ctx := context.Background()
+	req := &logical.Request{Storage: reqStorage}
+
+	accountId := &gcputil.ServiceAccountId{Project: "p", EmailOrId: "vaultmy-roleset-1234@p.iam.gserviceaccount.com"}
+	keyName := accountId.ResourceName() + "/keys/k1"
+	rs := &RoleSet{
+		Name:       "my-roleset",
+		SecretType: SecretTypeAccessToken,
+		AccountId:  accountId,
+		TokenGen:   &TokenGenerator{KeyName: keyName},
+	}
+	if err := rs.save(ctx, reqStorage); err != nil {
+		t.Fatal(err)
+	}
+
+	walId, err := b.addWalRoleSetServiceAccountKey(ctx, req, rs.Name, accountId, keyName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wal, err := framework.GetWAL(ctx, reqStorage, walId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wal == nil || wal.Kind != walTypeAccountKey {
+		t.Fatalf("expected WAL of kind %q, got %+v", walTypeAccountKey, wal)
+	}
+
+	if err := b.walRollback(ctx, req, wal.Kind, wal.Data); err != nil {
+		t.Fatalf("expected key in use to be kept, got %v", err)
+	}
+	// WALs written before the key type was used are also rolled back as keys.
+	if err := b.walRollback(ctx, req, walTypeAccount, wal.Data); err != nil {
+		t.Fatalf("expected legacy key WAL to be rolled back as a key, got %v", err)
+	}
+}
+
+// Test_UpdateBindingsForStaticAccount_Wals checks that the WALs written before changing a static
+// account's bindings record added roles as added and removed roles as removed, so a rollback
+// undoes the change instead of reverting it twice. The added resource cannot be parsed, so the
+// update fails after the WALs are written and before any IAM policy is changed.
+func Test_UpdateBindingsForStaticAccount_Wals(t *testing.T) {
+	b, reqStorage := getTestBackend(t)
+	ctx := context.Background()
+	req := &logical.Request{Storage: reqStorage}
+
+	// Credentials are only parsed, never used to get a token.
+	if err := writeConfig(ctx, reqStorage, config{
+		CredentialsRaw: `{"type": "service_account", "project_id": "p", "private_key_id": "k", "private_key": "unused", "client_email": "vault@p.iam.gserviceaccount.com", "client_id": "1", "token_uri": "https://oauth2.googleapis.com/token"}`,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	acct := &StaticAccount{
+		Name:             "my-static",
+		ServiceAccountId: gcputil.ServiceAccountId{Project: "p", EmailOrId: "customer@p.iam.gserviceaccount.com"},
+	}
+	removedResource := "//cloudresourcemanager.googleapis.com/projects/p"
+	addedResource := "not-a-resource"
+	oldBindings := ResourceBindings{removedResource: util.ToSet([]string{"roles/viewer"})}
+	newBindings := ResourceBindings{addedResource: util.ToSet([]string{"roles/editor"})}
+
+	if _, err := b.updateBindingsForStaticAccount(ctx, req, acct, nil, oldBindings, newBindings); err == nil {
+		t.Fatal("expected update to fail on the unparseable resource")
+	}
+
+	walIds, err := framework.ListWAL(ctx, reqStorage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(walIds) != 2 {
+		t.Fatalf("expected 2 WALs, got %d", len(walIds))
+	}
+	for _, walId := range walIds {
+		wal, err := framework.GetWAL(ctx, reqStorage, walId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var entry walIamPolicyStaticAccount
+		if err := mapstructure.Decode(wal.Data, &entry); err != nil {
+			t.Fatal(err)
+		}
+		switch entry.Resource {
+		case addedResource:
+			if len(entry.RolesAdded) != 1 || entry.RolesAdded[0] != "roles/editor" || len(entry.RolesRemoved) != 0 {
+				t.Fatalf("expected roles/editor to be recorded as added, got %+v", entry)
+			}
+		case removedResource:
+			if len(entry.RolesRemoved) != 1 || entry.RolesRemoved[0] != "roles/viewer" || len(entry.RolesAdded) != 0 {
+				t.Fatalf("expected roles/viewer to be recorded as removed, got %+v", entry)
+			}
+		default:
+			t.Fatalf("unexpected WAL for resource %q", entry.Resource)
+		}
+	}
+}
@@ -6,56 +6,183 @@ package gcpsecrets
 import (
 	"context"
 	"encoding/base64"
+	"fmt"
 	"time"
 
 	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/go-gcp-common/gcputil"
+	"github.com/hashicorp/go-secure-stdlib/strutil"
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/logical"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
+
+	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/tokencache"
 )
 
-func (b *backend) secretAccessTokenResponse(ctx context.Context, s logical.Storage, tokenGen *TokenGenerator) (*logical.Response, error) {
-	if tokenGen == nil || tokenGen.KeyName == "" {
+// maxAccessTokenTTL is the longest lifetime Google issues OAuth2 access tokens for.
+const maxAccessTokenTTL = time.Hour
+
+// accessTokenParams are the optional request parameters used to narrow a generated access token.
+type accessTokenParams struct {
+	scopes   []string
+	ttl      time.Duration
+	boundary *AccessBoundary
+
+	// warnings are returned in the response of the generated token.
+	warnings []string
+}
+
+// parseAccessTokenParams reads the requested scopes, ttl and access boundary for an access token.
+// Requested scopes must be a subset of the role's configured scopes and default to all of them.
+// The requested ttl cannot exceed maxTTL, is capped at maxAccessTokenTTL with a warning, and is
+// zero if not given. A requested access boundary
+// must be within the role's boundary, which is used if none is requested.
+func parseAccessTokenParams(d *framework.FieldData, roleScopes []string, roleBoundary *AccessBoundary, maxTTL time.Duration) (*accessTokenParams, error) {
+	params := &accessTokenParams{
+		scopes:   roleScopes,
+		boundary: roleBoundary,
+	}
+
+	if v, ok := d.GetOk("scopes"); ok {
+		scopes := v.([]string)
+		if len(scopes) == 0 {
+			return nil, fmt.Errorf("cannot provide empty scopes")
+		}
+		for _, scope := range scopes {
+			if !strutil.StrListContains(roleScopes, scope) {
+				return nil, fmt.Errorf("scope %q is not one of the configured token_scopes", scope)
+			}
+		}
+		params.scopes = strutil.RemoveDuplicates(scopes, false)
+	}
+
+	if v, ok := d.GetOk("ttl"); ok {
+		ttl := time.Duration(v.(int)) * time.Second
+		if ttl < 0 {
+			return nil, fmt.Errorf("ttl cannot be negative")
+		}
+		if maxTTL > 0 && ttl > maxTTL {
+			return nil, fmt.Errorf("requested ttl %q exceeds the maximum allowed ttl %q", ttl.String(), maxTTL.String())
+		}
+		if ttl > maxAccessTokenTTL {
+			params.warnings = append(params.warnings, fmt.Sprintf("requested ttl %q exceeds the maximum access token lifetime, using %q",
+				ttl.String(), maxAccessTokenTTL.String()))
+			ttl = maxAccessTokenTTL
+		}
+		params.ttl = ttl
+	}
+
+	if v, ok := d.GetOk("access_boundary"); ok {
+		boundary, err := parseAccessBoundary(v.(string))
+		if err != nil {
+			return nil, err
+		}
+		if boundary != nil {
+			if err := roleBoundary.checkWithin(boundary); err != nil {
+				return nil, err
+			}
+			params.boundary = boundary
+		}
+	}
+
+	return params, nil
+}
+
+// capAccessTokenLifetime returns the default lifetime of a token capped at maxAccessTokenTTL,
+// and a warning if it was capped.
+func capAccessTokenLifetime(lifetime time.Duration) (time.Duration, string) {
+	if lifetime <= maxAccessTokenTTL {
+		return lifetime, ""
+	}
+	return maxAccessTokenTTL, fmt.Sprintf("default ttl %q exceeds the maximum access token lifetime, using %q",
+		lifetime.String(), maxAccessTokenTTL.String())
+}
+
+// maxTokenTTL returns the maximum lifetime of tokens generated under this mount.
+func (b *backend) maxTokenTTL(cfg *config) time.Duration {
+	if cfg != nil && cfg.MaxTTL > 0 {
+		return cfg.MaxTTL
+	}
+	return b.System().MaxLeaseTTL()
+}
+
+func (b *backend) secretAccessTokenResponse(ctx context.Context, s logical.Storage, accountId *gcputil.ServiceAccountId, tokenGen *TokenGenerator, params *accessTokenParams) (*logical.Response, error) {
+	if accountId == nil || tokenGen == nil || tokenGen.KeyName == "" {
 		return logical.ErrorResponse("invalid token generator has no service account key"), nil
 	}
 
-	t, err := retryWithExponentialBackoff(ctx, func() (interface{}, bool, error) {
-		token, err := tokenGen.getAccessToken(ctx)
+	// Tokens exchanged for a service account key always have the default lifetime, so a
+	// requested ttl cannot be applied.
+	warnings := params.warnings
+	if params.ttl > 0 && params.ttl != maxAccessTokenTTL {
+		warnings = append(warnings, fmt.Sprintf("access tokens generated with a service account key are valid for %q, ignoring requested ttl %q; see expires_at_seconds",
+			maxAccessTokenTTL.String(), params.ttl.String()))
+	}
+
+	key := tokencache.Key{
+		Account: accountId.EmailOrId,
+		Scopes:  params.scopes,
+	}
+	token, err := b.cachedToken(ctx, s, key, func(ctx context.Context) (*oauth2.Token, error) {
+		t, err := retryWithExponentialBackoff(ctx, func() (interface{}, bool, error) {
+			token, err := tokenGen.getAccessToken(ctx, params.scopes)
+			if err != nil {
+				return nil, false, err
+			}
+			return token, true, nil
+		})
 		if err != nil {
-			return nil, false, err
+			return nil, err
+		}
+		if t == nil {
+			return nil, fmt.Errorf("got nil token")
 		}
-		return token, true, nil
+		return t.(*oauth2.Token), nil
 	})
 	if err != nil {
 		return logical.ErrorResponse("unable to generate token - make sure your roleset service account and key are still valid: %v", err), nil
 	}
-	if t == nil {
-		return logical.ErrorResponse("unable to generate token - got nil token"), nil
-	}
 
-	token := t.(*oauth2.Token)
+	token, err = downscopeToken(ctx, token, params.boundary)
+	if err != nil {
+		return logical.ErrorResponse("unable to generate token: %v", err), nil
+	}
 
 	return &logical.Response{
-		Data: map[string]interface{}{
-			"token":              token.AccessToken,
-			"token_ttl":          token.Expiry.UTC().Sub(time.Now().UTC()) / (time.Second),
-			"expires_at_seconds": token.Expiry.Unix(),
-		},
+		Data:     accessTokenResponseData(token, params.scopes),
+		Warnings: warnings,
 	}, nil
 }
 
-func (tg *TokenGenerator) getAccessToken(ctx context.Context) (*oauth2.Token, error) {
+// tokenResponseData returns the response data shared by generated access and ID tokens.
+func tokenResponseData(token *oauth2.Token) map[string]interface{} {
+	return map[string]interface{}{
+		"token":              token.AccessToken,
+		"token_ttl":          token.Expiry.UTC().Sub(time.Now().UTC()) / (time.Second),
+		"expires_at_seconds": token.Expiry.Unix(),
+	}
+}
+
+// accessTokenResponseData returns the response data for a generated OAuth2 access token
+// granted the given scopes.
+func accessTokenResponseData(token *oauth2.Token, scopes []string) map[string]interface{} {
+	data := tokenResponseData(token)
+	data["scopes"] = scopes
+	return data
+}
+
+// getAccessToken exchanges the token generator's key for an access token with the given scopes.
+func (tg *TokenGenerator) getAccessToken(ctx context.Context, scopes []string) (*oauth2.Token, error) {
 	jsonBytes, err := base64.StdEncoding.DecodeString(tg.B64KeyJSON)
 	if err != nil {
 		return nil, errwrap.Wrapf("could not b64-decode key data: {{err}}", err)
 	}
 
-	cfg, err := google.JWTConfigFromJSON(jsonBytes, tg.Scopes...)
+	cfg, err := google.JWTConfigFromJSON(jsonBytes, scopes...)
 	if err != nil {
 		return nil, errwrap.Wrapf("could not generate token JWT config: {{err}}", err)
 	}
-
 	tkn, err := cfg.TokenSource(ctx).Token()
 	if err != nil {
 		return nil, errwrap.Wrapf("got error while creating OAuth2 token: {{err}}", err)
@@ -0,0 +1,157 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"golang.org/x/oauth2/google/downscope"
+)
+
+func Test_ParseAccessTokenParams(t *testing.T) {
+	roleScopes := []string{
+		"https://www.googleapis.com/auth/cloud-platform",
+		"https://www.googleapis.com/auth/devstorage.read_only",
+	}
+	schema := map[string]*framework.FieldSchema{
+		"scopes":          {Type: framework.TypeCommaStringSlice},
+		"ttl":             {Type: framework.TypeDurationSecond},
+		"access_boundary": {Type: framework.TypeString},
+	}
+
+	roleBoundary := &AccessBoundary{
+		Rules: []downscope.AccessBoundaryRule{
+			{
+				AvailableResource:    "//storage.googleapis.com/projects/_/buckets/bucket-a",
+				AvailablePermissions: []string{"inRole:roles/storage.objectViewer"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		raw          map[string]interface{}
+		maxTTL       time.Duration
+		roleBoundary *AccessBoundary
+		wantScopes   []string
+		wantTTL      time.Duration
+		wantBoundary *AccessBoundary
+		wantWarnings int
+		wantErr      bool
+	}{
+		{
+			name:       "defaults to role scopes and no ttl",
+			raw:        map[string]interface{}{},
+			maxTTL:     time.Hour,
+			wantScopes: roleScopes,
+		},
+		{
+			name:       "narrowed scopes",
+			raw:        map[string]interface{}{"scopes": "https://www.googleapis.com/auth/devstorage.read_only"},
+			maxTTL:     time.Hour,
+			wantScopes: []string{"https://www.googleapis.com/auth/devstorage.read_only"},
+		},
+		{
+			name:    "scope not configured on role",
+			raw:     map[string]interface{}{"scopes": "https://www.googleapis.com/auth/compute"},
+			maxTTL:  time.Hour,
+			wantErr: true,
+		},
+		{
+			name:       "ttl within max",
+			raw:        map[string]interface{}{"ttl": "30m"},
+			maxTTL:     time.Hour,
+			wantScopes: roleScopes,
+			wantTTL:    30 * time.Minute,
+		},
+		{
+			name:         "ttl capped at access token lifetime",
+			raw:          map[string]interface{}{"ttl": "2h"},
+			maxTTL:       24 * time.Hour,
+			wantScopes:   roleScopes,
+			wantTTL:      maxAccessTokenTTL,
+			wantWarnings: 1,
+		},
+		{
+			name:    "ttl exceeds max",
+			raw:     map[string]interface{}{"ttl": "2h"},
+			maxTTL:  time.Hour,
+			wantErr: true,
+		},
+		{
+			name:         "defaults to role access boundary",
+			raw:          map[string]interface{}{},
+			roleBoundary: roleBoundary,
+			wantScopes:   roleScopes,
+			wantBoundary: roleBoundary,
+		},
+		{
+			name: "narrowed access boundary",
+			raw: map[string]interface{}{
+				"access_boundary": `{"accessBoundary": {"accessBoundaryRules": [{"availableResource": "//storage.googleapis.com/projects/_/buckets/bucket-a", "availablePermissions": ["inRole:roles/storage.objectViewer"], "availabilityCondition": {"expression": "resource.name.startsWith('projects/_/buckets/bucket-a/objects/prefix/')"}}]}}`,
+			},
+			roleBoundary: roleBoundary,
+			wantScopes:   roleScopes,
+			wantBoundary: &AccessBoundary{
+				Rules: []downscope.AccessBoundaryRule{
+					{
+						AvailableResource:    "//storage.googleapis.com/projects/_/buckets/bucket-a",
+						AvailablePermissions: []string{"inRole:roles/storage.objectViewer"},
+						Condition: &downscope.AvailabilityCondition{
+							Expression: "resource.name.startsWith('projects/_/buckets/bucket-a/objects/prefix/')",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "access boundary outside role boundary",
+			raw: map[string]interface{}{
+				"access_boundary": `{"accessBoundary": {"accessBoundaryRules": [{"availableResource": "//storage.googleapis.com/projects/_/buckets/bucket-b", "availablePermissions": ["inRole:roles/storage.objectViewer"]}]}}`,
+			},
+			roleBoundary: roleBoundary,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &framework.FieldData{Raw: tt.raw, Schema: schema}
+			got, err := parseAccessTokenParams(d, roleScopes, tt.roleBoundary, tt.maxTTL)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("parseAccessTokenParams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(got.scopes, tt.wantScopes) {
+				t.Fatalf("expected scopes %v, got %v", tt.wantScopes, got.scopes)
+			}
+			if got.ttl != tt.wantTTL {
+				t.Fatalf("expected ttl %v, got %v", tt.wantTTL, got.ttl)
+			}
+			if !reflect.DeepEqual(got.boundary, tt.wantBoundary) {
+				t.Fatalf("expected access boundary %+v, got %+v", tt.wantBoundary, got.boundary)
+			}
+			if len(got.warnings) != tt.wantWarnings {
+				t.Fatalf("expected %d warnings, got %v", tt.wantWarnings, got.warnings)
+			}
+		})
+	}
+}
+
+func Test_CapAccessTokenLifetime(t *testing.T) {
+	for _, lifetime := range []time.Duration{0, 30 * time.Minute, maxAccessTokenTTL} {
+		if got, w := capAccessTokenLifetime(lifetime); got != lifetime || w != "" {
+			t.Fatalf("expected %v to be kept without warning, got %v and %q", lifetime, got, w)
+		}
+	}
+
+	got, w := capAccessTokenLifetime(4 * time.Hour)
+	if got != maxAccessTokenTTL || w == "" {
+		t.Fatalf("expected lifetime to be capped at %v with a warning, got %v and %q", maxAccessTokenTTL, got, w)
+	}
+}
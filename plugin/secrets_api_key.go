@@ -0,0 +1,217 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"google.golang.org/api/apikeys/v2"
+)
+
+const (
+	// SecretTypeApiKey generates restricted API keys for Google APIs.
+	SecretTypeApiKey = "api_key"
+
+	apiKeyOperationPollInterval = time.Second
+)
+
+func responseFieldsApiKey() map[string]*framework.FieldSchema {
+	return map[string]*framework.FieldSchema{
+		"api_key": {
+			Type:        framework.TypeString,
+			Description: "The API key string.",
+		},
+		"key_name": {
+			Type:        framework.TypeString,
+			Description: "Resource name of the API key.",
+		},
+	}
+}
+
+func secretApiKey(b *backend) *framework.Secret {
+	return &framework.Secret{
+		Type:   SecretTypeApiKey,
+		Fields: responseFieldsApiKey(),
+		Renew:  b.secretApiKeyRenew,
+		Revoke: b.secretApiKeyRevoke,
+	}
+}
+
+func (b *backend) secretApiKeyRenew(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	keyName, err := apiKeyNameFromSecret(req.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	apiKeysC, err := b.ApiKeysClient(req.Storage)
+	if err != nil {
+		return logical.ErrorResponse("could not confirm API key still exists in GCP"), nil
+	}
+
+	k, err := apiKeysC.Projects.Locations.Keys.Get(keyName).Context(ctx).Do()
+	if err != nil || k == nil {
+		return logical.ErrorResponse("could not confirm API key still exists in GCP: %v", err), nil
+	}
+	if k.DeleteTime != "" {
+		return logical.ErrorResponse("API key %q has been deleted", keyName), nil
+	}
+
+	cfg, err := getConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		cfg = &config{}
+	}
+
+	resp := &logical.Response{Secret: req.Secret}
+	resp.Secret.TTL = cfg.TTL
+	resp.Secret.MaxTTL = cfg.MaxTTL
+	return resp, nil
+}
+
+func (b *backend) secretApiKeyRevoke(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	keyName, err := apiKeyNameFromSecret(req.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	apiKeysC, err := b.ApiKeysClient(req.Storage)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	if err := b.deleteApiKey(ctx, apiKeysC, keyName); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	return nil, nil
+}
+
+func apiKeyNameFromSecret(secret *logical.Secret) (string, error) {
+	keyNameRaw, ok := secret.InternalData["key_name"]
+	if !ok {
+		return "", fmt.Errorf("secret is missing key_name internal data")
+	}
+	return keyNameRaw.(string), nil
+}
+
+// deleteApiKey deletes the given API key. Keys that no longer exist are ignored.
+// Deleted keys stop working immediately, although GCP keeps them for 30 days before purging them.
+func (b *backend) deleteApiKey(ctx context.Context, apiKeysC *apikeys.Service, keyName string) error {
+	_, err := apiKeysC.Projects.Locations.Keys.Delete(keyName).Context(ctx).Do()
+	if err != nil && !isGoogleAccountNotFoundErr(err) {
+		return errwrap.Wrapf(fmt.Sprintf("unable to delete API key %q: {{err}}", keyName), err)
+	}
+	return nil
+}
+
+// waitForApiKeyOperation polls the given long-running operation until it is done.
+func (b *backend) waitForApiKeyOperation(ctx context.Context, apiKeysC *apikeys.Service, op *apikeys.Operation) error {
+	if _, hasTimeout := ctx.Deadline(); !hasTimeout {
+		var cancel func()
+		ctx, cancel = context.WithTimeout(ctx, retryTimeout)
+		defer cancel()
+	}
+
+	ticker := time.NewTicker(apiKeyOperationPollInterval)
+	defer ticker.Stop()
+	for !op.Done {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for operation %q: %w", op.Name, ctx.Err())
+		}
+
+		next, err := apiKeysC.Operations.Get(op.Name).Context(ctx).Do()
+		if err != nil {
+			return errwrap.Wrapf(fmt.Sprintf("unable to get operation %q: {{err}}", op.Name), err)
+		}
+		op = next
+	}
+
+	if op.Error != nil {
+		return fmt.Errorf("operation %q failed: %s", op.Name, op.Error.Message)
+	}
+	return nil
+}
+
+func (b *backend) createApiKeySecret(ctx context.Context, s logical.Storage, role *ApiKeyRole, ttl int) (*logical.Response, error) {
+	cfg, err := getConfig(ctx, s)
+	if err != nil {
+		return nil, errwrap.Wrapf("could not read backend config: {{err}}", err)
+	}
+	if cfg == nil {
+		cfg = &config{}
+	}
+
+	apiKeysC, err := b.ApiKeysClient(s)
+	if err != nil {
+		return nil, errwrap.Wrapf("could not create API Keys client: {{err}}", err)
+	}
+
+	keyId, key, err := role.newKey()
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	parent := fmt.Sprintf("projects/%s/locations/global", role.Project)
+	keyName := fmt.Sprintf("%s/keys/%s", parent, keyId)
+
+	// The key name is chosen up front, so add a WAL before creating the key in case we
+	// fail before it is returned in a lease.
+	walId, err := framework.PutWAL(ctx, s, walTypeApiKey, &walApiKey{
+		ApiKeyRole: role.Name,
+		KeyName:    keyName,
+	})
+	if err != nil {
+		return nil, errwrap.Wrapf("unable to create WAL entry to clean up API key: {{err}}", err)
+	}
+
+	op, err := apiKeysC.Projects.Locations.Keys.Create(parent, key).KeyId(keyId).Context(ctx).Do()
+	if err != nil {
+		// Leave the WAL, since the key may have been created even if we got an error.
+		return logical.ErrorResponse("unable to create API key: %v", err), nil
+	}
+	if err := b.waitForApiKeyOperation(ctx, apiKeysC, op); err != nil {
+		return nil, errwrap.Wrapf("unable to create API key: {{err}}", err)
+	}
+
+	keyString, err := apiKeysC.Projects.Locations.Keys.GetKeyString(keyName).Context(ctx).Do()
+	if err != nil {
+		return nil, errwrap.Wrapf("unable to get API key string: {{err}}", err)
+	}
+
+	secretD := map[string]interface{}{
+		"api_key":  keyString.KeyString,
+		"key_name": keyName,
+	}
+	internalD := map[string]interface{}{
+		"api_key_role": role.Name,
+		"key_name":     keyName,
+	}
+
+	resp := b.Secret(SecretTypeApiKey).Response(secretD, internalD)
+	if w := role.unrestrictedWarning(); w != "" {
+		resp.AddWarning(w)
+	}
+	resp.Secret.Renewable = true
+
+	resp.Secret.MaxTTL = cfg.MaxTTL
+	resp.Secret.TTL = cfg.TTL
+
+	// If the request came with a TTL value, overwrite the config default
+	if ttl > 0 {
+		resp.Secret.TTL = time.Duration(ttl) * time.Second
+	}
+
+	// The lease now deletes the API key on revocation.
+	if err := deleteLeasedSecretWAL(ctx, s, walId); err != nil {
+		return nil, errwrap.Wrapf("unable to delete WAL entry for API key, key will be cleaned up later: {{err}}", err)
+	}
+	return resp, nil
+}
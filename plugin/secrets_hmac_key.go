@@ -0,0 +1,238 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/go-gcp-common/gcputil"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"google.golang.org/api/storage/v1"
+)
+
+const (
+	// SecretTypeHmacKey generates Cloud Storage HMAC keys for interoperability with S3-compatible tools.
+	SecretTypeHmacKey = "hmac_key"
+
+	hmacKeyStateActive   = "ACTIVE"
+	hmacKeyStateInactive = "INACTIVE"
+)
+
+type secretHmacKeyParams struct {
+	ttl               int
+	extraInternalData map[string]interface{}
+}
+
+func fieldSchemaHmacKey() map[string]*framework.FieldSchema {
+	return map[string]*framework.FieldSchema{
+		"ttl": {
+			Type:        framework.TypeDurationSecond,
+			Description: "Lifetime of the HMAC key",
+			Query:       true,
+		},
+	}
+}
+
+func responseFieldsHmacKey() map[string]*framework.FieldSchema {
+	return map[string]*framework.FieldSchema{
+		"access_id": {
+			Type:        framework.TypeString,
+			Description: "Access ID of the HMAC key.",
+		},
+		"secret": {
+			Type:        framework.TypeString,
+			Description: "Secret of the HMAC key.",
+		},
+	}
+}
+
+func secretHmacKey(b *backend) *framework.Secret {
+	return &framework.Secret{
+		Type:   SecretTypeHmacKey,
+		Fields: responseFieldsHmacKey(),
+		Renew:  b.secretHmacKeyRenew,
+		Revoke: b.secretHmacKeyRevoke,
+	}
+}
+
+func (b *backend) secretHmacKeyRenew(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	resp, err := b.verifySecretHmacKeyActive(ctx, req)
+	if err != nil || (resp != nil && resp.IsError()) {
+		return resp, err
+	}
+	if resp == nil {
+		resp = &logical.Response{}
+	}
+
+	cfg, err := getConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		cfg = &config{}
+	}
+
+	resp.Secret = req.Secret
+	resp.Secret.TTL = cfg.TTL
+	resp.Secret.MaxTTL = cfg.MaxTTL
+	return resp, nil
+}
+
+func (b *backend) verifySecretHmacKeyActive(ctx context.Context, req *logical.Request) (*logical.Response, error) {
+	project, accessId, err := hmacKeyFromSecret(req.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := b.verifyBindingsNotUpdatedForSecret(ctx, req); err != nil {
+		return logical.ErrorResponse(err.Error()), err
+	}
+
+	storageC, err := b.StorageClient(req.Storage)
+	if err != nil {
+		return logical.ErrorResponse("could not confirm HMAC key still exists in GCP"), nil
+	}
+
+	k, err := storageC.Projects.HmacKeys.Get(project, accessId).Context(ctx).Do()
+	if err != nil || k == nil {
+		return logical.ErrorResponse("could not confirm HMAC key still exists in GCP: %v", err), nil
+	}
+	if k.State != hmacKeyStateActive {
+		return logical.ErrorResponse("HMAC key %q is not active (state %s)", accessId, k.State), nil
+	}
+
+	return nil, nil
+}
+
+func (b *backend) secretHmacKeyRevoke(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	project, accessId, err := hmacKeyFromSecret(req.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	storageC, err := b.StorageClient(req.Storage)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	if err := b.deleteHmacKey(ctx, storageC, project, accessId); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	return nil, nil
+}
+
+func hmacKeyFromSecret(secret *logical.Secret) (project, accessId string, err error) {
+	projectRaw, ok := secret.InternalData["project"]
+	if !ok {
+		return "", "", fmt.Errorf("secret is missing project internal data")
+	}
+	accessIdRaw, ok := secret.InternalData["access_id"]
+	if !ok {
+		return "", "", fmt.Errorf("secret is missing access_id internal data")
+	}
+	return projectRaw.(string), accessIdRaw.(string), nil
+}
+
+// deleteHmacKey deactivates and deletes the given HMAC key, since only inactive keys can be deleted.
+// Keys that no longer exist are ignored.
+func (b *backend) deleteHmacKey(ctx context.Context, storageC *storage.Service, project, accessId string) error {
+	_, err := storageC.Projects.HmacKeys.Update(project, accessId, &storage.HmacKeyMetadata{
+		State: hmacKeyStateInactive,
+	}).Context(ctx).Do()
+	if err != nil {
+		if isGoogleAccountNotFoundErr(err) {
+			return nil
+		}
+		return errwrap.Wrapf(fmt.Sprintf("unable to deactivate HMAC key %q: {{err}}", accessId), err)
+	}
+
+	err = storageC.Projects.HmacKeys.Delete(project, accessId).Context(ctx).Do()
+	if err != nil && !isGoogleAccountNotFoundErr(err) {
+		return errwrap.Wrapf(fmt.Sprintf("unable to delete HMAC key %q: {{err}}", accessId), err)
+	}
+	return nil
+}
+
+func (b *backend) createHmacKeySecret(ctx context.Context, s logical.Storage, id *gcputil.ServiceAccountId, params secretHmacKeyParams) (*logical.Response, error) {
+	cfg, err := getConfig(ctx, s)
+	if err != nil {
+		return nil, errwrap.Wrapf("could not read backend config: {{err}}", err)
+	}
+	if cfg == nil {
+		cfg = &config{}
+	}
+
+	storageC, err := b.StorageClient(s)
+	if err != nil {
+		return nil, errwrap.Wrapf("could not create Cloud Storage client: {{err}}", err)
+	}
+
+	key, err := storageC.Projects.HmacKeys.Create(id.Project, id.EmailOrId).Context(ctx).Do()
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	// Add a WAL so the key is cleaned up if we fail to return it in a lease.
+	walEntry := &walHmacKey{
+		Project:  key.Metadata.ProjectId,
+		AccessId: key.Metadata.AccessId,
+	}
+	if v, ok := params.extraInternalData["role_set"]; ok {
+		walEntry.RoleSet = v.(string)
+	}
+	if v, ok := params.extraInternalData["static_account"]; ok {
+		walEntry.StaticAccount = v.(string)
+	}
+	walId, err := framework.PutWAL(ctx, s, walTypeHmacKey, walEntry)
+	if err != nil {
+		if delErr := b.deleteHmacKey(ctx, storageC, walEntry.Project, walEntry.AccessId); delErr != nil {
+			b.Logger().Error("unable to delete HMAC key after failing to create WAL", "access_id", walEntry.AccessId, "error", delErr)
+		}
+		return nil, errwrap.Wrapf("unable to create WAL entry to clean up HMAC key: {{err}}", err)
+	}
+
+	secretD := map[string]interface{}{
+		"access_id": key.Metadata.AccessId,
+		"secret":    key.Secret,
+	}
+	internalD := map[string]interface{}{
+		"project":   key.Metadata.ProjectId,
+		"access_id": key.Metadata.AccessId,
+	}
+
+	for k, v := range params.extraInternalData {
+		internalD[k] = v
+	}
+
+	resp := b.Secret(SecretTypeHmacKey).Response(secretD, internalD)
+	resp.Secret.Renewable = true
+
+	resp.Secret.MaxTTL = cfg.MaxTTL
+	resp.Secret.TTL = cfg.TTL
+
+	// If the request came with a TTL value, overwrite the config default
+	if params.ttl > 0 {
+		resp.Secret.TTL = time.Duration(params.ttl) * time.Second
+	}
+
+	// The lease now deactivates and deletes the key on revocation.
+	if err := deleteLeasedSecretWAL(ctx, s, walId); err != nil {
+		return nil, errwrap.Wrapf("unable to delete WAL entry for HMAC key, key will be cleaned up later: {{err}}", err)
+	}
+	return resp, nil
+}
+
+const (
+	pathHmacKeySyn  = `Generate a Cloud Storage HMAC key secret.`
+	pathHmacKeyDesc = `
+This path will generate a new Cloud Storage HMAC key for the service account,
+for use with tools that access Cloud Storage through its S3-compatible XML API.
+
+The key is deactivated and deleted when the lease is revoked.
+`
+)
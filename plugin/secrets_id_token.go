@@ -0,0 +1,167 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/go-secure-stdlib/strutil"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"golang.org/x/oauth2"
+	"google.golang.org/api/idtoken"
+	"google.golang.org/api/impersonate"
+	"google.golang.org/api/option"
+)
+
+func fieldSchemaIdToken() map[string]*framework.FieldSchema {
+	return map[string]*framework.FieldSchema{
+		"audience": {
+			Type:        framework.TypeString,
+			Description: "Required. Audience (aud claim) of the generated ID token.",
+			Query:       true,
+		},
+		"include_email": {
+			Type:        framework.TypeBool,
+			Description: "Include the service account email and email_verified claims in the ID token.",
+			Query:       true,
+		},
+	}
+}
+
+func responseFieldsIdToken() map[string]*framework.FieldSchema {
+	return map[string]*framework.FieldSchema{
+		"token": {
+			Type:        framework.TypeString,
+			Description: "Google-signed OIDC ID token.",
+		},
+		"token_ttl": {
+			Type:        framework.TypeInt,
+			Description: "Remaining lifetime of the token in seconds.",
+		},
+		"expires_at_seconds": {
+			Type:        framework.TypeInt,
+			Description: "Unix timestamp at which the token expires.",
+		},
+	}
+}
+
+// idTokenParams are the request parameters for generating an ID token.
+type idTokenParams struct {
+	audience     string
+	includeEmail bool
+}
+
+// parseIdTokenParams reads the ID token request parameters and checks the
+// audience against the given list of allowed audience patterns. An empty
+// list of allowed audiences allows any audience.
+func parseIdTokenParams(d *framework.FieldData, allowedAudiences []string) (*idTokenParams, error) {
+	audience := d.Get("audience").(string)
+	if audience == "" {
+		return nil, fmt.Errorf("audience is required")
+	}
+	if len(allowedAudiences) > 0 && !strutil.StrListContainsGlob(allowedAudiences, audience) {
+		return nil, fmt.Errorf("audience %q is not allowed", audience)
+	}
+
+	return &idTokenParams{
+		audience:     audience,
+		includeEmail: d.Get("include_email").(bool),
+	}, nil
+}
+
+// secretIdTokenResponse generates an ID token signed with the service account key of the given token generator.
+// Tokens generated with a service account key always include the email claims.
+func (b *backend) secretIdTokenResponse(ctx context.Context, tokenGen *TokenGenerator, params *idTokenParams) (*logical.Response, error) {
+	if tokenGen == nil || tokenGen.KeyName == "" {
+		return logical.ErrorResponse("invalid token generator has no service account key"), nil
+	}
+
+	t, err := retryWithExponentialBackoff(ctx, func() (interface{}, bool, error) {
+		token, err := tokenGen.getIdToken(ctx, params.audience)
+		if err != nil {
+			return nil, false, err
+		}
+		return token, true, nil
+	})
+	if err != nil {
+		return logical.ErrorResponse("unable to generate ID token - make sure your service account and key are still valid: %v", err), nil
+	}
+	if t == nil {
+		return logical.ErrorResponse("unable to generate ID token - got nil token"), nil
+	}
+
+	return &logical.Response{
+		Data: tokenResponseData(t.(*oauth2.Token)),
+	}, nil
+}
+
+// secretImpersonatedIdTokenResponse generates an ID token by impersonating the given service account,
+// optionally through a chain of delegates.
+func (b *backend) secretImpersonatedIdTokenResponse(ctx context.Context, s logical.Storage, email string, delegates []string, params *idTokenParams) (*logical.Response, error) {
+	creds, err := b.credentials(s)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenSource, err := impersonate.IDTokenSource(ctx, impersonate.IDTokenConfig{
+		Audience:        params.audience,
+		TargetPrincipal: email,
+		IncludeEmail:    params.includeEmail,
+		Delegates:       delegates,
+	}, option.WithCredentials(creds))
+	if err != nil {
+		return logical.ErrorResponse("unable to generate ID token source: %v", err), nil
+	}
+
+	t, err := retryWithExponentialBackoff(ctx, func() (interface{}, bool, error) {
+		token, err := tokenSource.Token()
+		if err != nil {
+			return nil, false, err
+		}
+		return token, true, nil
+	})
+	if err != nil {
+		return logical.ErrorResponse("unable to generate ID token - make sure the service account still exists and Vault can impersonate it: %v", err), nil
+	}
+	if t == nil {
+		return logical.ErrorResponse("unable to generate ID token - got nil token"), nil
+	}
+
+	return &logical.Response{
+		Data: tokenResponseData(t.(*oauth2.Token)),
+	}, nil
+}
+
+func (tg *TokenGenerator) getIdToken(ctx context.Context, audience string) (*oauth2.Token, error) {
+	jsonBytes, err := base64.StdEncoding.DecodeString(tg.B64KeyJSON)
+	if err != nil {
+		return nil, errwrap.Wrapf("could not b64-decode key data: {{err}}", err)
+	}
+
+	ts, err := idtoken.NewTokenSource(ctx, audience, idtoken.WithCredentialsJSON(jsonBytes))
+	if err != nil {
+		return nil, errwrap.Wrapf("could not create ID token source: {{err}}", err)
+	}
+
+	tkn, err := ts.Token()
+	if err != nil {
+		return nil, errwrap.Wrapf("got error while creating ID token: {{err}}", err)
+	}
+	return tkn, nil
+}
+
+const (
+	pathIdTokenHelpSyn  = `Generate a Google-signed OIDC ID token.`
+	pathIdTokenHelpDesc = `
+This path will generate a new Google-signed OIDC ID token for the given audience,
+e.g. for calling Cloud Run services, Cloud Functions or IAP-protected applications.
+
+If the role set, static account or impersonated account restricts the allowed
+audiences, the requested audience must match one of the configured patterns.
+`
+)
@@ -0,0 +1,74 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/framework"
+)
+
+func Test_ParseIdTokenParams(t *testing.T) {
+	tests := []struct {
+		name             string
+		raw              map[string]interface{}
+		allowedAudiences []string
+		wantAudience     string
+		wantIncludeEmail bool
+		wantErr          bool
+	}{
+		{
+			name:    "audience is required",
+			raw:     map[string]interface{}{},
+			wantErr: true,
+		},
+		{
+			name:         "any audience allowed without allowed audiences",
+			raw:          map[string]interface{}{"audience": "https://service.example.com"},
+			wantAudience: "https://service.example.com",
+		},
+		{
+			name:             "audience matches allowed glob",
+			raw:              map[string]interface{}{"audience": "https://my-service-abc123.a.run.app"},
+			allowedAudiences: []string{"https://other.example.com", "https://*.a.run.app"},
+			wantAudience:     "https://my-service-abc123.a.run.app",
+		},
+		{
+			name:             "audience not allowed",
+			raw:              map[string]interface{}{"audience": "https://service.example.com"},
+			allowedAudiences: []string{"https://*.a.run.app"},
+			wantErr:          true,
+		},
+		{
+			name:             "include email",
+			raw:              map[string]interface{}{"audience": "https://service.example.com", "include_email": true},
+			wantAudience:     "https://service.example.com",
+			wantIncludeEmail: true,
+		},
+		{
+			name:             "include email as string",
+			raw:              map[string]interface{}{"audience": "https://service.example.com", "include_email": "true"},
+			wantAudience:     "https://service.example.com",
+			wantIncludeEmail: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &framework.FieldData{Raw: tt.raw, Schema: fieldSchemaIdToken()}
+			got, err := parseIdTokenParams(d, tt.allowedAudiences)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("parseIdTokenParams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.audience != tt.wantAudience {
+				t.Fatalf("expected audience %q, got %q", tt.wantAudience, got.audience)
+			}
+			if got.includeEmail != tt.wantIncludeEmail {
+				t.Fatalf("expected include_email %v, got %v", tt.wantIncludeEmail, got.includeEmail)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/go-gcp-common/gcputil"
+	"github.com/hashicorp/vault/sdk/logical"
+	"golang.org/x/oauth2"
+	"google.golang.org/api/impersonate"
+	"google.golang.org/api/option"
+
+	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/tokencache"
+)
+
+const (
+	// SecretTypeImpersonatedAccessToken generates access tokens by impersonating the
+	// service account with the root credentials, so no service account key is created.
+	SecretTypeImpersonatedAccessToken = "impersonated_access_token"
+
+	tokenCreatorRole = "roles/iam.serviceAccountTokenCreator"
+)
+
+// isAccessTokenSecretType returns whether the given secret type generates OAuth2 access tokens.
+func isAccessTokenSecretType(secretType string) bool {
+	return secretType == SecretTypeAccessToken || secretType == SecretTypeImpersonatedAccessToken
+}
+
+// impersonatedTokenSource returns a token source that impersonates the target principal
+// of the given config using the root credentials.
+func (b *backend) impersonatedTokenSource(ctx context.Context, s logical.Storage, cfg impersonate.CredentialsConfig) (oauth2.TokenSource, error) {
+	creds, err := b.credentials(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return impersonate.CredentialsTokenSource(ctx, cfg, option.WithCredentials(creds))
+}
+
+func (b *backend) secretImpersonatedAccessTokenResponse(ctx context.Context, s logical.Storage, accountId *gcputil.ServiceAccountId, ti *TokenImpersonator, params *accessTokenParams) (*logical.Response, error) {
+	if accountId == nil || ti == nil {
+		return logical.ErrorResponse("invalid token impersonator has no service account"), nil
+	}
+
+	cfg, err := getConfig(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		cfg = &config{}
+	}
+
+	lifetime := cfg.TTL
+	if cfg.MaxTTL > 0 && lifetime > cfg.MaxTTL {
+		lifetime = cfg.MaxTTL
+	}
+	if params.ttl > 0 {
+		lifetime = params.ttl
+	} else if capped, w := capAccessTokenLifetime(lifetime); w != "" {
+		lifetime = capped
+		params.warnings = append(params.warnings, w)
+	}
+
+	key := tokencache.Key{
+		Account:  accountId.EmailOrId,
+		Scopes:   params.scopes,
+		Lifetime: lifetime,
+	}
+	token, err := b.cachedToken(ctx, s, key, func(ctx context.Context) (*oauth2.Token, error) {
+		tokenSource, err := b.impersonatedTokenSource(ctx, s, impersonate.CredentialsConfig{
+			TargetPrincipal: accountId.EmailOrId,
+			Scopes:          params.scopes,
+			Lifetime:        lifetime,
+		})
+		if err != nil {
+			return nil, errwrap.Wrapf("unable to generate token source: {{err}}", err)
+		}
+
+		// The token creator binding may take some time to propagate after the
+		// account was created or updated, so retry like we do for new keys.
+		t, err := retryWithExponentialBackoff(ctx, func() (interface{}, bool, error) {
+			token, err := tokenSource.Token()
+			if err != nil {
+				return nil, false, err
+			}
+			return token, true, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if t == nil {
+			return nil, fmt.Errorf("got nil token")
+		}
+		return t.(*oauth2.Token), nil
+	})
+	if err != nil {
+		return logical.ErrorResponse("unable to generate token - make sure the service account still exists and Vault can impersonate it: %v", err), nil
+	}
+
+	token, err = downscopeToken(ctx, token, params.boundary)
+	if err != nil {
+		return logical.ErrorResponse("unable to generate token: %v", err), nil
+	}
+
+	return &logical.Response{
+		Data:     accessTokenResponseData(token, params.scopes),
+		Warnings: params.warnings,
+	}, nil
+}
@@ -0,0 +1,146 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/iamutil"
+	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/util"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/mitchellh/mapstructure"
+)
+
+// SecretTypeJitGrant grants a principal time-bound IAM bindings.
+const SecretTypeJitGrant = "jit_grant"
+
+func responseFieldsJitGrant() map[string]*framework.FieldSchema {
+	return map[string]*framework.FieldSchema{
+		"grant_id": {
+			Type:        framework.TypeString,
+			Description: "ID of the grant, included in the IAM Condition description.",
+		},
+		"principal": {
+			Type:        framework.TypeString,
+			Description: "IAM member the bindings were granted to.",
+		},
+		"bindings": {
+			Type:        framework.TypeMap,
+			Description: "Map of resource to roles granted.",
+		},
+		"expires_at": {
+			Type:        framework.TypeString,
+			Description: "Time at which the IAM Condition on the bindings stops granting access.",
+		},
+	}
+}
+
+func secretJitGrant(b *backend) *framework.Secret {
+	return &framework.Secret{
+		Type:   SecretTypeJitGrant,
+		Fields: responseFieldsJitGrant(),
+		// The expiry is part of the IAM Condition, so grants cannot be renewed.
+		Revoke: b.secretJitGrantRevoke,
+	}
+}
+
+// jitGrant holds what is needed to remove the bindings of a grant.
+type jitGrant struct {
+	Member    string
+	Condition iamutil.Condition
+	Bindings  map[string][]string
+}
+
+func (g *jitGrant) resourceBindings() ResourceBindings {
+	rb := make(ResourceBindings, len(g.Bindings))
+	for resource, roles := range g.Bindings {
+		rb[resource] = util.ToSet(roles)
+	}
+	return rb
+}
+
+func (b *backend) secretJitGrantRevoke(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	var grant jitGrant
+	if err := mapstructure.Decode(req.Secret.InternalData, &grant); err != nil {
+		return nil, errwrap.Wrapf("unable to decode grant from secret internal data: {{err}}", err)
+	}
+	if grant.Member == "" {
+		return nil, fmt.Errorf("secret is missing grant internal data")
+	}
+
+	if merr := b.removeMemberBindings(ctx, req, grant.Member, &grant.Condition, grant.resourceBindings()); merr != nil {
+		return logical.ErrorResponse("unable to remove bindings for JIT grant: %v", merr), nil
+	}
+	return nil, nil
+}
+
+func (b *backend) createJitGrantSecret(ctx context.Context, req *logical.Request, role *JitGrantRole, principal string, ttl time.Duration) (*logical.Response, error) {
+	grantId, err := uuid.GenerateUUID()
+	if err != nil {
+		return nil, err
+	}
+	expiry := time.Now().Add(ttl)
+
+	grant := &jitGrant{
+		Member:    principal,
+		Condition: *role.grantCondition(grantId, expiry),
+		Bindings:  role.Bindings.asOutput(),
+	}
+
+	// Add a WAL so the bindings are removed if we fail to return them in a lease.
+	walId, err := framework.PutWAL(ctx, req.Storage, walTypeJitGrant, &walJitGrant{
+		JitGrantRole: role.Name,
+		Grant:        *grant,
+	})
+	if err != nil {
+		return nil, errwrap.Wrapf("unable to create WAL entry to clean up JIT grant: {{err}}", err)
+	}
+
+	if err := b.createMemberIamBindings(ctx, req, grant.Member, &grant.Condition, role.Bindings); err != nil {
+		if merr := b.removeMemberBindings(ctx, req, grant.Member, &grant.Condition, role.Bindings); merr == nil {
+			b.tryDeleteWALs(ctx, req.Storage, walId)
+		}
+		return logical.ErrorResponse("unable to create JIT grant: %v", err), nil
+	}
+
+	secretD := map[string]interface{}{
+		"grant_id":   grantId,
+		"principal":  principal,
+		"bindings":   grant.Bindings,
+		"expires_at": expiry.UTC().Format(time.RFC3339),
+	}
+	internalD := map[string]interface{}{
+		"jit_grant_role": role.Name,
+		"member":         grant.Member,
+		"condition":      grant.Condition,
+		"bindings":       grant.Bindings,
+	}
+
+	resp := b.Secret(SecretTypeJitGrant).Response(secretD, internalD)
+	resp.Secret.Renewable = false
+	resp.Secret.TTL = ttl
+	resp.Secret.MaxTTL = ttl
+
+	// The lease now removes the bindings on revocation, or their condition expires first.
+	if err := deleteLeasedSecretWAL(ctx, req.Storage, walId); err != nil {
+		return nil, errwrap.Wrapf("unable to delete WAL entry for JIT grant, grant will be removed later: {{err}}", err)
+	}
+	return resp, nil
+}
+
+const (
+	pathJitGrantSecretSyn  = `Grant a principal time-bound access to the role's bindings.`
+	pathJitGrantSecretDesc = `
+This path will bind the given principal to the role's IAM bindings with an IAM
+Condition that expires at the end of the lease, so access ends even if Vault is
+unavailable when the lease expires.
+
+The bindings are removed early when the lease is revoked. Grants cannot be renewed.
+`
+)
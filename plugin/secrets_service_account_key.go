@@ -24,6 +24,7 @@ const (
 type secretKeyParams struct {
 	keyType           string
 	keyAlgorithm      string
+	keySource         string
 	ttl               int
 	extraInternalData map[string]interface{}
 }
@@ -160,6 +161,16 @@ func (b *backend) secretKeyRevoke(ctx context.Context, req *logical.Request, d *
 		return logical.ErrorResponse("unable to delete service account key: %v", err), nil
 	}
 
+	if err := unindexLeasedKey(ctx, req.Storage, req.Secret.InternalData, keyNameRaw.(string)); err != nil {
+		return nil, err
+	}
+
+	if rsName, ok := req.Secret.InternalData["role_set"].(string); ok {
+		if err := b.retireDrainedPoolAccount(ctx, req, rsName, keyNameRaw.(string)); err != nil {
+			b.Logger().Warn("unable to retire drained roleset pool service account", "roleset", rsName, "error", err)
+		}
+	}
+
 	return nil, nil
 }
 
@@ -177,19 +188,46 @@ func (b *backend) createServiceAccountKeySecret(ctx context.Context, s logical.S
 		return nil, errwrap.Wrapf("could not create IAM Admin client: {{err}}", err)
 	}
 
-	key, err := iamC.Projects.ServiceAccounts.Keys.Create(
-		id.ResourceName(), &iam.CreateServiceAccountKeyRequest{
-			KeyAlgorithm:   params.keyAlgorithm,
-			PrivateKeyType: params.keyType,
-		}).Do()
-	if err != nil {
-		return logical.ErrorResponse(err.Error()), nil
-	}
+	var key *iam.ServiceAccountKey
+	var secretD map[string]interface{}
+	switch params.keySource {
+	case "", keySourceGoogle:
+		key, err = iamC.Projects.ServiceAccounts.Keys.Create(
+			id.ResourceName(), &iam.CreateServiceAccountKeyRequest{
+				KeyAlgorithm:   params.keyAlgorithm,
+				PrivateKeyType: params.keyType,
+			}).Do()
+		if err != nil {
+			return keyCreateErrorResponse(ctx, iamC, id, err), nil
+		}
+
+		secretD = map[string]interface{}{
+			"private_key_data": key.PrivateKeyData,
+			"key_algorithm":    key.KeyAlgorithm,
+			"key_type":         key.PrivateKeyType,
+		}
+	case keySourceVault:
+		if params.keyType != privateKeyTypeJson {
+			return logical.ErrorResponse("key_type must be %q when key_source is %q", privateKeyTypeJson, keySourceVault), nil
+		}
+		if params.keyAlgorithm != keyAlgorithmRSA2k {
+			return logical.ErrorResponse("key_algorithm must be %q when key_source is %q", keyAlgorithmRSA2k, keySourceVault), nil
+		}
+
+		// The certificate expires with the lease, so the key can't be used past its max TTL.
+		var privateKeyData string
+		key, privateKeyData, err = b.uploadServiceAccountKey(ctx, iamC, id, b.maxTokenTTL(cfg))
+		if err != nil {
+			return keyCreateErrorResponse(ctx, iamC, id, err), nil
+		}
 
-	secretD := map[string]interface{}{
-		"private_key_data": key.PrivateKeyData,
-		"key_algorithm":    key.KeyAlgorithm,
-		"key_type":         key.PrivateKeyType,
+		secretD = map[string]interface{}{
+			"private_key_data": privateKeyData,
+			"key_algorithm":    params.keyAlgorithm,
+			"key_type":         params.keyType,
+		}
+	default:
+		return logical.ErrorResponse("invalid key_source %q, must be %q or %q", params.keySource, keySourceGoogle, keySourceVault), nil
 	}
 	internalD := map[string]interface{}{
 		"key_name": key.Name,
@@ -199,6 +237,14 @@ func (b *backend) createServiceAccountKeySecret(ctx context.Context, s logical.S
 		internalD[k] = v
 	}
 
+	// Index the key so the key inventory can tell it belongs to a lease.
+	if err := indexLeasedKey(ctx, s, internalD, key.Name); err != nil {
+		if _, delErr := iamC.Projects.ServiceAccounts.Keys.Delete(key.Name).Context(ctx).Do(); delErr != nil {
+			b.Logger().Warn("unable to delete service account key after failing to index it", "key", key.Name, "error", delErr)
+		}
+		return nil, err
+	}
+
 	resp := b.Secret(SecretTypeKey).Response(secretD, internalD)
 	resp.Secret.Renewable = true
 
@@ -210,9 +256,28 @@ func (b *backend) createServiceAccountKeySecret(ctx context.Context, s logical.S
 		resp.Secret.TTL = time.Duration(params.ttl) * time.Second
 	}
 
+	if count, err := countUserManagedKeys(ctx, iamC, id); err == nil {
+		if w := keyLimitWarning(id, count); w != "" {
+			resp.AddWarning(w)
+		}
+	}
+
 	return resp, nil
 }
 
+// keyCreateErrorResponse returns an error response for a failure to create a key, explaining
+// if the service account has reached GCP's limit of user-managed keys.
+func keyCreateErrorResponse(ctx context.Context, iamC *iam.Service, id *gcputil.ServiceAccountId, err error) *logical.Response {
+	return logical.ErrorResponse(keyCreateError(ctx, iamC, id, err).Error())
+}
+
+func keyCreateError(ctx context.Context, iamC *iam.Service, id *gcputil.ServiceAccountId, err error) error {
+	if count, countErr := countUserManagedKeys(ctx, iamC, id); countErr == nil && count >= maxUserManagedKeys {
+		return fmt.Errorf("service account %q has reached the maximum of %d user-managed keys; revoke leases or prune unknown keys from its keys endpoint: %w", id.EmailOrId, maxUserManagedKeys, err)
+	}
+	return err
+}
+
 const (
 	pathServiceAccountKeySyn  = `Generate a service account private key secret.`
 	pathServiceAccountKeyDesc = `
@@ -221,6 +286,11 @@ This path will generate a new service account key for accessing GCP APIs.
 Either specify "roleset/my-roleset" or "static/my-account" to generate a key corresponding
 to a roleset or static account respectively.
 
+By default, Google generates the key pair. If key_source is "vault", Vault generates the
+key pair itself and only uploads a self-signed certificate for the public key, so the
+private key is never sent to or held by Google. The certificate expires at the lease
+max TTL, after which the key can no longer be used.
+
 Please see backend documentation for more information:
 https://www.vaultproject.io/docs/secrets/gcp/index.html
 `
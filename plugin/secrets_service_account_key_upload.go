@@ -0,0 +1,133 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/go-gcp-common/gcputil"
+	"google.golang.org/api/iam/v1"
+)
+
+const (
+	// keySourceGoogle has Google generate the service account key pair.
+	keySourceGoogle = "google"
+	// keySourceVault has Vault generate the key pair and upload only the public certificate,
+	// so the private key is never known to Google.
+	keySourceVault = "vault"
+
+	uploadedKeyBits = 2048
+)
+
+// serviceAccountCredentialsFile is the format of a TYPE_GOOGLE_CREDENTIALS_FILE service account key.
+type serviceAccountCredentialsFile struct {
+	Type                    string `json:"type"`
+	ProjectId               string `json:"project_id"`
+	PrivateKeyId            string `json:"private_key_id"`
+	PrivateKey              string `json:"private_key"`
+	ClientEmail             string `json:"client_email"`
+	ClientId                string `json:"client_id"`
+	AuthUri                 string `json:"auth_uri"`
+	TokenUri                string `json:"token_uri"`
+	AuthProviderX509CertUrl string `json:"auth_provider_x509_cert_url"`
+	ClientX509CertUrl       string `json:"client_x509_cert_url"`
+}
+
+// uploadServiceAccountKey generates an RSA key pair in Vault and uploads a self-signed certificate for
+// its public key to the service account. The certificate expires after validity, after which Google
+// no longer accepts the key. It returns the uploaded key and the base64-encoded credentials file.
+func (b *backend) uploadServiceAccountKey(ctx context.Context, iamC *iam.Service, id *gcputil.ServiceAccountId, validity time.Duration) (*iam.ServiceAccountKey, string, error) {
+	sa, err := b.getServiceAccount(iamC, id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	privateKey, certPEM, err := newSelfSignedServiceAccountCert(sa.Email, validity)
+	if err != nil {
+		return nil, "", err
+	}
+
+	key, err := iamC.Projects.ServiceAccounts.Keys.Upload(
+		id.ResourceName(), &iam.UploadServiceAccountKeyRequest{
+			PublicKeyData: base64.StdEncoding.EncodeToString(certPEM),
+		}).Context(ctx).Do()
+	if err != nil {
+		return nil, "", errwrap.Wrapf("unable to upload service account key: {{err}}", err)
+	}
+
+	credsJSON, err := serviceAccountCredentialsJSON(sa, key.Name, privateKey)
+	if err != nil {
+		return nil, "", err
+	}
+	return key, base64.StdEncoding.EncodeToString(credsJSON), nil
+}
+
+// newSelfSignedServiceAccountCert generates an RSA private key and a self-signed certificate
+// for its public key, valid from now until validity has passed.
+func newSelfSignedServiceAccountCert(email string, validity time.Duration) (*rsa.PrivateKey, []byte, error) {
+	if validity <= 0 {
+		return nil, nil, fmt.Errorf("certificate validity must be positive, got %s", validity)
+	}
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, uploadedKeyBits)
+	if err != nil {
+		return nil, nil, errwrap.Wrapf("unable to generate RSA key: {{err}}", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, errwrap.Wrapf("unable to generate certificate serial number: {{err}}", err)
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: email},
+		NotBefore:             now,
+		NotAfter:              now.Add(validity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		return nil, nil, errwrap.Wrapf("unable to create certificate: {{err}}", err)
+	}
+
+	return privateKey, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), nil
+}
+
+// serviceAccountCredentialsJSON builds a credentials file for the given key, in the same format
+// Google returns for keys of type TYPE_GOOGLE_CREDENTIALS_FILE.
+func serviceAccountCredentialsJSON(sa *iam.ServiceAccount, keyName string, privateKey *rsa.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, errwrap.Wrapf("unable to marshal private key: {{err}}", err)
+	}
+
+	return json.Marshal(&serviceAccountCredentialsFile{
+		Type:                    "service_account",
+		ProjectId:               sa.ProjectId,
+		PrivateKeyId:            keyName[strings.LastIndex(keyName, "/")+1:],
+		PrivateKey:              string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		ClientEmail:             sa.Email,
+		ClientId:                sa.UniqueId,
+		AuthUri:                 "https://accounts.google.com/o/oauth2/auth",
+		TokenUri:                "https://oauth2.googleapis.com/token",
+		AuthProviderX509CertUrl: "https://www.googleapis.com/oauth2/v1/certs",
+		ClientX509CertUrl:       "https://www.googleapis.com/robot/v1/metadata/x509/" + url.PathEscape(sa.Email),
+	})
+}
@@ -0,0 +1,78 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/iam/v1"
+)
+
+func Test_NewSelfSignedServiceAccountCert(t *testing.T) {
+	email := "test@project.iam.gserviceaccount.com"
+
+	if _, _, err := newSelfSignedServiceAccountCert(email, 0); err == nil {
+		t.Fatal("expected error for zero validity")
+	}
+
+	privateKey, certPEM, err := newSelfSignedServiceAccountCert(email, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block, _ := pem.Decode(certPEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		t.Fatalf("expected PEM-encoded certificate, got %q", certPEM)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cert.Subject.CommonName != email {
+		t.Fatalf("expected certificate subject %q, got %q", email, cert.Subject.CommonName)
+	}
+	if validity := cert.NotAfter.Sub(cert.NotBefore); validity != time.Hour {
+		t.Fatalf("expected certificate validity of 1h, got %s", validity)
+	}
+	if !privateKey.PublicKey.Equal(cert.PublicKey) {
+		t.Fatal("certificate public key does not match private key")
+	}
+	if err := cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature); err != nil {
+		t.Fatalf("expected self-signed certificate: %v", err)
+	}
+}
+
+func Test_ServiceAccountCredentialsJSON(t *testing.T) {
+	sa := &iam.ServiceAccount{
+		Email:     "test@project.iam.gserviceaccount.com",
+		ProjectId: "project",
+		UniqueId:  "1234567890",
+	}
+	privateKey, _, err := newSelfSignedServiceAccountCert(sa.Email, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	credsJSON, err := serviceAccountCredentialsJSON(sa, "projects/project/serviceAccounts/test@project.iam.gserviceaccount.com/keys/abc123", privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The credentials file should be usable the same way as one generated by Google.
+	cfg, err := google.JWTConfigFromJSON(credsJSON)
+	if err != nil {
+		t.Fatalf("unable to parse credentials file: %v", err)
+	}
+	if cfg.Email != sa.Email {
+		t.Fatalf("expected email %q, got %q", sa.Email, cfg.Email)
+	}
+	if cfg.PrivateKeyID != "abc123" {
+		t.Fatalf("expected private key ID %q, got %q", "abc123", cfg.PrivateKeyID)
+	}
+}
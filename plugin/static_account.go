@@ -7,12 +7,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
 
 	"github.com/hashicorp/errwrap"
 	"github.com/hashicorp/go-gcp-common/gcputil"
 	"github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/go-secure-stdlib/strutil"
+	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/iamutil"
 	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/automatedrotationutil"
 	"github.com/hashicorp/vault/sdk/logical"
 	"google.golang.org/api/iam/v1"
 )
@@ -35,20 +38,50 @@ func (b *backend) getStaticAccount(name string, ctx context.Context, s logical.S
 }
 
 type StaticAccount struct {
-	Name        string
-	SecretType  string
-	RawBindings string
-	Bindings    ResourceBindings
+	Name                string
+	SecretType          string
+	RawBindings         string
+	Bindings            ResourceBindings
+	ConditionalBindings ConditionalBindings `json:",omitempty"`
 	gcputil.ServiceAccountId
 
-	TokenGen *TokenGenerator
+	TokenGen          *TokenGenerator
+	TokenImpersonator *TokenImpersonator
+
+	AllowedAudiences []string
+
+	// AccessBoundary, if set, downscopes access tokens generated under this account.
+	AccessBoundary *AccessBoundary
+
+	// CredentialMode is how service_account_key secrets are issued, and RotatingKey is the key
+	// Vault owns in rotating mode.
+	CredentialMode string       `json:",omitempty"`
+	RotatingKey    *RotatingKey `json:",omitempty"`
+
+	// Automated rotation of the access_token key.
+	automatedrotationutil.AutomatedRotationParams
+	automatedrotationutil.RotationInfoResponseParams
 }
 
 func (a *StaticAccount) boundResources() *gcpAccountResources {
 	return &gcpAccountResources{
-		accountId: a.ServiceAccountId,
-		bindings:  a.Bindings,
-		tokenGen:  a.TokenGen,
+		accountId:           a.ServiceAccountId,
+		bindings:            a.Bindings,
+		conditionalBindings: a.ConditionalBindings,
+		tokenGen:            a.TokenGen,
+		tokenImpersonator:   a.TokenImpersonator,
+	}
+}
+
+// tokenScopes returns the OAuth scopes configured for access tokens generated by this static account.
+func (a *StaticAccount) tokenScopes() []string {
+	switch {
+	case a.TokenGen != nil:
+		return a.TokenGen.Scopes
+	case a.TokenImpersonator != nil:
+		return a.TokenImpersonator.Scopes
+	default:
+		return nil
 	}
 }
 
@@ -77,11 +110,30 @@ func (a *StaticAccount) validate() error {
 		} else if len(a.TokenGen.Scopes) == 0 {
 			err = multierror.Append(err, fmt.Errorf("access token static account should have defined scopes"))
 		}
-	case SecretTypeKey:
+	case SecretTypeImpersonatedAccessToken:
+		if a.TokenImpersonator == nil {
+			err = multierror.Append(err, fmt.Errorf("impersonated access token static account should have initialized token impersonator"))
+		} else if len(a.TokenImpersonator.Scopes) == 0 {
+			err = multierror.Append(err, fmt.Errorf("impersonated access token static account should have defined scopes"))
+		}
+	case SecretTypeKey, SecretTypeHmacKey:
 		break
 	default:
 		err = multierror.Append(err, fmt.Errorf("unknown secret type: %s", a.SecretType))
 	}
+
+	switch a.credentialMode() {
+	case credentialModeLeased:
+	case credentialModeRotating:
+		if a.SecretType != SecretTypeKey {
+			err = multierror.Append(err, fmt.Errorf("%q credential mode is only supported for %q static accounts", credentialModeRotating, SecretTypeKey))
+		}
+		if a.RotatingKey == nil || a.RotatingKey.Current == nil {
+			err = multierror.Append(err, fmt.Errorf("rotating static account should have a current key"))
+		}
+	default:
+		err = multierror.Append(err, fmt.Errorf("unknown credential mode: %s", a.CredentialMode))
+	}
 	return err.ErrorOrNil()
 }
 
@@ -135,13 +187,24 @@ func (b *backend) createStaticAccount(ctx context.Context, req *logical.Request,
 
 	// Construct gcpAccountResources references. Note bindings/key are yet to be created.
 	newResources := &gcpAccountResources{
-		accountId: acctId,
-		bindings:  input.bindings,
+		accountId:           acctId,
+		bindings:            input.bindings,
+		conditionalBindings: input.conditionalBindings,
 	}
-	if input.secretType == SecretTypeAccessToken {
+	switch input.secretType {
+	case SecretTypeAccessToken:
 		newResources.tokenGen = &TokenGenerator{
 			Scopes: input.scopes,
 		}
+	case SecretTypeImpersonatedAccessToken:
+		principal, err := b.rootServiceAccountEmail(ctx, req.Storage)
+		if err != nil {
+			return fmt.Errorf("unable to determine principal to impersonate static account service account: %w", err)
+		}
+		newResources.tokenImpersonator = &TokenImpersonator{
+			Principal: principal,
+			Scopes:    input.scopes,
+		}
 	}
 
 	// add WALs for static account resources
@@ -153,9 +216,14 @@ func (b *backend) createStaticAccount(ctx context.Context, req *logical.Request,
 
 	// Create new IAM bindings.
 	_, err = retryWithExponentialBackoff(ctx, func() (interface{}, bool, error) {
-		if err := b.createIamBindings(ctx, req, gcpAcct.Email, newResources.bindings); err != nil {
+		if err := b.createAccountIamBindings(ctx, req, gcpAcct.Email, newResources.bindings, newResources.conditionalBindings); err != nil {
 			return nil, false, err
 		}
+		if newResources.tokenImpersonator != nil {
+			if err := b.createNewTokenImpersonator(ctx, req, acctId, newResources.tokenImpersonator); err != nil {
+				return nil, false, err
+			}
+		}
 		return nil, true, nil
 	})
 	if err != nil {
@@ -173,12 +241,34 @@ func (b *backend) createStaticAccount(ctx context.Context, req *logical.Request,
 
 	// Construct new static account
 	a := &StaticAccount{
-		Name:             input.name,
-		SecretType:       input.secretType,
-		RawBindings:      input.rawBindings,
-		Bindings:         input.bindings,
-		ServiceAccountId: acctId,
-		TokenGen:         newResources.tokenGen,
+		Name:                input.name,
+		SecretType:          input.secretType,
+		RawBindings:         input.rawBindings,
+		Bindings:            input.bindings,
+		ConditionalBindings: input.conditionalBindings,
+		ServiceAccountId:    acctId,
+		TokenGen:            newResources.tokenGen,
+		TokenImpersonator:   newResources.tokenImpersonator,
+		AllowedAudiences:    input.allowedAudiences,
+		AccessBoundary:      input.accessBoundary,
+
+		AutomatedRotationParams:    input.rotation,
+		RotationInfoResponseParams: input.rotationInfo,
+	}
+
+	// Create the first key of a rotating static account.
+	if input.credentialMode == credentialModeRotating {
+		a.CredentialMode = credentialModeRotating
+		a.RotatingKey = &RotatingKey{
+			RotationPeriod: input.keyRotationPeriod,
+			OverlapPeriod:  input.keyOverlapPeriod,
+		}
+		version, walId, err := b.newRotatingKeyVersion(ctx, req, a)
+		if err != nil {
+			return err
+		}
+		a.RotatingKey.Current = version
+		newWalIds = append(newWalIds, walId)
 	}
 
 	// Save to storage.
@@ -213,14 +303,17 @@ func (b *backend) updateStaticAccount(ctx context.Context, req *logical.Request,
 		b.Logger().Debug("detected bindings change, updating bindings for static account")
 		newBindings := updateInput.bindings
 
-		bindingWals, err := b.updateBindingsForStaticAccount(ctx, req, a, newBindings)
-		if err != nil {
-			return nil, err
+		for _, change := range bindingGroupChanges(a.Bindings, a.ConditionalBindings, newBindings, updateInput.conditionalBindings) {
+			bindingWals, err := b.updateBindingsForStaticAccount(ctx, req, a, change.condition, change.oldBindings, change.newBindings)
+			if err != nil {
+				return nil, err
+			}
+			walIds = append(walIds, bindingWals...)
 		}
-		walIds = append(walIds, bindingWals...)
 
 		a.RawBindings = updateInput.rawBindings
 		a.Bindings = newBindings
+		a.ConditionalBindings = updateInput.conditionalBindings
 		madeChange = true
 	}
 
@@ -235,6 +328,45 @@ func (b *backend) updateStaticAccount(ctx context.Context, req *logical.Request,
 		}
 	}
 
+	if a.SecretType == SecretTypeImpersonatedAccessToken {
+		if a.TokenImpersonator == nil {
+			return nil, fmt.Errorf("unexpected invalid impersonated_access_token static account has no TokenImpersonator")
+		}
+		if !strutil.EquivalentSlices(updateInput.scopes, a.TokenImpersonator.Scopes) {
+			b.Logger().Debug("detected scopes change, updating scopes for static account")
+			a.TokenImpersonator.Scopes = updateInput.scopes
+			madeChange = true
+		}
+	}
+
+	if !strutil.EquivalentSlices(updateInput.allowedAudiences, a.AllowedAudiences) {
+		b.Logger().Debug("detected allowed audiences change, updating allowed audiences for static account")
+		a.AllowedAudiences = updateInput.allowedAudiences
+		madeChange = true
+	}
+
+	if !reflect.DeepEqual(updateInput.accessBoundary, a.AccessBoundary) {
+		b.Logger().Debug("detected access boundary change, updating access boundary for static account")
+		a.AccessBoundary = updateInput.accessBoundary
+		madeChange = true
+	}
+
+	if !reflect.DeepEqual(updateInput.rotation, a.AutomatedRotationParams) || !reflect.DeepEqual(updateInput.rotationInfo, a.RotationInfoResponseParams) {
+		b.Logger().Debug("detected automated rotation change, updating automated rotation for static account")
+		a.AutomatedRotationParams = updateInput.rotation
+		a.RotationInfoResponseParams = updateInput.rotationInfo
+		madeChange = true
+	}
+
+	if a.credentialMode() == credentialModeRotating {
+		if updateInput.keyRotationPeriod != a.RotatingKey.RotationPeriod || updateInput.keyOverlapPeriod != a.RotatingKey.OverlapPeriod {
+			b.Logger().Debug("detected rotation period change, updating rotating key for static account")
+			a.RotatingKey.RotationPeriod = updateInput.keyRotationPeriod
+			a.RotatingKey.OverlapPeriod = updateInput.keyOverlapPeriod
+			madeChange = true
+		}
+	}
+
 	if !madeChange {
 		return nil, nil
 	}
@@ -247,24 +379,114 @@ func (b *backend) updateStaticAccount(ctx context.Context, req *logical.Request,
 	return
 }
 
-func (b *backend) updateBindingsForStaticAccount(ctx context.Context, req *logical.Request, a *StaticAccount, newBindings ResourceBindings) ([]string, error) {
-	oldBindings := a.Bindings
+// saveStaticAccountWithNewTokenKey rotates the static account access_token key and saves it to storage.
+func (b *backend) saveStaticAccountWithNewTokenKey(ctx context.Context, req *logical.Request, acct *StaticAccount) (warning string, err error) {
+	if acct.SecretType != SecretTypeAccessToken {
+		return "", fmt.Errorf("a key is not saved or used for non-access-token static account '%s'", acct.Name)
+	}
+	if acct.TokenGen == nil {
+		return "", fmt.Errorf("unexpected invalid account has no TokenGen")
+	}
+
+	scopes := acct.TokenGen.Scopes
+	oldTokenGen := acct.TokenGen
+	iamAdmin, err := b.IAMAdminClient(req.Storage)
+	if err != nil {
+		return "", err
+	}
+
+	oldWalId, err := b.addWalStaticAccountServiceAccountKey(ctx, req, acct.Name, &acct.ServiceAccountId, oldTokenGen.KeyName)
+	if err != nil {
+		return "", err
+	}
+
+	newTokenGen, err := b.createNewTokenGen(ctx, req, acct.ResourceName(), scopes)
+	if err != nil {
+		return "", err
+	}
+
+	// The service account belongs to the user, so the WAL for the new key is only added once its
+	// name is known rather than cleaning up every key not in use.
+	newWalId, err := b.addWalStaticAccountServiceAccountKey(ctx, req, acct.Name, &acct.ServiceAccountId, newTokenGen.KeyName)
+	if err != nil {
+		if delErr := b.deleteTokenGenKey(ctx, iamAdmin, newTokenGen); delErr != nil {
+			b.Logger().Warn("unable to delete new service account key after failing to create WAL", "key", newTokenGen.KeyName, "error", delErr)
+		}
+		return "", err
+	}
+
+	// Edit static account with new key and save to storage.
+	acct.TokenGen = newTokenGen
+	if err := acct.save(ctx, req.Storage); err != nil {
+		return "", err
+	}
+	b.tokenCache.ExpireAccount(acct.EmailOrId)
+
+	// Try deleting the old key.
+	b.tryDeleteWALs(ctx, req.Storage, newWalId)
+
+	if err := b.deleteTokenGenKey(ctx, iamAdmin, oldTokenGen); err != nil {
+		return fmt.Sprintf("saved static account with new token generator service account key but failed to delete old key (covered by WAL): %v", err), nil
+	}
+	b.tryDeleteWALs(ctx, req.Storage, oldWalId)
+	return "", nil
+}
+
+// bindingGroupChange is the old and new bindings under a condition. A nil condition refers to the
+// unconditional bindings.
+type bindingGroupChange struct {
+	condition   *iamutil.Condition
+	oldBindings ResourceBindings
+	newBindings ResourceBindings
+}
+
+// bindingGroupChanges pairs up the old and new bindings of each condition, including conditions
+// that were added or removed.
+func bindingGroupChanges(oldBinds ResourceBindings, oldConditional ConditionalBindings, newBinds ResourceBindings, newConditional ConditionalBindings) []*bindingGroupChange {
+	changes := []*bindingGroupChange{{
+		oldBindings: oldBinds,
+		newBindings: newBinds,
+	}}
+	for _, cb := range newConditional {
+		changes = append(changes, &bindingGroupChange{
+			condition:   &cb.Condition,
+			oldBindings: oldConditional.find(&cb.Condition),
+			newBindings: cb.Bindings,
+		})
+	}
+	for _, cb := range oldConditional {
+		if newConditional.find(&cb.Condition) != nil {
+			continue
+		}
+		changes = append(changes, &bindingGroupChange{
+			condition:   &cb.Condition,
+			oldBindings: cb.Bindings,
+		})
+	}
+	return changes
+}
+
+// updateBindingsForStaticAccount changes the static account's bindings under the given condition
+// from oldBindings to newBindings.
+func (b *backend) updateBindingsForStaticAccount(ctx context.Context, req *logical.Request, a *StaticAccount, condition *iamutil.Condition, oldBindings, newBindings ResourceBindings) ([]string, error) {
 	bindsToAdd := newBindings.sub(oldBindings)
 	bindsToRemove := oldBindings.sub(newBindings)
 
-	b.Logger().Debug("updating bindings for static account")
-	walIds, err := b.addWalsForStaticAccountBindings(ctx, req, a, bindsToAdd, bindsToRemove)
+	b.Logger().Debug("updating bindings for static account", "condition", condition)
+	walIds, err := b.addWalsForStaticAccountBindings(ctx, req, a, condition, bindsToRemove, bindsToAdd)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := b.createIamBindings(ctx, req, a.EmailOrId, bindsToAdd); err != nil {
+	member := fmt.Sprintf(iamutil.ServiceAccountMemberTmpl, a.EmailOrId)
+	if err := b.createMemberIamBindings(ctx, req, member, condition, bindsToAdd); err != nil {
 		return nil, err
 	}
 
-	if err := b.removeBindings(ctx, req, a.EmailOrId, bindsToRemove); err != nil {
+	if err := b.removeMemberBindings(ctx, req, member, condition, bindsToRemove); err != nil {
 		return nil, err
 	}
+	b.tokenCache.ExpireAccount(a.EmailOrId)
 
 	return walIds, nil
 }
@@ -277,17 +499,29 @@ func (b *backend) addWalsForStaticAccountResources(ctx context.Context, req *log
 	}
 
 	walIds = make([]string, 0, len(boundResources.bindings)+1)
-	for resName, roles := range boundResources.bindings {
-		walId, err := framework.PutWAL(ctx, req.Storage, walTypeIamPolicyDiff, &walIamPolicyStaticAccount{
-			StaticAccount: staticAcctName,
-			AccountId:     boundResources.accountId,
-			Resource:      resName,
-			RolesAdded:    roles.ToSlice(),
-		})
-		if err != nil {
-			return walIds, errwrap.Wrapf("unable to create WAL entry to clean up service account bindings: {{err}}", err)
+	addBindingWals := func(condition *iamutil.Condition, bindings ResourceBindings) error {
+		for resName, roles := range bindings {
+			walId, err := framework.PutWAL(ctx, req.Storage, walTypeIamPolicyDiff, &walIamPolicyStaticAccount{
+				StaticAccount: staticAcctName,
+				AccountId:     boundResources.accountId,
+				Resource:      resName,
+				RolesAdded:    roles.ToSlice(),
+				Condition:     condition,
+			})
+			if err != nil {
+				return errwrap.Wrapf("unable to create WAL entry to clean up service account bindings: {{err}}", err)
+			}
+			walIds = append(walIds, walId)
+		}
+		return nil
+	}
+	if err := addBindingWals(nil, boundResources.bindings); err != nil {
+		return walIds, err
+	}
+	for _, cb := range boundResources.conditionalBindings {
+		if err := addBindingWals(&cb.Condition, cb.Bindings); err != nil {
+			return walIds, err
 		}
-		walIds = append(walIds, walId)
 	}
 
 	if boundResources.tokenGen != nil {
@@ -297,10 +531,22 @@ func (b *backend) addWalsForStaticAccountResources(ctx context.Context, req *log
 		}
 		walIds = append(walIds, walId)
 	}
+
+	if boundResources.tokenImpersonator != nil {
+		walId, err := framework.PutWAL(ctx, req.Storage, walTypeTokenCreator, &walTokenCreator{
+			StaticAccount: staticAcctName,
+			AccountId:     boundResources.accountId,
+			Principal:     boundResources.tokenImpersonator.Principal,
+		})
+		if err != nil {
+			return walIds, errwrap.Wrapf("unable to create WAL entry to clean up token creator binding: {{err}}", err)
+		}
+		walIds = append(walIds, walId)
+	}
 	return walIds, nil
 }
 
-func (b *backend) addWalsForStaticAccountBindings(ctx context.Context, req *logical.Request, a *StaticAccount, removed, added ResourceBindings) (walIds []string, err error) {
+func (b *backend) addWalsForStaticAccountBindings(ctx context.Context, req *logical.Request, a *StaticAccount, condition *iamutil.Condition, removed, added ResourceBindings) (walIds []string, err error) {
 	walIds = make([]string, 0, len(removed)+len(added))
 
 	// Add WALs for resources in added bindings
@@ -310,6 +556,7 @@ func (b *backend) addWalsForStaticAccountBindings(ctx context.Context, req *logi
 			AccountId:     a.ServiceAccountId,
 			Resource:      resource,
 			RolesAdded:    rolesAdded.ToSlice(),
+			Condition:     condition,
 		}
 		if rolesRemoved, ok := removed[resource]; ok {
 			walEntry.RolesRemoved = rolesRemoved.ToSlice()
@@ -331,6 +578,7 @@ func (b *backend) addWalsForStaticAccountBindings(ctx context.Context, req *logi
 			AccountId:     a.ServiceAccountId,
 			Resource:      resource,
 			RolesRemoved:  rolesRemoved.ToSlice(),
+			Condition:     condition,
 		}
 
 		walId, err := framework.PutWAL(ctx, req.Storage, walTypeIamPolicyDiff, walEntry)
@@ -343,15 +591,16 @@ func (b *backend) addWalsForStaticAccountBindings(ctx context.Context, req *logi
 	return walIds, nil
 }
 
-// addWalStaticAccountServiceAccountKey creates WAL to clean up a static account's service account key (for access tokens) if needed.
+// addWalStaticAccountServiceAccountKey creates WAL to clean up a static account's service account key if needed.
+// The key name must be given, as keys of a static account's service account are never swept.
 func (b *backend) addWalStaticAccountServiceAccountKey(ctx context.Context, req *logical.Request, acct string, accountId *gcputil.ServiceAccountId, keyName string) (string, error) {
 	if accountId == nil {
-		return "", fmt.Errorf("given nil account ID for WAL for roleset service account key")
+		return "", fmt.Errorf("given nil account ID for WAL for static account service account key")
 	}
 
 	b.Logger().Debug("add WAL for service account key", "account", accountId.ResourceName(), "keyName", keyName)
 
-	walId, err := framework.PutWAL(ctx, req.Storage, walTypeAccount, &walAccountKey{
+	walId, err := framework.PutWAL(ctx, req.Storage, walTypeAccountKey, &walAccountKey{
 		StaticAccount:      acct,
 		ServiceAccountName: accountId.ResourceName(),
 		KeyName:            keyName,
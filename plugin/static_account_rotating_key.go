@@ -0,0 +1,271 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/logical"
+	"google.golang.org/api/iam/v1"
+)
+
+const (
+	// Static account credential modes for service_account_key secrets.
+	credentialModeLeased   = "leased"
+	credentialModeRotating = "rotating"
+
+	// minKeyRotationPeriod is the shortest rotation period of a rotating key, as rotations are
+	// checked by the periodic function.
+	minKeyRotationPeriod = time.Minute
+)
+
+// RotatingKey is the key Vault owns for a static account in rotating credential mode. The
+// current key is rotated every RotationPeriod, and the previous key is kept for OverlapPeriod
+// after a rotation so consumers can switch to the new key.
+type RotatingKey struct {
+	RotationPeriod time.Duration
+	OverlapPeriod  time.Duration
+
+	Current  *RotatingKeyVersion
+	Previous *RotatingKeyVersion `json:",omitempty"`
+}
+
+// RotatingKeyVersion is a service account key of a rotating key.
+type RotatingKeyVersion struct {
+	KeyName        string
+	PrivateKeyData string
+	KeyAlgorithm   string
+	KeyType        string
+	CreateTime     time.Time
+
+	// DeleteTime is when a previous key is deleted.
+	DeleteTime time.Time `json:",omitempty"`
+}
+
+// nextRotation returns when the current key is due to be rotated.
+func (rk *RotatingKey) nextRotation() time.Time {
+	if rk.Current == nil {
+		return time.Time{}
+	}
+	return rk.Current.CreateTime.Add(rk.RotationPeriod)
+}
+
+// credentialMode returns the credential mode of the static account. Accounts created before
+// modes were added have an empty mode, which issues leased keys.
+func (a *StaticAccount) credentialMode() string {
+	if a.CredentialMode == "" {
+		return credentialModeLeased
+	}
+	return a.CredentialMode
+}
+
+// rotatingKeyNames returns the names of the current and previous keys of a rotating key.
+func (a *StaticAccount) rotatingKeyNames() []string {
+	if a.RotatingKey == nil {
+		return nil
+	}
+	var names []string
+	for _, v := range []*RotatingKeyVersion{a.RotatingKey.Current, a.RotatingKey.Previous} {
+		if v != nil {
+			names = append(names, v.KeyName)
+		}
+	}
+	return names
+}
+
+// newRotatingKeyVersion creates a new key for the static account. The returned WAL cleans up
+// the key unless it is saved to the account, and should be deleted once it is.
+func (b *backend) newRotatingKeyVersion(ctx context.Context, req *logical.Request, a *StaticAccount) (version *RotatingKeyVersion, walId string, err error) {
+	iamAdmin, err := b.IAMAdminClient(req.Storage)
+	if err != nil {
+		return nil, "", err
+	}
+	key, err := iamAdmin.Projects.ServiceAccounts.Keys.Create(a.ResourceName(), &iam.CreateServiceAccountKeyRequest{
+		KeyAlgorithm:   keyAlgorithmRSA2k,
+		PrivateKeyType: privateKeyTypeJson,
+	}).Context(ctx).Do()
+	if err != nil {
+		return nil, "", keyCreateError(ctx, iamAdmin, &a.ServiceAccountId, err)
+	}
+
+	// The WAL names the key so that only it is cleaned up, never other keys of the user's account.
+	walId, err = b.addWalStaticAccountServiceAccountKey(ctx, req, a.Name, &a.ServiceAccountId, key.Name)
+	if err != nil {
+		if _, delErr := iamAdmin.Projects.ServiceAccounts.Keys.Delete(key.Name).Context(ctx).Do(); delErr != nil && !isGoogleAccountKeyNotFoundErr(delErr) {
+			b.Logger().Warn("unable to delete new service account key after failing to create WAL", "key", key.Name, "error", delErr)
+		}
+		return nil, "", err
+	}
+
+	return &RotatingKeyVersion{
+		KeyName:        key.Name,
+		PrivateKeyData: key.PrivateKeyData,
+		KeyAlgorithm:   key.KeyAlgorithm,
+		KeyType:        key.PrivateKeyType,
+		CreateTime:     time.Now().UTC(),
+	}, walId, nil
+}
+
+// rotateStaticAccountKey replaces the current key of a static account in rotating credential
+// mode. The replaced key becomes the previous key until the overlap period ends, and a previous
+// key left from an earlier rotation is deleted first. The static account lock must be held.
+func (b *backend) rotateStaticAccountKey(ctx context.Context, req *logical.Request, a *StaticAccount) (warnings []string, err error) {
+	if a.credentialMode() != credentialModeRotating || a.RotatingKey == nil {
+		return nil, fmt.Errorf("static account %q does not use %q credential mode", a.Name, credentialModeRotating)
+	}
+	rk := a.RotatingKey
+
+	if rk.Previous != nil {
+		warnings, err = b.deletePreviousRotatingKey(ctx, req, a)
+		if err != nil {
+			return warnings, err
+		}
+	}
+
+	version, walId, err := b.newRotatingKeyVersion(ctx, req, a)
+	if err != nil {
+		return warnings, err
+	}
+
+	b.Logger().Debug("rotating static account key", "static_account", a.Name, "key", version.KeyName)
+	if rk.Current != nil {
+		rk.Previous = rk.Current
+		rk.Previous.DeleteTime = version.CreateTime.Add(rk.OverlapPeriod)
+	}
+	rk.Current = version
+	if err := a.save(ctx, req.Storage); err != nil {
+		return warnings, err
+	}
+	b.tryDeleteWALs(ctx, req.Storage, walId)
+
+	if rk.Previous != nil && rk.OverlapPeriod <= 0 {
+		ws, err := b.deletePreviousRotatingKey(ctx, req, a)
+		warnings = append(warnings, ws...)
+		if err != nil {
+			return warnings, err
+		}
+	}
+	return warnings, nil
+}
+
+// deletePreviousRotatingKey deletes the previous key of a rotating key. The key is removed from
+// storage first and a WAL deletes it if deleting it fails. The static account lock must be held.
+func (b *backend) deletePreviousRotatingKey(ctx context.Context, req *logical.Request, a *StaticAccount) (warnings []string, err error) {
+	previous := a.RotatingKey.Previous
+
+	walId, err := b.addWalStaticAccountServiceAccountKey(ctx, req, a.Name, &a.ServiceAccountId, previous.KeyName)
+	if err != nil {
+		return nil, err
+	}
+
+	b.Logger().Debug("deleting previous static account key", "static_account", a.Name, "key", previous.KeyName)
+	a.RotatingKey.Previous = nil
+	if err := a.save(ctx, req.Storage); err != nil {
+		return nil, err
+	}
+
+	iamAdmin, err := b.IAMAdminClient(req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	_, err = iamAdmin.Projects.ServiceAccounts.Keys.Delete(previous.KeyName).Context(ctx).Do()
+	if err != nil && !isGoogleAccountKeyNotFoundErr(err) {
+		return []string{fmt.Sprintf("unable to delete previous key %q of static account %q (WAL entry to clean-up later has been added): %v", previous.KeyName, a.Name, err)}, nil
+	}
+	b.tryDeleteWALs(ctx, req.Storage, walId)
+	return nil, nil
+}
+
+// tryDeleteRotatingKeys deletes the keys of a deleted static account, given as a map of key name
+// to the WAL that cleans up the key. Keys that fail to delete are left to their WAL.
+func (b *backend) tryDeleteRotatingKeys(ctx context.Context, req *logical.Request, walIds map[string]string) (warnings []string) {
+	if len(walIds) == 0 {
+		return nil
+	}
+
+	iamAdmin, err := b.IAMAdminClient(req.Storage)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	for keyName, walId := range walIds {
+		_, err := iamAdmin.Projects.ServiceAccounts.Keys.Delete(keyName).Context(ctx).Do()
+		if err != nil && !isGoogleAccountKeyNotFoundErr(err) {
+			warnings = append(warnings, fmt.Sprintf("unable to delete service account key %q (WAL entry to clean-up later has been added): %v", keyName, err))
+			continue
+		}
+		b.tryDeleteWALs(ctx, req.Storage, walId)
+	}
+	return warnings
+}
+
+// rotatingKeyTimes returns the last_rotated and ttl_until_rotation response fields of a
+// rotating key.
+func rotatingKeyTimes(rk *RotatingKey) map[string]interface{} {
+	var lastRotated string
+	if rk.Current != nil {
+		lastRotated = rk.Current.CreateTime.Format(time.RFC3339)
+	}
+	ttl := time.Until(rk.nextRotation())
+	if ttl < 0 {
+		ttl = 0
+	}
+	return map[string]interface{}{
+		"last_rotated":       lastRotated,
+		"ttl_until_rotation": int64(ttl / time.Second),
+	}
+}
+
+// periodicStaticKeyRotation rotates the keys of static accounts in rotating credential mode once
+// they are due, and deletes previous keys once their overlap period has ended.
+func (b *backend) periodicStaticKeyRotation(ctx context.Context, req *logical.Request) error {
+	// Static accounts are replicated, so only the primary's active node rotates their keys.
+	if b.System().ReplicationState().HasState(consts.ReplicationPerformanceSecondary | consts.ReplicationPerformanceStandby) {
+		return nil
+	}
+
+	names, err := req.Storage.List(ctx, fmt.Sprintf("%s/", staticAccountStoragePrefix))
+	if err != nil {
+		return err
+	}
+
+	var merr *multierror.Error
+	for _, name := range names {
+		if err := b.periodicStaticAccountKeyRotation(ctx, req, name); err != nil {
+			merr = multierror.Append(merr, errwrap.Wrapf(fmt.Sprintf("unable to rotate key of static account %q: {{err}}", name), err))
+		}
+	}
+	return merr.ErrorOrNil()
+}
+
+func (b *backend) periodicStaticAccountKeyRotation(ctx context.Context, req *logical.Request, name string) error {
+	b.staticAccountLock.Lock()
+	defer b.staticAccountLock.Unlock()
+
+	a, err := b.getStaticAccount(name, ctx, req.Storage)
+	if err != nil {
+		return err
+	}
+	if a == nil || a.credentialMode() != credentialModeRotating || a.RotatingKey == nil {
+		return nil
+	}
+
+	now := time.Now()
+	var warnings []string
+	switch {
+	case !now.Before(a.RotatingKey.nextRotation()):
+		warnings, err = b.rotateStaticAccountKey(ctx, req, a)
+	case a.RotatingKey.Previous != nil && !now.Before(a.RotatingKey.Previous.DeleteTime):
+		warnings, err = b.deletePreviousRotatingKey(ctx, req, a)
+	}
+	for _, w := range warnings {
+		b.Logger().Warn(w)
+	}
+	return err
+}
@@ -0,0 +1,138 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+)
+
+func Test_ParseOkInputCredentialMode(t *testing.T) {
+	schema := map[string]*framework.FieldSchema{
+		"credential_mode": {Type: framework.TypeString},
+		"rotation_period": {Type: framework.TypeDurationSecond},
+		"overlap_period":  {Type: framework.TypeDurationSecond},
+	}
+
+	tests := []struct {
+		name        string
+		raw         map[string]interface{}
+		secretType  string
+		initialMode string
+		wantMode    string
+		wantPeriod  time.Duration
+		wantOverlap time.Duration
+		wantErr     bool
+	}{
+		{
+			name:       "defaults to leased",
+			raw:        map[string]interface{}{},
+			secretType: SecretTypeKey,
+			wantMode:   credentialModeLeased,
+		},
+		{
+			name: "rotating",
+			raw: map[string]interface{}{
+				"credential_mode": credentialModeRotating,
+				"rotation_period": "24h",
+				"overlap_period":  "1h",
+			},
+			secretType:  SecretTypeKey,
+			wantMode:    credentialModeRotating,
+			wantPeriod:  24 * time.Hour,
+			wantOverlap: time.Hour,
+		},
+		{
+			name:       "unknown mode",
+			raw:        map[string]interface{}{"credential_mode": "shared"},
+			secretType: SecretTypeKey,
+			wantErr:    true,
+		},
+		{
+			name: "rotating access token",
+			raw: map[string]interface{}{
+				"credential_mode": credentialModeRotating,
+				"rotation_period": "24h",
+			},
+			secretType: SecretTypeAccessToken,
+			wantErr:    true,
+		},
+		{
+			name:       "rotating without rotation period",
+			raw:        map[string]interface{}{"credential_mode": credentialModeRotating},
+			secretType: SecretTypeKey,
+			wantErr:    true,
+		},
+		{
+			name: "overlap not less than rotation period",
+			raw: map[string]interface{}{
+				"credential_mode": credentialModeRotating,
+				"rotation_period": "1h",
+				"overlap_period":  "1h",
+			},
+			secretType: SecretTypeKey,
+			wantErr:    true,
+		},
+		{
+			name:       "overlap in leased mode",
+			raw:        map[string]interface{}{"overlap_period": "1h"},
+			secretType: SecretTypeKey,
+			wantErr:    true,
+		},
+		{
+			name:        "mode cannot be updated",
+			raw:         map[string]interface{}{"credential_mode": credentialModeLeased},
+			secretType:  SecretTypeKey,
+			initialMode: credentialModeRotating,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := &inputParams{secretType: tt.secretType, credentialMode: tt.initialMode}
+			_, err := input.parseOkInputCredentialMode(&framework.FieldData{Raw: tt.raw, Schema: schema})
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("parseOkInputCredentialMode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if input.credentialMode != tt.wantMode {
+				t.Fatalf("expected mode %q, got %q", tt.wantMode, input.credentialMode)
+			}
+			if input.keyRotationPeriod != tt.wantPeriod || input.keyOverlapPeriod != tt.wantOverlap {
+				t.Fatalf("expected periods %v/%v, got %v/%v", tt.wantPeriod, tt.wantOverlap, input.keyRotationPeriod, input.keyOverlapPeriod)
+			}
+		})
+	}
+}
+
+func Test_RotatingKeyTimes(t *testing.T) {
+	created := time.Now().Add(-time.Hour).UTC()
+	rk := &RotatingKey{
+		RotationPeriod: 2 * time.Hour,
+		Current:        &RotatingKeyVersion{KeyName: "current", CreateTime: created},
+		Previous:       &RotatingKeyVersion{KeyName: "previous"},
+	}
+
+	times := rotatingKeyTimes(rk)
+	if times["last_rotated"] != created.Format(time.RFC3339) {
+		t.Fatalf("unexpected last_rotated %v", times["last_rotated"])
+	}
+	if ttl := times["ttl_until_rotation"].(int64); ttl <= 0 || ttl > int64(time.Hour/time.Second) {
+		t.Fatalf("unexpected ttl_until_rotation %d", ttl)
+	}
+
+	rk.RotationPeriod = time.Minute
+	if ttl := rotatingKeyTimes(rk)["ttl_until_rotation"].(int64); ttl != 0 {
+		t.Fatalf("expected overdue key to have no ttl, got %d", ttl)
+	}
+
+	owned := ownedKeys(nil, rk)
+	if owned["current"] != keyStatusCurrent || owned["previous"] != keyStatusPrevious {
+		t.Fatalf("unexpected owned keys %v", owned)
+	}
+}
@@ -0,0 +1,551 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/go-gcp-common/gcputil"
+	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/iamutil"
+	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/util"
+	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/helper/useragent"
+	"github.com/hashicorp/vault/sdk/logical"
+	"google.golang.org/api/iam/v1"
+)
+
+const (
+	tidyStateInactive = "Inactive"
+	tidyStateRunning  = "Running"
+	tidyStateFinished = "Finished"
+	tidyStateError    = "Error"
+
+	// defaultTidySafetyBuffer is the default minimum age of a resource before tidy deletes it.
+	defaultTidySafetyBuffer = 72 * time.Hour
+
+	deletedServiceAccountMemberPrefix = "deleted:serviceAccount:"
+)
+
+// roleSetAccountNameRegex matches the names generated by generateAccountNameForRoleSet and
+// captures their creation time.
+var roleSetAccountNameRegex = regexp.MustCompile(`^vault[a-zA-Z0-9-]*-([0-9]+)$`)
+
+type tidyParams struct {
+	SafetyBuffer                 time.Duration
+	DryRun                       bool
+	TidyServiceAccounts          bool
+	TidyUnlabeledServiceAccounts bool
+	TidyKeys                     bool
+	TidyBindings                 bool
+}
+
+// tidiedBinding is a member removed (or, in a dry run, that would be removed) from a role.
+type tidiedBinding struct {
+	Resource  string
+	Role      string
+	Condition *iamutil.Condition
+	Member    string
+}
+
+// tidyStatus is the state of the last tidy operation. It is only accessed while holding
+// tidyStatusLock.
+type tidyStatus struct {
+	params       tidyParams
+	state        string
+	err          error
+	timeStarted  time.Time
+	timeFinished time.Time
+
+	serviceAccounts []string
+	keys            []string
+	bindings        []*tidiedBinding
+	errors          []string
+}
+
+func (s *tidyStatus) asOutput() map[string]interface{} {
+	out := map[string]interface{}{
+		"state":                           tidyStateInactive,
+		"error":                           nil,
+		"dry_run":                         nil,
+		"safety_buffer":                   nil,
+		"tidy_service_accounts":           nil,
+		"tidy_unlabeled_service_accounts": nil,
+		"tidy_keys":                       nil,
+		"tidy_bindings":                   nil,
+		"time_started":                    nil,
+		"time_finished":                   nil,
+		"orphaned_service_accounts":       []string{},
+		"orphaned_keys":                   []string{},
+		"stale_bindings":                  []map[string]interface{}{},
+		"errors":                          []string{},
+	}
+	if s == nil {
+		return out
+	}
+
+	out["state"] = s.state
+	out["dry_run"] = s.params.DryRun
+	out["safety_buffer"] = int64(s.params.SafetyBuffer / time.Second)
+	out["tidy_service_accounts"] = s.params.TidyServiceAccounts
+	out["tidy_unlabeled_service_accounts"] = s.params.TidyUnlabeledServiceAccounts
+	out["tidy_keys"] = s.params.TidyKeys
+	out["tidy_bindings"] = s.params.TidyBindings
+	out["time_started"] = s.timeStarted.Format(time.RFC3339)
+	if !s.timeFinished.IsZero() {
+		out["time_finished"] = s.timeFinished.Format(time.RFC3339)
+	}
+	if s.err != nil {
+		out["error"] = s.err.Error()
+	}
+	out["orphaned_service_accounts"] = append([]string{}, s.serviceAccounts...)
+	out["orphaned_keys"] = append([]string{}, s.keys...)
+	out["errors"] = append([]string{}, s.errors...)
+
+	bindings := make([]map[string]interface{}, 0, len(s.bindings))
+	for _, tb := range s.bindings {
+		bind := map[string]interface{}{
+			"resource": tb.Resource,
+			"role":     tb.Role,
+			"member":   tb.Member,
+		}
+		if tb.Condition != nil {
+			bind["condition"] = map[string]interface{}{
+				"title":       tb.Condition.Title,
+				"description": tb.Condition.Description,
+				"expression":  tb.Condition.Expression,
+			}
+		}
+		bindings = append(bindings, bind)
+	}
+	out["stale_bindings"] = bindings
+	return out
+}
+
+// tidyInventory is a snapshot of the GCP resources referenced by role sets and static accounts.
+type tidyInventory struct {
+	// projects are the projects role set service accounts are created in.
+	projects util.StringSet
+
+	// accounts are the emails of all service accounts in use.
+	accounts util.StringSet
+
+	// roleSetAccounts are the service accounts of role sets, whose keys Vault manages.
+	roleSetAccounts []*tidyRoleSetAccount
+
+	// resources are the resources role sets and static accounts have bindings on.
+	resources util.StringSet
+
+	// mountId is the ID recorded on service accounts created by this mount, if any were.
+	mountId string
+}
+
+type tidyRoleSetAccount struct {
+	accountId  gcputil.ServiceAccountId
+	secretType string
+	keyName    string
+}
+
+func (inv *tidyInventory) addBoundResources(resources *gcpAccountResources) {
+	inv.accounts.Add(resources.accountId.EmailOrId)
+	for resName := range resources.bindings {
+		inv.resources.Add(resName)
+	}
+	for _, cb := range resources.conditionalBindings {
+		for resName := range cb.Bindings {
+			inv.resources.Add(resName)
+		}
+	}
+}
+
+// roleSetAccountCreated returns the creation time encoded in the name of a service account
+// created for a role set by the mount with the given ID, and whether the account follows the
+// role set naming convention and records that mount. Accounts without a mount ID, such as those
+// created before mount IDs were recorded, may belong to another mount and are only matched if
+// unlabeled is set.
+func roleSetAccountCreated(sa *iam.ServiceAccount, mountId string, unlabeled bool) (time.Time, bool) {
+	switch {
+	case sa.Description == "":
+		if !unlabeled {
+			return time.Time{}, false
+		}
+	case mountId == "" || sa.Description != fmt.Sprintf(serviceAccountDescriptionTmpl, mountId):
+		return time.Time{}, false
+	}
+	if !strings.HasPrefix(sa.DisplayName, fmt.Sprintf(serviceAccountDisplayNameTmpl, "")) {
+		return time.Time{}, false
+	}
+	return roleSetAccountNameCreated(sa.Email)
+}
+
+// roleSetAccountNameCreated returns the creation time encoded in the email of a service
+// account whose name follows the role set naming convention.
+func roleSetAccountNameCreated(email string) (time.Time, bool) {
+	name := strings.SplitN(email, "@", 2)[0]
+	m := roleSetAccountNameRegex.FindStringSubmatch(name)
+	if m == nil {
+		return time.Time{}, false
+	}
+	ts, err := strconv.ParseInt(m[1], 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(ts, 0), true
+}
+
+// isStaleRoleSetMember returns whether the IAM member is a deleted role set service account, or
+// one of the orphaned accounts, that is not in use.
+func isStaleRoleSetMember(member string, inUse, orphaned util.StringSet) bool {
+	if strings.HasPrefix(member, deletedServiceAccountMemberPrefix) {
+		// Deleted members have the form "deleted:serviceAccount:<email>?uid=<id>".
+		email := strings.SplitN(strings.TrimPrefix(member, deletedServiceAccountMemberPrefix), "?", 2)[0]
+		_, ok := roleSetAccountNameCreated(email)
+		return ok && !inUse.Includes(email)
+	}
+	if email := strings.TrimPrefix(member, fmt.Sprintf(iamutil.ServiceAccountMemberTmpl, "")); email != member {
+		return orphaned.Includes(email)
+	}
+	return false
+}
+
+// startTidy starts a tidy operation in the background. It returns an error if one is already running.
+func (b *backend) startTidy(req *logical.Request, params tidyParams) error {
+	if !b.tidyRunning.CompareAndSwap(false, true) {
+		return fmt.Errorf("tidy operation already in progress")
+	}
+
+	b.tidyStatusLock.Lock()
+	b.tidyStatus = &tidyStatus{
+		params:      params,
+		state:       tidyStateRunning,
+		timeStarted: time.Now(),
+	}
+	b.lastTidy = b.tidyStatus.timeStarted
+	b.tidyStatusLock.Unlock()
+
+	go func() {
+		defer b.tidyRunning.Store(false)
+
+		// The request context is cancelled once the request returns.
+		err := b.doTidy(context.Background(), req, params)
+
+		b.tidyStatusLock.Lock()
+		defer b.tidyStatusLock.Unlock()
+		b.tidyStatus.timeFinished = time.Now()
+		if err != nil {
+			b.tidyStatus.state = tidyStateError
+			b.tidyStatus.err = err
+			b.Logger().Error("error running tidy", "error", err)
+			return
+		}
+		b.tidyStatus.state = tidyStateFinished
+		b.Logger().Info("finished tidy",
+			"dry_run", params.DryRun,
+			"service_accounts", len(b.tidyStatus.serviceAccounts),
+			"keys", len(b.tidyStatus.keys),
+			"bindings", len(b.tidyStatus.bindings),
+			"errors", len(b.tidyStatus.errors))
+	}()
+	return nil
+}
+
+// updateTidyStatus applies f to the current tidy status.
+func (b *backend) updateTidyStatus(f func(s *tidyStatus)) {
+	b.tidyStatusLock.Lock()
+	defer b.tidyStatusLock.Unlock()
+	f(b.tidyStatus)
+}
+
+func (b *backend) addTidyError(err error) {
+	b.updateTidyStatus(func(s *tidyStatus) {
+		s.errors = append(s.errors, err.Error())
+	})
+}
+
+func (b *backend) doTidy(ctx context.Context, req *logical.Request, params tidyParams) error {
+	inv, err := b.tidyInventory(ctx, req.Storage)
+	if err != nil {
+		return err
+	}
+
+	iamAdmin, err := b.IAMAdminClient(req.Storage)
+	if err != nil {
+		return err
+	}
+
+	orphaned := make(util.StringSet)
+	if params.TidyServiceAccounts {
+		if orphaned, err = b.tidyServiceAccounts(ctx, iamAdmin, inv, params); err != nil {
+			return err
+		}
+	}
+	if params.TidyKeys {
+		b.tidyKeys(ctx, iamAdmin, inv, params)
+	}
+	if params.TidyBindings {
+		if err := b.tidyBindings(ctx, req, inv, orphaned, params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tidyInventory lists the service accounts, keys and bound resources in use, holding the
+// respective lock while each role set or static account is read.
+func (b *backend) tidyInventory(ctx context.Context, s logical.Storage) (*tidyInventory, error) {
+	inv := &tidyInventory{
+		projects:  make(util.StringSet),
+		accounts:  make(util.StringSet),
+		resources: make(util.StringSet),
+	}
+
+	mountId, err := b.getMountId(ctx, s, false)
+	if err != nil {
+		return nil, err
+	}
+	inv.mountId = mountId
+
+	rsNames, err := s.List(ctx, fmt.Sprintf("%s/", rolesetStoragePrefix))
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range rsNames {
+		b.rolesetLock.Lock()
+		rs, err := getRoleSet(name, ctx, s)
+		b.rolesetLock.Unlock()
+		if err != nil {
+			return nil, err
+		}
+		if rs == nil || rs.AccountId == nil {
+			continue
+		}
+
+		inv.projects.Add(rs.AccountId.Project)
+		inv.addBoundResources(rs.boundResources())
+		acct := &tidyRoleSetAccount{
+			accountId:  *rs.AccountId,
+			secretType: rs.SecretType,
+		}
+		if rs.TokenGen != nil {
+			acct.keyName = rs.TokenGen.KeyName
+		}
+		inv.roleSetAccounts = append(inv.roleSetAccounts, acct)
+		for _, id := range rs.PoolAccounts {
+			inv.accounts.Add(id.EmailOrId)
+			inv.roleSetAccounts = append(inv.roleSetAccounts, &tidyRoleSetAccount{
+				accountId:  *id,
+				secretType: rs.SecretType,
+			})
+		}
+	}
+
+	saNames, err := s.List(ctx, fmt.Sprintf("%s/", staticAccountStoragePrefix))
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range saNames {
+		b.staticAccountLock.Lock()
+		a, err := b.getStaticAccount(name, ctx, s)
+		b.staticAccountLock.Unlock()
+		if err != nil {
+			return nil, err
+		}
+		if a == nil {
+			continue
+		}
+		inv.addBoundResources(a.boundResources())
+	}
+	return inv, nil
+}
+
+// tidyServiceAccounts deletes service accounts created by this mount, and optionally those
+// without a mount ID, that follow the role set naming convention but are not used by any role
+// set, and returns their emails.
+func (b *backend) tidyServiceAccounts(ctx context.Context, iamAdmin *iam.Service, inv *tidyInventory, params tidyParams) (util.StringSet, error) {
+	orphaned := make(util.StringSet)
+	if inv.mountId == "" && !params.TidyUnlabeledServiceAccounts {
+		return orphaned, nil
+	}
+
+	projects := inv.projects.ToSlice()
+	sort.Strings(projects)
+
+	for _, project := range projects {
+		var found []*iam.ServiceAccount
+		err := iamAdmin.Projects.ServiceAccounts.List(fmt.Sprintf("projects/%s", project)).Pages(ctx, func(page *iam.ListServiceAccountsResponse) error {
+			for _, sa := range page.Accounts {
+				created, ok := roleSetAccountCreated(sa, inv.mountId, params.TidyUnlabeledServiceAccounts)
+				if !ok || inv.accounts.Includes(sa.Email) || time.Since(created) < params.SafetyBuffer {
+					continue
+				}
+				found = append(found, sa)
+			}
+			return nil
+		})
+		if err != nil {
+			b.addTidyError(errwrap.Wrapf(fmt.Sprintf("unable to list service accounts in project %q: {{err}}", project), err))
+			continue
+		}
+
+		for _, sa := range found {
+			if !params.DryRun {
+				b.Logger().Info("deleting orphaned role set service account", "service_account", sa.Email)
+				if err := b.deleteServiceAccount(ctx, iamAdmin, gcputil.ServiceAccountId{Project: project, EmailOrId: sa.Email}); err != nil {
+					b.addTidyError(errwrap.Wrapf(fmt.Sprintf("unable to delete service account %q: {{err}}", sa.Email), err))
+					continue
+				}
+			}
+			orphaned.Add(sa.Email)
+			b.updateTidyStatus(func(s *tidyStatus) {
+				s.serviceAccounts = append(s.serviceAccounts, sa.Email)
+			})
+		}
+	}
+	return orphaned, nil
+}
+
+// tidyKeys deletes user-managed keys on role set service accounts that are not the role set's
+// token key. Keys of service_account_key role sets may belong to leases, so they are only
+// deleted once they are older than the longest possible lease.
+func (b *backend) tidyKeys(ctx context.Context, iamAdmin *iam.Service, inv *tidyInventory, params tidyParams) {
+	for _, acct := range inv.roleSetAccounts {
+		minAge := params.SafetyBuffer
+		if acct.secretType == SecretTypeKey {
+			minAge += b.System().MaxLeaseTTL()
+		}
+
+		resp, err := iamAdmin.Projects.ServiceAccounts.Keys.List(acct.accountId.ResourceName()).KeyTypes("USER_MANAGED").Context(ctx).Do()
+		if err != nil {
+			if !isGoogleAccountNotFoundErr(err) {
+				b.addTidyError(errwrap.Wrapf(fmt.Sprintf("unable to list keys of service account %q: {{err}}", acct.accountId.EmailOrId), err))
+			}
+			continue
+		}
+
+		for _, key := range resp.Keys {
+			if key.Name == acct.keyName {
+				continue
+			}
+			created, err := time.Parse(time.RFC3339, key.ValidAfterTime)
+			if err != nil || time.Since(created) < minAge {
+				continue
+			}
+
+			if !params.DryRun {
+				b.Logger().Info("deleting orphaned service account key", "key", key.Name)
+				_, err := iamAdmin.Projects.ServiceAccounts.Keys.Delete(key.Name).Context(ctx).Do()
+				if err != nil && !isGoogleAccountKeyNotFoundErr(err) {
+					b.addTidyError(errwrap.Wrapf(fmt.Sprintf("unable to delete key %q: {{err}}", key.Name), err))
+					continue
+				}
+			}
+			b.updateTidyStatus(func(s *tidyStatus) {
+				s.keys = append(s.keys, key.Name)
+			})
+		}
+	}
+}
+
+// tidyBindings removes deleted role set service accounts, and the orphaned accounts, from the
+// IAM policies of the resources role sets and static accounts are bound to.
+func (b *backend) tidyBindings(ctx context.Context, req *logical.Request, inv *tidyInventory, orphaned util.StringSet, params tidyParams) error {
+	httpC, err := b.HTTPClient(req.Storage)
+	if err != nil {
+		return err
+	}
+	apiHandle := iamutil.GetApiHandle(httpC, useragent.PluginString(b.pluginEnv,
+		userAgentPluginName))
+
+	resourceNames := inv.resources.ToSlice()
+	sort.Strings(resourceNames)
+
+	for _, resName := range resourceNames {
+		resource, err := b.resources.Parse(resName)
+		if err != nil {
+			b.addTidyError(errwrap.Wrapf(fmt.Sprintf("unable to parse resource %q: {{err}}", resName), err))
+			continue
+		}
+		// removed is set from the policy of the last attempt, as a retried update may remove
+		// other members than the first.
+		var removed []*tidiedBinding
+		_, err = iamutil.UpdatePolicy(ctx, apiHandle, resource, func(p *iamutil.Policy) (bool, *iamutil.Policy) {
+			changed, newP := p.RemoveMembers(func(member string) bool {
+				return isStaleRoleSetMember(member, inv.accounts, orphaned)
+			})
+			removed = nil
+			if !changed {
+				return false, nil
+			}
+			for _, diff := range iamutil.DiffPolicies(p, newP) {
+				for _, member := range diff.MembersRemoved {
+					removed = append(removed, &tidiedBinding{
+						Resource:  resName,
+						Role:      diff.Role,
+						Condition: diff.Condition,
+						Member:    member,
+					})
+				}
+			}
+			if params.DryRun {
+				return false, nil
+			}
+			b.Logger().Info("removing stale role set bindings", "resource", resName)
+			return true, newP
+		}, nil)
+		if err != nil {
+			b.addTidyError(errwrap.Wrapf(fmt.Sprintf("unable to remove stale bindings from resource %q: {{err}}", resName), err))
+			continue
+		}
+		if len(removed) == 0 {
+			continue
+		}
+		b.updateTidyStatus(func(s *tidyStatus) {
+			s.bindings = append(s.bindings, removed...)
+		})
+	}
+	return nil
+}
+
+// periodicTidy starts a tidy operation if automatic tidying is enabled and due.
+func (b *backend) periodicTidy(ctx context.Context, req *logical.Request) error {
+	// Only the primary's active node manages GCP resources.
+	if b.System().ReplicationState().HasState(consts.ReplicationPerformanceSecondary | consts.ReplicationPerformanceStandby) {
+		return nil
+	}
+
+	cfg, err := getConfig(ctx, req.Storage)
+	if err != nil {
+		return err
+	}
+	if cfg == nil || cfg.TidyInterval <= 0 {
+		return nil
+	}
+
+	b.tidyStatusLock.RLock()
+	due := time.Since(b.lastTidy) >= cfg.TidyInterval
+	b.tidyStatusLock.RUnlock()
+	if !due {
+		return nil
+	}
+
+	safetyBuffer := cfg.TidySafetyBuffer
+	if safetyBuffer <= 0 {
+		safetyBuffer = defaultTidySafetyBuffer
+	}
+	b.Logger().Debug("starting automatic tidy")
+	if err := b.startTidy(req, tidyParams{
+		SafetyBuffer: safetyBuffer,
+		TidyKeys:     true,
+		TidyBindings: true,
+	}); err != nil {
+		b.Logger().Debug("skipping automatic tidy", "error", err)
+	}
+	return nil
+}
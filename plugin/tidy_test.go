@@ -0,0 +1,179 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gcpsecrets
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault-plugin-secrets-gcp/plugin/util"
+	"google.golang.org/api/iam/v1"
+)
+
+func Test_RoleSetAccountCreated(t *testing.T) {
+	displayName := roleSetServiceAccountDisplayName("my-role")
+	mountId := "a1b2c3d4-0000-0000-0000-000000000000"
+	description := fmt.Sprintf(serviceAccountDescriptionTmpl, mountId)
+
+	tests := []struct {
+		name        string
+		email       string
+		displayName string
+		description string
+		want        time.Time
+		wantOk      bool
+	}{
+		{
+			name:        "generated name",
+			email:       "vaultmy-role-1600000000@project.iam.gserviceaccount.com",
+			displayName: displayName,
+			description: description,
+			want:        time.Unix(1600000000, 0),
+			wantOk:      true,
+		},
+		{
+			name:        "truncated display name",
+			email:       "vaultdisplay-name-that-1600000000@project.iam.gserviceaccount.com",
+			displayName: roleSetServiceAccountDisplayName("display-name-that-is-really-long-vault-plugin-secrets-gcp-role-name"),
+			description: description,
+			want:        time.Unix(1600000000, 0),
+			wantOk:      true,
+		},
+		{
+			name:        "other mount",
+			email:       "vaultmy-role-1600000000@project.iam.gserviceaccount.com",
+			displayName: displayName,
+			description: fmt.Sprintf(serviceAccountDescriptionTmpl, "ffffffff-0000-0000-0000-000000000000"),
+		},
+		{
+			name:        "no mount ID",
+			email:       "vaultmy-role-1600000000@project.iam.gserviceaccount.com",
+			displayName: displayName,
+		},
+		{
+			name:        "other display name",
+			email:       "vaultmy-role-1600000000@project.iam.gserviceaccount.com",
+			displayName: "My service account",
+			description: description,
+		},
+		{
+			name:        "no timestamp suffix",
+			email:       "vaultmy-role@project.iam.gserviceaccount.com",
+			displayName: displayName,
+			description: description,
+		},
+		{
+			name:        "not a vault account",
+			email:       "my-role-1600000000@project.iam.gserviceaccount.com",
+			displayName: displayName,
+			description: description,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sa := &iam.ServiceAccount{Email: tt.email, DisplayName: tt.displayName, Description: tt.description}
+			got, ok := roleSetAccountCreated(sa, mountId, false)
+			if ok != tt.wantOk {
+				t.Fatalf("expected ok %v, got %v", tt.wantOk, ok)
+			}
+			if ok && !got.Equal(tt.want) {
+				t.Fatalf("expected creation time %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	sa := &iam.ServiceAccount{Email: tests[0].email, DisplayName: displayName}
+	if _, ok := roleSetAccountCreated(sa, "", false); ok {
+		t.Fatalf("expected accounts not to be tidied without a mount ID")
+	}
+
+	// Accounts without a mount ID are only matched if unlabeled accounts are tidied.
+	for _, id := range []string{"", mountId} {
+		if got, ok := roleSetAccountCreated(sa, id, true); !ok || !got.Equal(tests[0].want) {
+			t.Fatalf("expected unlabeled account to be matched with mount ID %q, got %v, %v", id, got, ok)
+		}
+	}
+	sa.Description = tests[2].description
+	if _, ok := roleSetAccountCreated(sa, mountId, true); ok {
+		t.Fatalf("expected accounts of other mounts not to be tidied")
+	}
+	sa.Description = ""
+	sa.DisplayName = "My service account"
+	if _, ok := roleSetAccountCreated(sa, mountId, true); ok {
+		t.Fatalf("expected unlabeled accounts with other display names not to be tidied")
+	}
+}
+
+func Test_GetMountId(t *testing.T) {
+	b, reqStorage := getTestBackend(t)
+	ctx := context.Background()
+
+	if id, err := b.getMountId(ctx, reqStorage, false); err != nil || id != "" {
+		t.Fatalf("expected no mount ID before one is created, got %q (err: %v)", id, err)
+	}
+	id, err := b.getMountId(ctx, reqStorage, true)
+	if err != nil || id == "" {
+		t.Fatalf("expected mount ID to be created, got %q (err: %v)", id, err)
+	}
+
+	// A new backend on the same storage reads the same ID.
+	b2, _ := getTestBackend(t)
+	if id2, err := b2.getMountId(ctx, reqStorage, false); err != nil || id2 != id {
+		t.Fatalf("expected mount ID %q from storage, got %q (err: %v)", id, id2, err)
+	}
+}
+
+func Test_IsStaleRoleSetMember(t *testing.T) {
+	inUse := util.ToSet([]string{"vaultin-use-1600000000@project.iam.gserviceaccount.com"})
+	orphaned := util.ToSet([]string{"vaultorphan-1600000000@project.iam.gserviceaccount.com"})
+
+	tests := []struct {
+		member string
+		want   bool
+	}{
+		{member: "deleted:serviceAccount:vaultold-1600000000@project.iam.gserviceaccount.com?uid=123", want: true},
+		{member: "deleted:serviceAccount:vaultin-use-1600000000@project.iam.gserviceaccount.com?uid=123"},
+		{member: "deleted:serviceAccount:someone-else@project.iam.gserviceaccount.com?uid=123"},
+		{member: "serviceAccount:vaultorphan-1600000000@project.iam.gserviceaccount.com", want: true},
+		{member: "serviceAccount:vaultin-use-1600000000@project.iam.gserviceaccount.com"},
+		{member: "serviceAccount:vaultother-1600000000@project.iam.gserviceaccount.com"},
+		{member: "user:vaultold-1600000000@example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.member, func(t *testing.T) {
+			if got := isStaleRoleSetMember(tt.member, inUse, orphaned); got != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_TidyStatusOutput(t *testing.T) {
+	var s *tidyStatus
+	if state := s.asOutput()["state"]; state != tidyStateInactive {
+		t.Fatalf("expected state %q before any tidy, got %q", tidyStateInactive, state)
+	}
+
+	s = &tidyStatus{
+		params:      tidyParams{SafetyBuffer: time.Hour, DryRun: true, TidyKeys: true},
+		state:       tidyStateRunning,
+		timeStarted: time.Unix(1600000000, 0),
+		err:         fmt.Errorf("boom"),
+		keys:        []string{"projects/p/serviceAccounts/sa/keys/k"},
+	}
+	out := s.asOutput()
+	if out["safety_buffer"] != int64(3600) || out["dry_run"] != true || out["error"] != "boom" {
+		t.Fatalf("unexpected tidy status output: %v", out)
+	}
+	if keys := out["orphaned_keys"].([]string); len(keys) != 1 {
+		t.Fatalf("expected 1 orphaned key, got %v", keys)
+	}
+	if out["time_finished"] != nil {
+		t.Fatalf("expected no finish time for running tidy, got %v", out["time_finished"])
+	}
+}
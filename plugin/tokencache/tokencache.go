@@ -0,0 +1,147 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package tokencache
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// Key identifies a cached token. Tokens are only shared between requests
+// for the same account, scopes, delegates, subject and lifetime.
+type Key struct {
+	Account   string
+	Scopes    []string
+	Delegates []string
+	Subject   string
+	Lifetime  time.Duration
+}
+
+// String returns the cache key for k. Scopes are sorted so that the order in
+// which they were requested does not matter. Delegates are kept in order, as
+// they form a delegation chain.
+func (k Key) String() string {
+	scopes := make([]string, len(k.Scopes))
+	copy(scopes, k.Scopes)
+	sort.Strings(scopes)
+	return fmt.Sprintf("%s|%s|%s|%s|%d", k.Account, strings.Join(scopes, ","), strings.Join(k.Delegates, ","), k.Subject, k.Lifetime)
+}
+
+// Func is the signature for a function that generates a new token.
+type Func func(ctx context.Context) (*oauth2.Token, error)
+
+// Cache caches OAuth2 tokens and coalesces concurrent requests for the same
+// key into a single call to the token source.
+type Cache struct {
+	lock     sync.Mutex
+	data     map[string]*cacheEntry
+	inflight map[string]*call
+
+	// generation is incremented whenever entries are expired so that tokens
+	// generated by calls in flight at that time are not cached.
+	generation uint64
+}
+
+// cacheEntry represents a token in the cache for an account.
+type cacheEntry struct {
+	account string
+	token   *oauth2.Token
+}
+
+// call represents a token request in flight.
+type call struct {
+	done  chan struct{}
+	token *oauth2.Token
+	err   error
+}
+
+// New creates a token cache.
+func New() *Cache {
+	return &Cache{
+		data:     map[string]*cacheEntry{},
+		inflight: map[string]*call{},
+	}
+}
+
+// Fetch returns the cached token for key if its remaining lifetime is more
+// than minTTL. Otherwise, f is invoked to generate a new token, which is
+// cached and returned. Concurrent callers missing the cache for the same key
+// wait for and share the result of a single call to f.
+//
+// Since the result is shared, f is called with a context that is not
+// canceled with ctx, so one caller giving up does not fail the others. f must
+// bound its own runtime. A caller whose ctx is done stops waiting and gets
+// the context error.
+func (c *Cache) Fetch(ctx context.Context, key Key, minTTL time.Duration, f Func) (*oauth2.Token, error) {
+	name := key.String()
+
+	c.lock.Lock()
+	if e, ok := c.data[name]; ok {
+		if time.Until(e.token.Expiry) > minTTL {
+			c.lock.Unlock()
+			return e.token, nil
+		}
+		delete(c.data, name)
+	}
+
+	cl, ok := c.inflight[name]
+	if !ok {
+		cl = &call{done: make(chan struct{})}
+		c.inflight[name] = cl
+		go c.fetch(context.WithoutCancel(ctx), name, key.Account, c.generation, cl, f)
+	}
+	c.lock.Unlock()
+
+	select {
+	case <-cl.done:
+		return cl.token, cl.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// fetch calls f for the in-flight call cl and caches its token, unless the
+// cache was invalidated since generation.
+func (c *Cache) fetch(ctx context.Context, name, account string, generation uint64, cl *call, f Func) {
+	cl.token, cl.err = f(ctx)
+
+	c.lock.Lock()
+	delete(c.inflight, name)
+	if cl.err == nil && cl.token != nil && generation == c.generation && !cl.token.Expiry.IsZero() {
+		c.data[name] = &cacheEntry{
+			account: account,
+			token:   cl.token,
+		}
+	}
+	c.lock.Unlock()
+	close(cl.done)
+}
+
+// ExpireAccount removes all cached tokens for the given account.
+func (c *Cache) ExpireAccount(account string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.generation++
+	for name, e := range c.data {
+		if e.account == account {
+			delete(c.data, name)
+		}
+	}
+}
+
+// Clear empties the cache for all tokens.
+func (c *Cache) Clear() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.generation++
+	c.data = map[string]*cacheEntry{}
+}
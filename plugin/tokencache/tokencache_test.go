@@ -0,0 +1,233 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package tokencache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func tokenFunc(calls *int32, lifetime time.Duration) Func {
+	return func(context.Context) (*oauth2.Token, error) {
+		n := atomic.AddInt32(calls, 1)
+		return &oauth2.Token{
+			AccessToken: fmt.Sprintf("token-%d", n),
+			Expiry:      time.Now().Add(lifetime),
+		}, nil
+	}
+}
+
+func TestCache_Fetch(t *testing.T) {
+	c := New()
+	key := Key{Account: "sa@project.iam.gserviceaccount.com", Scopes: []string{"b", "a"}}
+
+	var calls int32
+	first, err := c.Fetch(context.Background(), key, time.Minute, tokenFunc(&calls, time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Scope order should not matter.
+	second, err := c.Fetch(context.Background(), Key{Account: key.Account, Scopes: []string{"a", "b"}}, time.Minute, tokenFunc(&calls, time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.AccessToken != second.AccessToken || calls != 1 {
+		t.Fatalf("expected cached token, got %q and %q after %d calls", first.AccessToken, second.AccessToken, calls)
+	}
+
+	// A different subject is a different token.
+	if _, err := c.Fetch(context.Background(), Key{Account: key.Account, Scopes: key.Scopes, Subject: "user@example.com"}, time.Minute, tokenFunc(&calls, time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected new token for different subject, got %d calls", calls)
+	}
+
+	// A different delegation chain is a different token.
+	if _, err := c.Fetch(context.Background(), Key{Account: key.Account, Scopes: key.Scopes, Delegates: []string{"delegate@project.iam.gserviceaccount.com"}}, time.Minute, tokenFunc(&calls, time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected new token for different delegates, got %d calls", calls)
+	}
+}
+
+func TestCache_FetchMinTTL(t *testing.T) {
+	c := New()
+	key := Key{Account: "sa@project.iam.gserviceaccount.com", Scopes: []string{"a"}}
+
+	var calls int32
+	if _, err := c.Fetch(context.Background(), key, time.Minute, tokenFunc(&calls, 30*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	// The cached token expires within the minimum TTL, so a new one is generated.
+	if _, err := c.Fetch(context.Background(), key, time.Minute, tokenFunc(&calls, time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected token below minimum TTL to be regenerated, got %d calls", calls)
+	}
+}
+
+func TestCache_FetchError(t *testing.T) {
+	c := New()
+	key := Key{Account: "sa@project.iam.gserviceaccount.com"}
+
+	_, err := c.Fetch(context.Background(), key, time.Minute, func(context.Context) (*oauth2.Token, error) {
+		return nil, fmt.Errorf("upstream error")
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	var calls int32
+	if _, err := c.Fetch(context.Background(), key, time.Minute, tokenFunc(&calls, time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected errors not to be cached, got %d calls", calls)
+	}
+}
+
+func TestCache_FetchCoalesces(t *testing.T) {
+	c := New()
+	key := Key{Account: "sa@project.iam.gserviceaccount.com", Scopes: []string{"a"}}
+
+	var calls int32
+	release := make(chan struct{})
+	f := func(ctx context.Context) (*oauth2.Token, error) {
+		<-release
+		return tokenFunc(&calls, time.Hour)(ctx)
+	}
+
+	var wg sync.WaitGroup
+	tokens := make([]*oauth2.Token, 10)
+	for i := range tokens {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tkn, err := c.Fetch(context.Background(), key, time.Minute, f)
+			if err != nil {
+				t.Error(err)
+			}
+			tokens[i] = tkn
+		}(i)
+	}
+
+	// Give the goroutines a chance to join the in-flight request.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Fatalf("expected concurrent requests to be coalesced into 1 call, got %d", calls)
+	}
+	for _, tkn := range tokens {
+		if tkn == nil || tkn.AccessToken != tokens[0].AccessToken {
+			t.Fatalf("expected all callers to get the same token")
+		}
+	}
+}
+
+func TestCache_ExpireAccount(t *testing.T) {
+	c := New()
+	keyA := Key{Account: "a@project.iam.gserviceaccount.com"}
+	keyB := Key{Account: "b@project.iam.gserviceaccount.com"}
+
+	var calls int32
+	for _, k := range []Key{keyA, keyB} {
+		if _, err := c.Fetch(context.Background(), k, time.Minute, tokenFunc(&calls, time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c.ExpireAccount(keyA.Account)
+
+	for _, k := range []Key{keyA, keyB} {
+		if _, err := c.Fetch(context.Background(), k, time.Minute, tokenFunc(&calls, time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 3 {
+		t.Fatalf("expected only the expired account to be regenerated, got %d calls", calls)
+	}
+}
+
+func TestCache_ClearDuringFetch(t *testing.T) {
+	c := New()
+	key := Key{Account: "sa@project.iam.gserviceaccount.com"}
+
+	var calls int32
+	if _, err := c.Fetch(context.Background(), key, time.Minute, func(ctx context.Context) (*oauth2.Token, error) {
+		// Simulate the cache being invalidated while the token is being generated.
+		c.Clear()
+		return tokenFunc(&calls, time.Hour)(ctx)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Fetch(context.Background(), key, time.Minute, tokenFunc(&calls, time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected token generated during invalidation not to be cached, got %d calls", calls)
+	}
+}
+
+func TestCache_FetchCanceledCaller(t *testing.T) {
+	c := New()
+	key := Key{Account: "sa@project.iam.gserviceaccount.com"}
+
+	var calls int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	f := func(ctx context.Context) (*oauth2.Token, error) {
+		close(started)
+		<-release
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return tokenFunc(&calls, time.Hour)(ctx)
+	}
+
+	// The first caller starts the fetch and gives up.
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := c.Fetch(ctx, key, time.Minute, f)
+		errCh <- err
+	}()
+	<-started
+
+	// A second caller joins the fetch in flight.
+	tknCh := make(chan *oauth2.Token, 1)
+	go func() {
+		tkn, err := c.Fetch(context.Background(), key, time.Minute, f)
+		if err != nil {
+			t.Error(err)
+		}
+		tknCh <- tkn
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	cancel()
+	if err := <-errCh; err != context.Canceled {
+		t.Fatalf("expected canceled caller to get %v, got %v", context.Canceled, err)
+	}
+
+	close(release)
+	if tkn := <-tknCh; tkn == nil {
+		t.Fatal("expected the shared fetch to succeed after the first caller was canceled")
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 call, got %d", calls)
+	}
+}
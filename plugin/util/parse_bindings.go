@@ -33,7 +33,49 @@ func BindingsHCL(bindings map[string]StringSet) (string, error) {
 	return buf.String(), nil
 }
 
+// Condition is an IAM Condition under which a group of roles is bound.
+type Condition struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Expression  string `json:"expression"`
+}
+
+func (c *Condition) validate() error {
+	var merr *multierror.Error
+	if c.Title == "" {
+		merr = multierror.Append(merr, errors.New("condition title is required"))
+	}
+	if c.Expression == "" {
+		merr = multierror.Append(merr, errors.New("condition expression is required"))
+	}
+	return merr.ErrorOrNil()
+}
+
+// ConditionalBindings are the roles bound on each resource under a single condition.
+type ConditionalBindings struct {
+	Condition Condition
+	Bindings  map[string]StringSet
+}
+
+// ParseBindings parses bindings that do not have conditions.
 func ParseBindings(bindingsStr string) (map[string]StringSet, error) {
+	bindings, conditional, err := ParseConditionalBindings(bindingsStr)
+	if err != nil {
+		return nil, err
+	}
+	if len(conditional) > 0 {
+		return nil, errors.New("unable to parse bindings: condition blocks are not supported")
+	}
+	return bindings, nil
+}
+
+// ParseConditionalBindings parses bindings where each resource block can have a condition block
+// that applies to its roles. Roles without a condition are returned separately from the conditional
+// bindings, which are grouped by condition in the order they first appear.
+//
+// Bindings can be given as raw or base64-encoded HCL, or as a structured JSON document (see
+// parseStructuredBindings).
+func ParseConditionalBindings(bindingsStr string) (map[string]StringSet, []*ConditionalBindings, error) {
 	// Try to base64 decode
 	decoder := base64.NewDecoder(base64.StdEncoding, strings.NewReader(bindingsStr))
 	decoded, b64err := ioutil.ReadAll(decoder)
@@ -45,34 +87,66 @@ func ParseBindings(bindingsStr string) (map[string]StringSet, error) {
 		bindsString = string(decoded)
 	}
 
+	if parsed, ok, err := parseStructuredBindings(bindsString); ok {
+		if err != nil {
+			return nil, nil, errwrap.Wrapf("unable to parse JSON bindings: {{err}}", err)
+		}
+		return parsed.bindings, parsed.conditional, nil
+	}
+
 	root, err := hcl.Parse(bindsString)
 	if err != nil {
 		if b64err == nil {
-			return nil, errwrap.Wrapf("unable to parse base64-encoded bindings as valid HCL: {{err}}", err)
+			return nil, nil, errwrap.Wrapf("unable to parse base64-encoded bindings as valid HCL: {{err}}", err)
 		} else {
-			return nil, errwrap.Wrapf("unable to parse raw string bindings as valid HCL: {{err}}", err)
+			return nil, nil, errwrap.Wrapf("unable to parse raw string bindings as valid HCL: {{err}}", err)
 		}
 	}
 
 	bindingLst, ok := root.Node.(*ast.ObjectList)
 	if !ok {
-		return nil, errors.New("unable to parse bindings: does not contain a root object")
+		return nil, nil, errors.New("unable to parse bindings: does not contain a root object")
 	}
 
-	bindingsMap, err := parseBindingObjList(bindingLst)
+	parsed, err := parseBindingObjList(bindingLst)
 	if err != nil {
-		return nil, errwrap.Wrapf("unable to parse bindings: {{err}}", err)
+		return nil, nil, errwrap.Wrapf("unable to parse bindings: {{err}}", err)
+	}
+	return parsed.bindings, parsed.conditional, nil
+}
+
+type parsedBindings struct {
+	bindings    map[string]StringSet
+	conditional []*ConditionalBindings
+}
+
+// bindingsFor returns the bindings to add roles under the given condition to.
+func (p *parsedBindings) bindingsFor(condition *Condition) map[string]StringSet {
+	if condition == nil {
+		return p.bindings
+	}
+	for _, cb := range p.conditional {
+		if cb.Condition == *condition {
+			return cb.Bindings
+		}
+	}
+	cb := &ConditionalBindings{
+		Condition: *condition,
+		Bindings:  make(map[string]StringSet),
 	}
-	return bindingsMap, nil
+	p.conditional = append(p.conditional, cb)
+	return cb.Bindings
 }
 
-func parseBindingObjList(topList *ast.ObjectList) (map[string]StringSet, error) {
+func parseBindingObjList(topList *ast.ObjectList) (*parsedBindings, error) {
 	var merr *multierror.Error
 
-	bindings := make(map[string]StringSet)
+	parsed := &parsedBindings{
+		bindings: make(map[string]StringSet),
+	}
 
 	for _, item := range topList.Items {
-		err := parseResourceObject(item, bindings)
+		err := parseResourceObject(item, parsed)
 		if err != nil {
 			merr = multierror.Append(merr, fmt.Errorf("(line %d) %v", item.Assign.Line, err))
 		}
@@ -81,10 +155,10 @@ func parseBindingObjList(topList *ast.ObjectList) (map[string]StringSet, error)
 	if err != nil {
 		return nil, err
 	}
-	return bindings, nil
+	return parsed, nil
 }
 
-func parseResourceObject(item *ast.ObjectItem, bindings map[string]StringSet) error {
+func parseResourceObject(item *ast.ObjectItem, parsed *parsedBindings) error {
 	if len(item.Keys) != 2 || item.Keys[0] == nil || item.Keys[1] == nil {
 		return fmt.Errorf(`top-level items must have format "resource" "$resource_name"`)
 	}
@@ -102,25 +176,103 @@ func parseResourceObject(item *ast.ObjectItem, bindings map[string]StringSet) er
 		return err
 	}
 
-	_, ok := bindings[resourceName]
-	if !ok {
-		bindings[resourceName] = make(StringSet)
-	}
-	boundRoles := bindings[resourceName]
-
 	resourceItemList := item.Val.(*ast.ObjectType).List
 	if resourceItemList == nil {
 		return fmt.Errorf("invalid empty roles list for item (line %d)", item.Assign.Line)
 	}
 
 	var merr *multierror.Error
-	for _, rolesObj := range resourceItemList.Items {
-		err := parseRolesObject(rolesObj, boundRoles)
+	var condition *Condition
+	boundRoles := make(StringSet)
+	for _, obj := range resourceItemList.Items {
+		if isObjectWithKey(obj, "condition") {
+			if condition != nil {
+				merr = multierror.Append(merr, fmt.Errorf("condition (line %d): only one condition block is allowed per resource block", obj.Assign.Line))
+				continue
+			}
+			condition, err = parseConditionObject(obj)
+			if err != nil {
+				merr = multierror.Append(merr, fmt.Errorf("condition (line %d): %v", obj.Assign.Line, err))
+			}
+			continue
+		}
+
+		err := parseRolesObject(obj, boundRoles)
 		if err != nil {
-			merr = multierror.Append(merr, fmt.Errorf("role list (line %d): %v", rolesObj.Assign.Line, err))
+			merr = multierror.Append(merr, fmt.Errorf("role list (line %d): %v", obj.Assign.Line, err))
 		}
 	}
-	return merr.ErrorOrNil()
+	if err := merr.ErrorOrNil(); err != nil {
+		return err
+	}
+	if condition != nil && len(boundRoles) == 0 {
+		return fmt.Errorf("resource %q has a condition but no roles", resourceName)
+	}
+
+	bindings := parsed.bindingsFor(condition)
+	if _, ok := bindings[resourceName]; !ok {
+		bindings[resourceName] = make(StringSet)
+	}
+	bindings[resourceName].Update(boundRoles.ToSlice()...)
+	return nil
+}
+
+func isObjectWithKey(obj *ast.ObjectItem, key string) bool {
+	if obj == nil || len(obj.Keys) != 1 || obj.Keys[0] == nil {
+		return false
+	}
+	k, err := parseStringFromObjectKey(obj, obj.Keys[0])
+	return err == nil && k == key
+}
+
+func parseConditionObject(conditionObj *ast.ObjectItem) (*Condition, error) {
+	objType, ok := conditionObj.Val.(*ast.ObjectType)
+	if !ok || objType.List == nil {
+		return nil, fmt.Errorf(`expected "condition" block with title, description and expression`)
+	}
+
+	condition := &Condition{}
+	var merr *multierror.Error
+	for _, field := range objType.List.Items {
+		if field == nil || len(field.Keys) != 1 || field.Keys[0] == nil {
+			merr = multierror.Append(merr, errors.New("unexpected nil item in condition"))
+			continue
+		}
+		k, err := parseStringFromObjectKey(field, field.Keys[0])
+		if err != nil {
+			merr = multierror.Append(merr, err)
+			continue
+		}
+		lit, ok := field.Val.(*ast.LiteralType)
+		if !ok || lit == nil {
+			merr = multierror.Append(merr, fmt.Errorf("condition %s must be a string (line %d)", k, field.Assign.Line))
+			continue
+		}
+		v, ok := lit.Token.Value().(string)
+		if !ok {
+			merr = multierror.Append(merr, fmt.Errorf("condition %s must be a string (line %d)", k, field.Assign.Line))
+			continue
+		}
+
+		switch k {
+		case "title":
+			condition.Title = v
+		case "description":
+			condition.Description = v
+		case "expression":
+			condition.Expression = v
+		default:
+			merr = multierror.Append(merr, fmt.Errorf(`invalid key %q in condition, expected "title", "description" or "expression"`, k))
+		}
+	}
+
+	if err := condition.validate(); err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	if err := merr.ErrorOrNil(); err != nil {
+		return nil, err
+	}
+	return condition, nil
 }
 
 func parseRolesObject(rolesObj *ast.ObjectItem, parsedRoles StringSet) error {
@@ -0,0 +1,147 @@
+// Copyright IBM Corp. 2018, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package util
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// BindingsEntry is a single resource's roles in the structured JSON bindings format,
+// optionally granted under a condition.
+type BindingsEntry struct {
+	Resource  string     `json:"resource"`
+	Roles     []string   `json:"roles"`
+	Condition *Condition `json:"condition,omitempty"`
+}
+
+// parseStructuredBindings parses bindings given as a JSON document, either a map of
+// resource name to roles:
+//
+//	{"projects/my-project": ["roles/viewer"]}
+//
+// or a list of BindingsEntry objects, which can also have a condition:
+//
+//	[{"resource": "projects/my-project", "roles": ["roles/viewer"], "condition": {...}}]
+//
+// ok is false if the string is not one of these documents and should be parsed as HCL
+// instead. HCL-formatted JSON, i.e. {"resource": {"name": {"roles": [...]}}}, is left to
+// the HCL parser.
+func parseStructuredBindings(bindingsStr string) (parsed *parsedBindings, ok bool, err error) {
+	trimmed := strings.TrimSpace(bindingsStr)
+	switch {
+	case strings.HasPrefix(trimmed, "["):
+		var entries []*BindingsEntry
+		dec := json.NewDecoder(strings.NewReader(trimmed))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&entries); err != nil {
+			return nil, true, err
+		}
+		parsed, err := parseBindingsEntries(entries)
+		return parsed, true, err
+	case strings.HasPrefix(trimmed, "{"):
+		var rolesByResource map[string][]string
+		if err := json.Unmarshal([]byte(trimmed), &rolesByResource); err != nil {
+			return nil, false, nil
+		}
+		entries := make([]*BindingsEntry, 0, len(rolesByResource))
+		for resource, roles := range rolesByResource {
+			entries = append(entries, &BindingsEntry{Resource: resource, Roles: roles})
+		}
+		parsed, err := parseBindingsEntries(entries)
+		return parsed, true, err
+	default:
+		return nil, false, nil
+	}
+}
+
+func parseBindingsEntries(entries []*BindingsEntry) (*parsedBindings, error) {
+	var merr *multierror.Error
+
+	parsed := &parsedBindings{
+		bindings: make(map[string]StringSet),
+	}
+
+	for i, entry := range entries {
+		if entry == nil {
+			merr = multierror.Append(merr, fmt.Errorf("(entry %d) unexpected null entry", i))
+			continue
+		}
+		if err := parseBindingsEntry(entry, parsed); err != nil {
+			merr = multierror.Append(merr, fmt.Errorf("(resource %q) %v", entry.Resource, err))
+		}
+	}
+	if err := merr.ErrorOrNil(); err != nil {
+		return nil, err
+	}
+	return parsed, nil
+}
+
+func parseBindingsEntry(entry *BindingsEntry, parsed *parsedBindings) error {
+	if entry.Resource == "" {
+		return errors.New("resource name is required")
+	}
+	if len(entry.Roles) == 0 {
+		return errors.New("roles cannot be empty")
+	}
+	for _, role := range entry.Roles {
+		if role == "" {
+			return errors.New("roles cannot contain empty strings")
+		}
+	}
+	if entry.Condition != nil {
+		if err := entry.Condition.validate(); err != nil {
+			return err
+		}
+	}
+
+	bindings := parsed.bindingsFor(entry.Condition)
+	if _, ok := bindings[entry.Resource]; !ok {
+		bindings[entry.Resource] = make(StringSet)
+	}
+	bindings[entry.Resource].Update(entry.Roles...)
+	return nil
+}
+
+// BindingsEntries returns the structured entries for bindings under the given condition,
+// with sorted roles.
+func BindingsEntries(bindings map[string]StringSet, condition *Condition) []*BindingsEntry {
+	entries := make([]*BindingsEntry, 0, len(bindings))
+	for resource, roles := range bindings {
+		sorted := roles.ToSlice()
+		sort.Strings(sorted)
+		entries = append(entries, &BindingsEntry{
+			Resource:  resource,
+			Roles:     sorted,
+			Condition: condition,
+		})
+	}
+	return entries
+}
+
+// SortBindingsEntries sorts entries by resource, with unconditional roles before conditional roles,
+// so the same bindings are always rendered the same way.
+func SortBindingsEntries(entries []*BindingsEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Resource != b.Resource {
+			return a.Resource < b.Resource
+		}
+		if a.Condition == nil || b.Condition == nil {
+			return a.Condition == nil && b.Condition != nil
+		}
+		if a.Condition.Title != b.Condition.Title {
+			return a.Condition.Title < b.Condition.Title
+		}
+		if a.Condition.Expression != b.Condition.Expression {
+			return a.Condition.Expression < b.Condition.Expression
+		}
+		return a.Condition.Description < b.Condition.Description
+	})
+}
@@ -5,6 +5,7 @@ package util
 
 import (
 	"encoding/base64"
+	"reflect"
 	"testing"
 )
 
@@ -90,3 +91,221 @@ func checkParseBindings(t *testing.T, encodeB64 bool) {
 		}
 	}
 }
+
+func TestParseConditionalBindings(t *testing.T) {
+	input := `
+		resource "projects/X" {
+			roles = ["roles/viewer"]
+		}
+		resource "projects/X" {
+			roles = ["roles/editor"]
+			condition {
+				title       = "business-hours"
+				description = "Only during business hours"
+				expression  = "request.time.getHours(\"Europe/Berlin\") < 17"
+			}
+		}
+		resource "projects/Y" {
+			roles = ["roles/compute.admin"]
+			condition {
+				title       = "business-hours"
+				description = "Only during business hours"
+				expression  = "request.time.getHours(\"Europe/Berlin\") < 17"
+			}
+		}
+		resource "projects/Y" {
+			roles = ["roles/storage.admin"]
+			condition {
+				title      = "expires"
+				expression = "request.time < timestamp(\"2030-01-01T00:00:00Z\")"
+			}
+		}`
+
+	binds, conditional, err := ParseConditionalBindings(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(binds) != 1 || !binds["projects/X"].Equals(ToSet([]string{"roles/viewer"})) {
+		t.Fatalf("unexpected unconditional bindings: %v", binds)
+	}
+	if len(conditional) != 2 {
+		t.Fatalf("expected 2 condition groups, got %d", len(conditional))
+	}
+
+	hours := conditional[0]
+	expectedCond := Condition{
+		Title:       "business-hours",
+		Description: "Only during business hours",
+		Expression:  `request.time.getHours("Europe/Berlin") < 17`,
+	}
+	if hours.Condition != expectedCond {
+		t.Fatalf("expected condition %+v, got %+v", expectedCond, hours.Condition)
+	}
+	if len(hours.Bindings) != 2 ||
+		!hours.Bindings["projects/X"].Equals(ToSet([]string{"roles/editor"})) ||
+		!hours.Bindings["projects/Y"].Equals(ToSet([]string{"roles/compute.admin"})) {
+		t.Fatalf("unexpected bindings for condition %q: %v", hours.Condition.Title, hours.Bindings)
+	}
+
+	expires := conditional[1]
+	if expires.Condition.Title != "expires" || expires.Condition.Description != "" {
+		t.Fatalf("unexpected condition %+v", expires.Condition)
+	}
+	if len(expires.Bindings) != 1 || !expires.Bindings["projects/Y"].Equals(ToSet([]string{"roles/storage.admin"})) {
+		t.Fatalf("unexpected bindings for condition %q: %v", expires.Condition.Title, expires.Bindings)
+	}
+
+	if _, err := ParseBindings(input); err == nil {
+		t.Fatal("expected ParseBindings to reject condition blocks")
+	}
+}
+
+func TestParseConditionalBindingsInvalid(t *testing.T) {
+	tests := map[string]string{
+		"missing expression": `
+			resource "projects/X" {
+				roles = ["roles/viewer"]
+				condition {
+					title = "t"
+				}
+			}`,
+		"missing title": `
+			resource "projects/X" {
+				roles = ["roles/viewer"]
+				condition {
+					expression = "true"
+				}
+			}`,
+		"unknown key": `
+			resource "projects/X" {
+				roles = ["roles/viewer"]
+				condition {
+					title      = "t"
+					expression = "true"
+					foo        = "bar"
+				}
+			}`,
+		"multiple conditions": `
+			resource "projects/X" {
+				roles = ["roles/viewer"]
+				condition {
+					title      = "a"
+					expression = "true"
+				}
+				condition {
+					title      = "b"
+					expression = "true"
+				}
+			}`,
+		"condition without roles": `
+			resource "projects/X" {
+				condition {
+					title      = "t"
+					expression = "true"
+				}
+			}`,
+	}
+
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, _, err := ParseConditionalBindings(input); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestParseStructuredBindings(t *testing.T) {
+	tests := map[string]string{
+		"map": `{
+			"projects/X": ["roles/viewer", "roles/editor"],
+			"projects/Y": ["roles/compute.admin"]
+		}`,
+		"list": `[
+			{"resource": "projects/X", "roles": ["roles/viewer"]},
+			{"resource": "projects/X", "roles": ["roles/editor"]},
+			{"resource": "projects/Y", "roles": ["roles/compute.admin"]},
+			{
+				"resource": "projects/Y",
+				"roles": ["roles/storage.admin"],
+				"condition": {"title": "expires", "expression": "request.time < timestamp(\"2030-01-01T00:00:00Z\")"}
+			}
+		]`,
+		"hcl json": `{
+			"resource": {
+				"projects/X": {"roles": ["roles/viewer", "roles/editor"]},
+				"projects/Y": {"roles": ["roles/compute.admin"]}
+			}
+		}`,
+	}
+
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			for _, encoded := range []string{input, base64.StdEncoding.EncodeToString([]byte(input))} {
+				binds, conditional, err := ParseConditionalBindings(encoded)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if len(binds) != 2 ||
+					!binds["projects/X"].Equals(ToSet([]string{"roles/viewer", "roles/editor"})) ||
+					!binds["projects/Y"].Equals(ToSet([]string{"roles/compute.admin"})) {
+					t.Fatalf("unexpected bindings: %v", binds)
+				}
+				if name != "list" {
+					if len(conditional) != 0 {
+						t.Fatalf("unexpected conditional bindings: %v", conditional)
+					}
+					continue
+				}
+				if len(conditional) != 1 || conditional[0].Condition.Title != "expires" ||
+					!conditional[0].Bindings["projects/Y"].Equals(ToSet([]string{"roles/storage.admin"})) {
+					t.Fatalf("unexpected conditional bindings: %v", conditional)
+				}
+			}
+		})
+	}
+}
+
+func TestParseStructuredBindingsInvalid(t *testing.T) {
+	tests := map[string]string{
+		"empty roles":       `{"projects/X": []}`,
+		"missing resource":  `[{"roles": ["roles/viewer"]}]`,
+		"unknown field":     `[{"resource": "projects/X", "roles": ["roles/viewer"], "members": ["foo"]}]`,
+		"invalid condition": `[{"resource": "projects/X", "roles": ["roles/viewer"], "condition": {"title": "t"}}]`,
+		"invalid json":      `[{"resource": "projects/X"`,
+	}
+
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, _, err := ParseConditionalBindings(input); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestSortBindingsEntries(t *testing.T) {
+	cond := &Condition{Title: "expires", Expression: "true"}
+	entries := append(
+		BindingsEntries(map[string]StringSet{"projects/Y": ToSet([]string{"roles/b", "roles/a"})}, cond),
+		BindingsEntries(map[string]StringSet{
+			"projects/Y": ToSet([]string{"roles/c"}),
+			"projects/X": ToSet([]string{"roles/d"}),
+		}, nil)...,
+	)
+	SortBindingsEntries(entries)
+
+	expected := []BindingsEntry{
+		{Resource: "projects/X", Roles: []string{"roles/d"}},
+		{Resource: "projects/Y", Roles: []string{"roles/c"}},
+		{Resource: "projects/Y", Roles: []string{"roles/a", "roles/b"}, Condition: cond},
+	}
+	if len(entries) != len(expected) {
+		t.Fatalf("expected %d entries, got %d", len(expected), len(entries))
+	}
+	for i, e := range expected {
+		if !reflect.DeepEqual(*entries[i], e) {
+			t.Fatalf("entry %d: expected %+v, got %+v", i, e, *entries[i])
+		}
+	}
+}